@@ -23,9 +23,14 @@ import (
 
 	"github.com/gardener/auditlog-forwarder/cmd/auditlog-forwarder/app/options"
 	"github.com/gardener/auditlog-forwarder/internal/handler/audit"
+	"github.com/gardener/auditlog-forwarder/internal/handler/health"
 	"github.com/gardener/auditlog-forwarder/internal/output"
 	"github.com/gardener/auditlog-forwarder/internal/processor"
 	"github.com/gardener/auditlog-forwarder/internal/processor/annotation"
+	"github.com/gardener/auditlog-forwarder/internal/processor/dedup"
+	"github.com/gardener/auditlog-forwarder/internal/processor/sampling"
+	"github.com/gardener/auditlog-forwarder/internal/processor/stagemerge"
+	"github.com/gardener/auditlog-forwarder/internal/tracing"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
 
@@ -73,17 +78,66 @@ func NewCommand() *cobra.Command {
 	opt.AddFlags(fs)
 	fs.AddGoFlagSet(flag.CommandLine)
 
+	cmd.AddCommand(newVerifyCommand(), newDecryptCommand())
+
 	return cmd
 }
 
 func run(ctx context.Context, log logr.Logger, conf *options.Config) error {
+	shutdownTracing, err := tracing.Setup(ctx, conf.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		// The context of the command is already canceled when shutting down, hence a fresh one is used for flushing.
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Error(err, "Failed to flush traces")
+		}
+	}()
+
 	// Create processors
 	var processors []processor.Processor
+	// Deduplication, sampling and filtering are the first processors so that the following processors only handle the forwarded events.
+	if conf.Deduplication != nil {
+		processors = append(processors, dedup.New(conf.Deduplication))
+	}
+	if conf.Sampling != nil {
+		processors = append(processors, sampling.New(conf.Sampling))
+	}
+	if conf.Filter != nil {
+		processors = append(processors, conf.Filter)
+	}
+	if conf.StageMerging != nil {
+		processors = append(processors, stagemerge.New(log.WithName("stagemerge"), conf.StageMerging))
+	}
 	if len(conf.InjectAnnotations) > 0 {
-		processors = append(processors, annotation.New(conf.InjectAnnotations))
+		processors = append(processors, annotation.New(conf.InjectAnnotations, conf.OverwriteInjectedAnnotations))
+	}
+	// The enrichment precedes the computed annotations so that their expressions and templates can use the injected metadata.
+	if conf.Enrichment != nil {
+		processors = append(processors, conf.Enrichment)
+	}
+	if conf.ComputedAnnotations != nil {
+		processors = append(processors, conf.ComputedAnnotations)
+	}
+	// The hash chain must be the last processor so that it covers all modifications of the events.
+	// The validation ensures that the processors of the outputs do not drop or modify the chained events.
+	if conf.HashChain != nil {
+		processors = append(processors, conf.HashChain)
 	}
 
-	auditHandler, err := audit.NewHandler(log, processors, conf.OutputsGuaranteed, conf.OutputsBestEffort)
+	auditHandler, err := audit.NewHandler(
+		log,
+		processors,
+		conf.OutputsGuaranteed,
+		conf.OutputsBestEffort,
+		audit.WithQuorumOutputs(conf.OutputsQuorum, conf.QuorumMinSuccessful),
+		audit.WithRequestLimits(conf.Serving.RequestLimits),
+		audit.WithMaxInFlightRequests(conf.Serving.MaxInFlightRequests, conf.Serving.RetryAfter),
+		audit.WithBestEffortQueue(conf.BestEffortQueue),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to create audit handler: %w", err)
 	}
@@ -95,12 +149,24 @@ func run(ctx context.Context, log logr.Logger, conf *options.Config) error {
 		Addr:         conf.Serving.Address,
 		Handler:      muxAudit,
 		TLSConfig:    conf.Serving.TLSConfig,
+		Protocols:    conf.Serving.Protocols,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 	}
 
+	var healthOptions []health.Option
+	if certs := conf.Serving.TLSConfig.Certificates; len(certs) > 0 && certs[0].Leaf != nil {
+		healthOptions = append(healthOptions, health.WithServerCertificate(certs[0].Leaf))
+	}
+	if conf.Serving.HealthCheckInterval > 0 {
+		healthOptions = append(healthOptions, health.WithOutputChecks(conf.Serving.HealthCheckInterval))
+	}
+	healthHandler := health.New(log.WithName("health"), auditHandler, healthOptions...)
+	healthHandler.SetConfigLoaded()
+
 	muxMetrics := http.NewServeMux()
 	muxMetrics.Handle("GET /metrics", promhttp.Handler())
+	healthHandler.Register(muxMetrics)
 
 	srvMetrics := &http.Server{
 		Addr:         conf.Serving.MetricsAddress,
@@ -109,6 +175,8 @@ func run(ctx context.Context, log logr.Logger, conf *options.Config) error {
 		WriteTimeout: 15 * time.Second,
 	}
 
+	go healthHandler.Run(ctx)
+
 	srvAuditCh := make(chan error)
 	srvAuditCtx, cancelSrvAudit := context.WithCancel(ctx)
 
@@ -132,7 +200,7 @@ func run(ctx context.Context, log logr.Logger, conf *options.Config) error {
 		ch <- runServer(srvMetricsCtx, log, "metrics-server", false, srvMetrics, nil)
 	}(srvMetricsCh)
 
-	defer closeOutputs(log, conf.OutputsGuaranteed, conf.OutputsBestEffort)
+	defer closeOutputs(log, conf.OutputsGuaranteed, conf.OutputsQuorum, conf.OutputsBestEffort)
 
 	select {
 	case err := <-srvMetricsCh:
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+	"github.com/gardener/auditlog-forwarder/internal/encryption"
+)
+
+// newDecryptCommand returns the command decrypting request bodies stored by encrypted outputs.
+func newDecryptCommand() *cobra.Command {
+	var (
+		privateKeyFiles []string
+		keepCompressed  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "decrypt [FILE...]",
+		Short: "Decrypt request bodies stored by encrypted outputs",
+		Long: `Decrypt request bodies which were encrypted for an output as JSON Web Encryption (JWE).
+
+The JWEs are read from the given files, or from stdin if no file is given. Each input may contain several JWEs
+separated by whitespace. The decrypted bodies are decompressed and written to stdout, each followed by a newline,
+so that the output can be piped into the verify command.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(privateKeyFiles) == 0 {
+				return errors.New("at least one private key file is required")
+			}
+			var keys []crypto.PrivateKey
+			for _, path := range privateKeyFiles {
+				key, err := encryption.LoadPrivateKey(path)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+
+			if len(args) == 0 {
+				if err := decryptInput(cmd.OutOrStdout(), cmd.InOrStdin(), keys, keepCompressed); err != nil {
+					return fmt.Errorf("failed to decrypt stdin: %w", err)
+				}
+				return nil
+			}
+			for _, path := range args {
+				if err := decryptFile(cmd.OutOrStdout(), path, keys, keepCompressed); err != nil {
+					return fmt.Errorf("failed to decrypt %s: %w", path, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&privateKeyFiles, "private-key-file", nil, "File containing a PEM encoded RSA or ECDSA private key of a recipient, can be repeated for rotated keys.")
+	cmd.Flags().BoolVar(&keepCompressed, "keep-compressed", false, "Write compressed bodies without decompressing them.")
+	return cmd
+}
+
+// decryptFile decrypts the JWEs read from the file and writes the bodies to w.
+func decryptFile(w io.Writer, path string, keys []crypto.PrivateKey, keepCompressed bool) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return decryptInput(w, f, keys, keepCompressed)
+}
+
+// decryptInput decrypts the JWEs read from r and writes the bodies to w.
+func decryptInput(w io.Writer, r io.Reader, keys []crypto.PrivateKey, keepCompressed bool) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	jwes, err := encryption.Split(data)
+	if err != nil {
+		return err
+	}
+
+	for _, jwe := range jwes {
+		plaintext, err := encryption.Decrypt(jwe, keys)
+		if err != nil {
+			return err
+		}
+		body := plaintext.Data
+		if !keepCompressed {
+			if body, err = encoding.Decompress(body, plaintext.ContentEncoding); err != nil {
+				return fmt.Errorf("failed to decompress body: %w", err)
+			}
+		}
+		if !bytes.HasSuffix(body, []byte("\n")) {
+			body = append(body, '\n')
+		}
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -22,13 +23,28 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
+	"github.com/gardener/auditlog-forwarder/internal/filewatch"
+	"github.com/gardener/auditlog-forwarder/internal/handler/audit"
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
 	"github.com/gardener/auditlog-forwarder/internal/output"
 	outputfactory "github.com/gardener/auditlog-forwarder/internal/output/factory"
 	outputhttp "github.com/gardener/auditlog-forwarder/internal/output/http"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	"github.com/gardener/auditlog-forwarder/internal/processor/annotation"
+	"github.com/gardener/auditlog-forwarder/internal/processor/enrichment"
+	"github.com/gardener/auditlog-forwarder/internal/processor/filter"
+	"github.com/gardener/auditlog-forwarder/internal/processor/hashchain"
+	"github.com/gardener/auditlog-forwarder/internal/processor/pseudonym"
+	"github.com/gardener/auditlog-forwarder/internal/revocation"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+	confighelper "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1/helper"
 	"github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1/validation"
 )
 
+// enrichmentCacheSyncTimeout is the maximum duration to wait at startup until the objects used for enrichment are cached.
+const enrichmentCacheSyncTimeout = time.Minute
+
 var configDecoder runtime.Decoder
 
 func init() {
@@ -88,55 +104,176 @@ func (o *Options) LogConfig() (string, string) {
 
 // ApplyTo applies the options to the config.
 func (o *Options) ApplyTo(ctx context.Context, log logr.Logger, server *Config) error {
-	if err := o.applyServerConfigToServing(&server.Serving); err != nil {
+	if err := o.applyServerConfigToServing(ctx, log, &server.Serving); err != nil {
 		return err
 	}
 
 	serverConfig := o.Config.Server
 	server.Serving.MetricsAddress = net.JoinHostPort(serverConfig.Address, strconv.FormatInt(int64(serverConfig.MetricsPort), 10))
 
+	server.Deduplication = o.Config.Deduplication
+	server.Sampling = o.Config.Sampling
+	server.StageMerging = o.Config.StageMerging
 	server.InjectAnnotations = o.Config.InjectAnnotations
+	server.OverwriteInjectedAnnotations = o.Config.OverwriteInjectedAnnotations
+	server.Tracing = o.Config.Tracing
+
+	if o.Config.Filter != nil {
+		f, err := filter.New(log.WithName("filter"), o.Config.Filter)
+		if err != nil {
+			return fmt.Errorf("failed to create filter: %w", err)
+		}
+		server.Filter = f
+	}
+	if len(o.Config.ComputedAnnotations) > 0 {
+		injector, err := annotation.NewComputed(log.WithName("annotation"), o.Config.ComputedAnnotations)
+		if err != nil {
+			return fmt.Errorf("failed to create computed annotation injector: %w", err)
+		}
+		if err := injector.Watch(ctx, filewatch.DefaultDebounce); err != nil {
+			return fmt.Errorf("failed to watch annotation files: %w", err)
+		}
+		server.ComputedAnnotations = injector
+	}
+
+	if o.Config.Enrichment != nil {
+		client, err := enrichment.NewClient(o.Config.Enrichment.Kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create enrichment client: %w", err)
+		}
+		enricher := enrichment.New(log.WithName("enrichment"), o.Config.Enrichment, client)
+		syncCtx, cancel := context.WithTimeout(ctx, enrichmentCacheSyncTimeout)
+		defer cancel()
+		if err := enricher.Start(ctx, syncCtx); err != nil {
+			return fmt.Errorf("failed to start enrichment: %w", err)
+		}
+		server.Enrichment = enricher
+	}
+
+	if o.Config.HashChain != nil {
+		chain, err := hashchain.New(log.WithName("hashchain"), o.Config.HashChain)
+		if err != nil {
+			return fmt.Errorf("failed to create hash chain: %w", err)
+		}
+		if err := chain.Watch(ctx, filewatch.DefaultDebounce); err != nil {
+			return fmt.Errorf("failed to watch signing key file: %w", err)
+		}
+		server.HashChain = chain
+	}
 
-	guaranteedOutputs, err := outputfactory.NewHTTPOutputsWithOptions(
+	var pseudonymizer *pseudonym.Pseudonymizer
+	if o.Config.Pseudonymization != nil {
+		var err error
+		// The annotations computed from the events may contain the identities of the users.
+		var eventAnnotations []string
+		for _, computed := range o.Config.ComputedAnnotations {
+			if computed.Template != "" || computed.Expression != "" {
+				eventAnnotations = append(eventAnnotations, computed.Key)
+			}
+		}
+		if pseudonymizer, err = pseudonym.New(log.WithName("pseudonym"), o.Config.Pseudonymization, eventAnnotations); err != nil {
+			return fmt.Errorf("failed to create pseudonymizer: %w", err)
+		}
+		if err := pseudonymizer.Watch(ctx, filewatch.DefaultDebounce); err != nil {
+			return fmt.Errorf("failed to watch pseudonymization key file: %w", err)
+		}
+	}
+	// Outputs with the same filter expression share the filter, so that the filtered data is shared as well.
+	outputFilters := make(map[string]*filter.Filter)
+	for _, outputConfig := range o.Config.Outputs {
+		if outputConfig.Filter == nil || outputFilters[outputConfig.Filter.Expression] != nil {
+			continue
+		}
+		f, err := filter.New(log.WithName("filter"), outputConfig.Filter)
+		if err != nil {
+			return fmt.Errorf("failed to create output filter: %w", err)
+		}
+		outputFilters[outputConfig.Filter.Expression] = f
+	}
+	outputProcessors := func(config *configv1alpha1.Output) []processor.Processor {
+		var processors []processor.Processor
+		// The events are filtered first, so that the following processors only handle the sent events.
+		if config.Filter != nil {
+			processors = append(processors, outputFilters[config.Filter.Expression])
+		}
+		if config.Pseudonymize {
+			processors = append(processors, pseudonymizer)
+		}
+		return processors
+	}
+
+	guaranteedOutputs, err := outputfactory.NewHTTPOutputsWithProcessors(
 		ctx,
 		o.Config.Outputs,
 		configv1alpha1.DeliveryModeGuaranteed,
+		outputProcessors,
 		outputhttp.WithLogger(log.WithName("output")),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create Guaranteed outputs: %w", err)
 	}
 
+	quorumOutputs, err := outputfactory.NewHTTPOutputsWithProcessors(
+		ctx,
+		o.Config.Outputs,
+		configv1alpha1.DeliveryModeQuorum,
+		outputProcessors,
+		outputhttp.WithLogger(log.WithName("output")),
+	)
+	if err != nil {
+		// Guaranteed outputs already succeeded and might be holding resources;
+		// close them so they don't leak now that we're returning an error and the caller will not.
+		return errors.Join(fmt.Errorf("failed to create Quorum outputs: %w", err), closeOutputs(guaranteedOutputs))
+	}
+
 	// Purposefully use different backoff settings for BestEffort outputs
 	// in order to give more time to the target system to receive the events in case of transient errors.
-	bestEffortOutputs, err := outputfactory.NewHTTPOutputsWithOptions(
+	bestEffortOutputs, err := outputfactory.NewHTTPOutputsWithProcessors(
 		ctx,
 		o.Config.Outputs,
 		configv1alpha1.DeliveryModeBestEffort,
+		outputProcessors,
 		outputhttp.WithMaxSendAttempts(6),
 		outputhttp.WithBaseBackoff(1*time.Second),
 		outputhttp.WithMaxBackoff(6*time.Second),
 		outputhttp.WithLogger(log.WithName("output")),
 	)
 	if err != nil {
-		// Guaranteed outputs already succeeded and might be holding resources;
+		// Guaranteed and Quorum outputs already succeeded and might be holding resources;
 		// close them so they don't leak now that we're returning an error and the caller will not.
-		var closeErrs []error
-		for _, out := range guaranteedOutputs {
-			if cerr := out.Close(); cerr != nil {
-				closeErrs = append(closeErrs, fmt.Errorf("failed to close guaranteed output %q: %w", out.Name(), cerr))
-			}
-		}
-		return errors.Join(fmt.Errorf("failed to create BestEffort outputs: %w", err), errors.Join(closeErrs...))
+		return errors.Join(fmt.Errorf("failed to create BestEffort outputs: %w", err), closeOutputs(guaranteedOutputs), closeOutputs(quorumOutputs))
 	}
 	server.OutputsGuaranteed = guaranteedOutputs
 	server.OutputsBestEffort = bestEffortOutputs
+	server.OutputsQuorum = quorumOutputs
+	if o.Config.Quorum != nil {
+		server.QuorumMinSuccessful = int(o.Config.Quorum.MinSuccessful)
+	}
+	if queue := o.Config.BestEffortQueue; queue != nil {
+		server.BestEffortQueue = audit.BestEffortQueue{
+			Size:           int(queue.Size),
+			Workers:        int(queue.Workers),
+			OverflowPolicy: queue.OverflowPolicy,
+		}
+	}
 
 	return nil
 }
 
+// closeOutputs closes the given outputs, joining any errors.
+func closeOutputs(outputs []output.Output) error {
+	var errs []error
+	for _, out := range outputs {
+		if err := out.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close output %q: %w", out.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // applyServerConfigToServing applies server configuration to serving config
-func (o *Options) applyServerConfigToServing(serving *Serving) error {
+// The context controls the lifetime of the CRL file watcher.
+func (o *Options) applyServerConfigToServing(ctx context.Context, log logr.Logger, serving *Serving) error {
 	serverConfig := o.Config.Server
 	serving.Address = net.JoinHostPort(serverConfig.Address, strconv.FormatInt(int64(serverConfig.Port), 10))
 
@@ -144,10 +281,13 @@ func (o *Options) applyServerConfigToServing(serving *Serving) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse server certificates: %w", err)
 	}
+	metrics.CertificateExpiry.WithLabelValues(metrics.CertificateServer, "").Set(float64(serverCert.Leaf.NotAfter.Unix()))
 
 	tlsConfig := &tls.Config{
 		Certificates: []tls.Certificate{serverCert},
-		MinVersion:   tls.VersionTLS12,
+	}
+	if err := confighelper.ApplyTLSParameters(tlsConfig, serverConfig.TLS.TLSParameters); err != nil {
+		return fmt.Errorf("failed to apply TLS parameters: %w", err)
 	}
 
 	// Configure client certificate verification if specified
@@ -157,7 +297,41 @@ func (o *Options) applyServerConfigToServing(serving *Serving) error {
 		}
 	}
 
+	if serverConfig.TLS.ClientRevocation != nil {
+		checker, err := revocation.New(log.WithName("revocation"), serverConfig.TLS.ClientRevocation, metrics.CertificateClient, "")
+		if err != nil {
+			return fmt.Errorf("failed to configure revocation checking of client certificates: %w", err)
+		}
+		if err := checker.Watch(ctx, filewatch.DefaultDebounce); err != nil {
+			return fmt.Errorf("failed to watch CRL files: %w", err)
+		}
+		tlsConfig.VerifyConnection = checker.VerifyConnection
+	}
+
 	serving.TLSConfig = tlsConfig
+	// The server adds HTTP/2 and HTTP/1.1 to the ALPN protocols unless they are disabled explicitly.
+	serving.Protocols = confighelper.HTTPProtocols(serverConfig.TLS.ALPNProtocols)
+
+	limits := serverConfig.RequestLimits
+	if limits.MaxBodySize != nil {
+		serving.RequestLimits.MaxBodySize = limits.MaxBodySize.Value()
+	}
+	if limits.MaxDecompressedBodySize != nil {
+		serving.RequestLimits.MaxDecompressedBodySize = limits.MaxDecompressedBodySize.Value()
+	}
+	serving.RequestLimits.MaxDecompressionRatio = int64(limits.MaxDecompressionRatio)
+
+	if serverConfig.MaxInFlightRequests != nil {
+		serving.MaxInFlightRequests = int(*serverConfig.MaxInFlightRequests)
+	}
+	if serverConfig.RetryAfter != nil {
+		serving.RetryAfter = serverConfig.RetryAfter.Duration
+	}
+
+	if serverConfig.Health.CheckGuaranteedOutputs && serverConfig.Health.CheckInterval != nil {
+		serving.HealthCheckInterval = serverConfig.Health.CheckInterval.Duration
+	}
+
 	return nil
 }
 
@@ -172,6 +346,11 @@ func (o *Options) configureClientAuth(tlsConfig *tls.Config, clientCAFile string
 	if !caCertPool.AppendCertsFromPEM(caCert) {
 		return fmt.Errorf("failed to parse CA certificate from %s", clientCAFile)
 	}
+	expiry, err := helper.CertificatesExpiry(caCert)
+	if err != nil {
+		return fmt.Errorf("failed to determine expiry of CA certificate from %s: %w", clientCAFile, err)
+	}
+	metrics.CertificateExpiry.WithLabelValues(metrics.CertificateClientCA, "").Set(float64(expiry.Unix()))
 
 	tlsConfig.ClientCAs = caCertPool
 	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
@@ -181,16 +360,48 @@ func (o *Options) configureClientAuth(tlsConfig *tls.Config, clientCAFile string
 
 // Config has all the context to run an auditlog forwarder.
 type Config struct {
-	Serving           Serving
+	Serving Serving
+	// Deduplication is the configuration of the dropping of already forwarded events, nil if disabled.
+	Deduplication *configv1alpha1.Deduplication
+	// Sampling contains the rules by which events are sampled, nil if all events are forwarded.
+	Sampling *configv1alpha1.Sampling
+	// StageMerging is the configuration of the merging of the stages of a request, nil if disabled.
+	StageMerging *configv1alpha1.StageMerging
+	// Filter drops the events which are not forwarded, nil if all events are forwarded.
+	Filter            *filter.Filter
 	InjectAnnotations map[string]string
+	// OverwriteInjectedAnnotations specifies whether InjectAnnotations overwrites annotations which are already set.
+	OverwriteInjectedAnnotations bool
+	// ComputedAnnotations injects the annotations computed per event, nil if none are configured.
+	ComputedAnnotations *annotation.ComputedInjector
+	// Enrichment injects metadata of the namespaces of the events, nil if disabled.
+	Enrichment *enrichment.Enricher
+	// Tracing is the configuration for exporting traces, nil if tracing is disabled.
+	Tracing *configv1alpha1.Tracing
+	// HashChain links the forwarded events in a signed hash chain, nil if disabled.
+	HashChain         *hashchain.Chain
 	Outputs           []output.Output
 	OutputsGuaranteed []output.Output
 	OutputsBestEffort []output.Output
+	OutputsQuorum     []output.Output
+	// QuorumMinSuccessful is the number of Quorum outputs that must succeed for a request to be successful.
+	QuorumMinSuccessful int
+	// BestEffortQueue is the queue for deliveries to BestEffort outputs.
+	BestEffortQueue audit.BestEffortQueue
 }
 
 // Serving contains the configuration for the auditlog forwarder.
 type Serving struct {
-	TLSConfig      *tls.Config
+	TLSConfig *tls.Config
+	// Protocols are the HTTP protocols offered via ALPN, nil if the default protocols are offered.
+	Protocols      *http.Protocols
 	Address        string
 	MetricsAddress string
+	RequestLimits  audit.RequestLimits
+	// MaxInFlightRequests is the maximum number of concurrently processed requests, 0 means unlimited.
+	MaxInFlightRequests int
+	// RetryAfter is the duration after which clients are asked to retry requests rejected because of MaxInFlightRequests.
+	RetryAfter time.Duration
+	// HealthCheckInterval is the interval of active health checks of the Guaranteed and Quorum outputs, 0 if they are disabled.
+	HealthCheckInterval time.Duration
 }
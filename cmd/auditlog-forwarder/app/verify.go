@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gardener/auditlog-forwarder/internal/processor/hashchain"
+)
+
+// newVerifyCommand returns the command verifying the hash chains of stored audit events.
+func newVerifyCommand() *cobra.Command {
+	var publicKeyFiles []string
+
+	cmd := &cobra.Command{
+		Use:   "verify [FILE...]",
+		Short: "Verify the hash chains of stored audit events",
+		Long: `Verify the hash chains of audit events stored by an output.
+
+The events are read from the given files, or from stdin if no file is given. They may be stored as
+EventLists, JSON arrays or single events. The checkpoints are verified with the given public keys.
+The command fails if events were modified or are missing, or if a checkpoint cannot be verified.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var keys []ed25519.PublicKey
+			for _, path := range publicKeyFiles {
+				key, err := hashchain.LoadPublicKey(path)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+
+			readers := []io.Reader{cmd.InOrStdin()}
+			if len(args) > 0 {
+				readers = nil
+				for _, path := range args {
+					f, err := os.Open(filepath.Clean(path))
+					if err != nil {
+						return fmt.Errorf("failed to open %s: %w", path, err)
+					}
+					defer func() { _ = f.Close() }()
+					readers = append(readers, f)
+				}
+			}
+
+			report, err := hashchain.Verify(io.MultiReader(readers...), keys)
+			if err != nil {
+				return err
+			}
+			printReport(cmd.OutOrStdout(), report)
+			if !report.Valid() {
+				return errors.New("verification failed")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&publicKeyFiles, "public-key-file", nil, "File containing a PEM encoded Ed25519 public key to verify checkpoints, can be repeated for rotated keys.")
+	return cmd
+}
+
+// printReport writes a human-readable summary of the verification report.
+func printReport(w io.Writer, report *hashchain.Report) {
+	for _, chain := range report.Chains {
+		_, _ = fmt.Fprintf(w, "Chain %s: %d events (sequences %d-%d), %d valid checkpoints\n",
+			chain.ID, chain.Events, chain.FirstSequence, chain.LastSequence, chain.Checkpoints)
+		if unsigned := chain.LastSequence - chain.LastCheckpoint; chain.Events > 0 && unsigned > 0 {
+			_, _ = fmt.Fprintf(w, "  %d events after the last checkpoint are not signed yet\n", unsigned)
+		}
+		for _, problem := range chain.Problems {
+			_, _ = fmt.Fprintf(w, "  PROBLEM: %s\n", problem)
+		}
+	}
+	if report.Unchained > 0 {
+		_, _ = fmt.Fprintf(w, "PROBLEM: %d events are not part of a hash chain\n", report.Unchained)
+	}
+	if report.Valid() {
+		_, _ = fmt.Fprintln(w, "OK")
+	}
+}
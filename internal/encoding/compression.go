@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// Gzip is the gzip content coding.
+	Gzip = "gzip"
+	// Zstd is the Zstandard content coding.
+	Zstd = "zstd"
+	// Snappy is the snappy block format.
+	Snappy = "snappy"
+	// Deflate is the zlib-wrapped deflate content coding as defined for HTTP.
+	Deflate = "deflate"
+)
+
+// Compression identifies a compression algorithm together with its level.
+// It is comparable and can be used as a map key.
+type Compression struct {
+	// Algorithm is the compression algorithm which is also used as value of the Content-Encoding header.
+	// An empty algorithm means no compression.
+	Algorithm string
+	// Level is the compression level. 0 selects the default level of the algorithm.
+	Level int
+}
+
+// zstdEncoders caches one encoder per level. [zstd.Encoder.EncodeAll] is safe for concurrent use.
+var zstdEncoders sync.Map
+
+// Compress compresses data with the given compression.
+func Compress(data []byte, c Compression) ([]byte, error) {
+	switch c.Algorithm {
+	case "":
+		return data, nil
+	case Gzip:
+		level := gzip.DefaultCompression
+		if c.Level != 0 {
+			level = c.Level
+		}
+		return compressStream(data, func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, level)
+		})
+	case Deflate:
+		level := zlib.DefaultCompression
+		if c.Level != 0 {
+			level = c.Level
+		}
+		return compressStream(data, func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriterLevel(w, level)
+		})
+	case Zstd:
+		encoder, err := zstdEncoder(c.Level)
+		if err != nil {
+			return nil, err
+		}
+		return encoder.EncodeAll(data, nil), nil
+	case Snappy:
+		return snappy.Encode(nil, data), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %q", c.Algorithm)
+	}
+}
+
+// Decompress decompresses data which was compressed with the given algorithm.
+// It is meant for tooling and does not limit the size of the decompressed data.
+func Decompress(data []byte, algorithm string) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch algorithm {
+	case "":
+		return data, nil
+	case Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	case Deflate:
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	case Zstd:
+		var decoder *zstd.Decoder
+		if decoder, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1)); err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		defer decoder.Close()
+		return decoder.DecodeAll(data, nil)
+	case Snappy:
+		return snappy.Decode(nil, data)
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reader: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+func compressStream(data []byte, newWriter func(io.Writer) (io.WriteCloser, error)) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := newWriter(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create writer: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		// call w.Close for the sake of completeness
+		// ignore the error as this would probably be the same error as the error returned by w.Write
+		_ = w.Close()
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+	// explicitly close the writer in order to make it flush residual data and write the footer
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func zstdEncoder(level int) (*zstd.Encoder, error) {
+	if encoder, ok := zstdEncoders.Load(level); ok {
+		return encoder.(*zstd.Encoder), nil
+	}
+
+	encoderLevel := zstd.SpeedDefault
+	if level != 0 {
+		encoderLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+
+	actual, loaded := zstdEncoders.LoadOrStore(level, encoder)
+	if loaded {
+		_ = encoder.Close()
+	}
+	return actual.(*zstd.Encoder), nil
+}
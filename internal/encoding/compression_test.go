@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+)
+
+var _ = Describe("Compress", func() {
+	data := bytes.Repeat([]byte(`{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"RequestResponse"}`), 100)
+
+	readAll := func(r io.Reader, err error) []byte {
+		Expect(err).NotTo(HaveOccurred())
+		decompressed, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+		return decompressed
+	}
+
+	DescribeTable("should compress data",
+		func(c encoding.Compression, decompress func([]byte) []byte) {
+			compressed, err := encoding.Compress(data, c)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(compressed)).To(BeNumerically("<", len(data)))
+			Expect(decompress(compressed)).To(Equal(data))
+		},
+		Entry("gzip with default level", encoding.Compression{Algorithm: encoding.Gzip}, func(b []byte) []byte {
+			return readAll(gzip.NewReader(bytes.NewReader(b)))
+		}),
+		Entry("gzip with level", encoding.Compression{Algorithm: encoding.Gzip, Level: 9}, func(b []byte) []byte {
+			return readAll(gzip.NewReader(bytes.NewReader(b)))
+		}),
+		Entry("deflate with level", encoding.Compression{Algorithm: encoding.Deflate, Level: 1}, func(b []byte) []byte {
+			return readAll(zlib.NewReader(bytes.NewReader(b)))
+		}),
+		Entry("zstd with default level", encoding.Compression{Algorithm: encoding.Zstd}, func(b []byte) []byte {
+			decoder, err := zstd.NewReader(nil)
+			Expect(err).NotTo(HaveOccurred())
+			defer decoder.Close()
+			decompressed, err := decoder.DecodeAll(b, nil)
+			Expect(err).NotTo(HaveOccurred())
+			return decompressed
+		}),
+		Entry("zstd with level", encoding.Compression{Algorithm: encoding.Zstd, Level: 19}, func(b []byte) []byte {
+			decoder, err := zstd.NewReader(nil)
+			Expect(err).NotTo(HaveOccurred())
+			defer decoder.Close()
+			decompressed, err := decoder.DecodeAll(b, nil)
+			Expect(err).NotTo(HaveOccurred())
+			return decompressed
+		}),
+		Entry("snappy", encoding.Compression{Algorithm: encoding.Snappy}, func(b []byte) []byte {
+			decompressed, err := snappy.Decode(nil, b)
+			Expect(err).NotTo(HaveOccurred())
+			return decompressed
+		}),
+	)
+
+	It("should return the data unchanged without algorithm", func() {
+		Expect(encoding.Compress(data, encoding.Compression{})).To(Equal(data))
+	})
+
+	It("should fail for unsupported algorithms", func() {
+		_, err := encoding.Compress(data, encoding.Compression{Algorithm: "br"})
+		Expect(err).To(MatchError(`unsupported compression algorithm "br"`))
+	})
+
+	It("should fail for invalid levels", func() {
+		_, err := encoding.Compress(data, encoding.Compression{Algorithm: encoding.Gzip, Level: 42})
+		Expect(err).To(MatchError(ContainSubstring("failed to create writer")))
+	})
+})
+
+var _ = Describe("Decompress", func() {
+	data := bytes.Repeat([]byte(`{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"RequestResponse"}`), 100)
+
+	DescribeTable("should decompress compressed data",
+		func(algorithm string) {
+			compressed, err := encoding.Compress(data, encoding.Compression{Algorithm: algorithm})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoding.Decompress(compressed, algorithm)).To(Equal(data))
+		},
+		Entry("without algorithm", ""),
+		Entry("gzip", encoding.Gzip),
+		Entry("deflate", encoding.Deflate),
+		Entry("zstd", encoding.Zstd),
+		Entry("snappy", encoding.Snappy),
+	)
+
+	It("should fail for unsupported algorithms", func() {
+		_, err := encoding.Decompress(data, "br")
+		Expect(err).To(MatchError(`unsupported compression algorithm "br"`))
+	})
+
+	It("should fail for data which is not compressed", func() {
+		_, err := encoding.Decompress(data, encoding.Gzip)
+		Expect(err).To(MatchError(ContainSubstring("failed to create reader")))
+	})
+})
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+const (
+	// MediaTypeJSON is the media type of a JSON document.
+	MediaTypeJSON = "application/json"
+	// MediaTypeNDJSON is the media type of newline-delimited JSON documents.
+	MediaTypeNDJSON = "application/x-ndjson"
+	// MediaTypeText is the media type of text lines.
+	MediaTypeText = "text/plain; charset=utf-8"
+)
+
+// Encoding identifies how the events of a payload are encoded for an output.
+// It is comparable and can be used as a map key.
+type Encoding struct {
+	// Schema is the schema the events are transformed into.
+	// An empty schema keeps the "audit.k8s.io/v1" events.
+	Schema configv1alpha1.Schema
+	// Format is the framing of the events into request bodies.
+	// An empty format selects "EventList" for the "KubernetesAudit" schema and "NDJSON" otherwise.
+	Format configv1alpha1.Format
+	// Compression is the compression applied to each body.
+	Compression Compression
+}
+
+// normalized returns the encoding with the empty schema and format replaced by their defaults.
+func (e Encoding) normalized() Encoding {
+	if e.Schema == "" {
+		e.Schema = configv1alpha1.SchemaKubernetesAudit
+	}
+	if e.Format == "" {
+		e.Format = configv1alpha1.FormatNDJSON
+		if e.Schema == configv1alpha1.SchemaKubernetesAudit {
+			e.Format = configv1alpha1.FormatEventList
+		}
+	}
+	return e
+}
+
+// ContentType returns the media type of the bodies before compression.
+func (e Encoding) ContentType() string {
+	e = e.normalized()
+	switch {
+	case e.Schema == configv1alpha1.SchemaCEF:
+		return MediaTypeText
+	case e.Format == configv1alpha1.FormatNDJSON:
+		return MediaTypeNDJSON
+	default:
+		return MediaTypeJSON
+	}
+}
+
+// frame frames the records of the events into request bodies in the given format.
+func frame(format configv1alpha1.Format, records [][]byte) ([][]byte, error) {
+	switch format {
+	case configv1alpha1.FormatNDJSON:
+		var buf bytes.Buffer
+		for _, record := range records {
+			buf.Write(record)
+			buf.WriteByte('\n')
+		}
+		return [][]byte{buf.Bytes()}, nil
+	case configv1alpha1.FormatJSONArray:
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		buf.Write(bytes.Join(records, []byte{','}))
+		buf.WriteByte(']')
+		return [][]byte{buf.Bytes()}, nil
+	case configv1alpha1.FormatSingleEvent:
+		return records, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/transform"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// contextKey is the key type for storing the payload in context
+type contextKey string
+
+const payloadKey contextKey = "payload"
+
+// Payload is the data of a request which is forwarded to all outputs.
+// It caches the encodings of the data, so that each distinct encoding is computed
+// once per request and shared across all outputs requiring it.
+// It is safe for concurrent use.
+type Payload struct {
+	data []byte
+
+	decodeOnce sync.Once
+	eventList  *audit.EventList
+	decodeErr  error
+
+	countOnce  sync.Once
+	events     int
+	timestamps []time.Time
+	countErr   error
+
+	mu      sync.Mutex
+	records map[configv1alpha1.Schema]*result
+	encoded map[Encoding]*result
+	derived map[any]*derivation
+}
+
+// result is a lazily computed encoding of the payload.
+type result struct {
+	once   sync.Once
+	bodies [][]byte
+	err    error
+}
+
+// derivation is a lazily computed payload derived from a payload.
+type derivation struct {
+	once    sync.Once
+	payload *Payload
+	err     error
+}
+
+// NewPayload creates a payload for the given data.
+func NewPayload(data []byte) *Payload {
+	return &Payload{
+		data:    data,
+		records: make(map[configv1alpha1.Schema]*result),
+		encoded: make(map[Encoding]*result),
+		derived: make(map[any]*derivation),
+	}
+}
+
+// Data returns the unencoded data.
+func (p *Payload) Data() []byte {
+	return p.data
+}
+
+// Events returns the number of audit events of the data.
+// The events are counted once, so that all callers share the result.
+func (p *Payload) Events() (int, error) {
+	p.count()
+	return p.events, p.countErr
+}
+
+// EventTimestamps returns the timestamps of the audit events of the data, see helper.EventTimestamps.
+// The timestamps are extracted once together with the number of events.
+func (p *Payload) EventTimestamps() ([]time.Time, error) {
+	p.count()
+	return p.timestamps, p.countErr
+}
+
+// count counts the audit events of the data and extracts their timestamps, at most once.
+func (p *Payload) count() {
+	p.countOnce.Do(func() {
+		p.events, p.timestamps, p.countErr = helper.EventTimestamps(p.data)
+	})
+}
+
+// Derived returns the payload of the data derived from this payload by the derive function, e.g. the data processed
+// by the processor of an output. The key identifies the derivation and must be comparable.
+// Concurrent callers requesting the same key wait for a single computation, so that outputs with the same
+// derivation share the derived payload and its encodings.
+func (p *Payload) Derived(key any, derive func(data []byte) ([]byte, error)) (*Payload, error) {
+	p.mu.Lock()
+	d, ok := p.derived[key]
+	if !ok {
+		d = &derivation{}
+		p.derived[key] = d
+	}
+	p.mu.Unlock()
+
+	d.once.Do(func() {
+		var data []byte
+		if data, d.err = derive(p.data); d.err == nil {
+			d.payload = NewPayload(data)
+		}
+	})
+	return d.payload, d.err
+}
+
+// Compressed returns the data compressed with the given compression.
+// Concurrent callers requesting the same compression wait for a single computation.
+// The returned slice is shared and must not be modified.
+func (p *Payload) Compressed(c Compression) ([]byte, error) {
+	bodies, err := p.Bodies(Encoding{Compression: c})
+	if err != nil {
+		return nil, err
+	}
+	return bodies[0], nil
+}
+
+// Bodies returns the request bodies of the data in the given encoding, i.e. the events transformed into the
+// schema, framed in the format and then compressed. All formats except "SingleEvent" result in exactly one body.
+// Concurrent callers requesting the same encoding wait for a single computation.
+// The returned slices are shared and must not be modified.
+func (p *Payload) Bodies(e Encoding) ([][]byte, error) {
+	e = e.normalized()
+	if e.Schema == configv1alpha1.SchemaKubernetesAudit && e.Format == configv1alpha1.FormatEventList && e.Compression.Algorithm == "" {
+		return [][]byte{p.data}, nil
+	}
+
+	return cached(&p.mu, p.encoded, e, func() ([][]byte, error) {
+		if e.Compression.Algorithm == "" {
+			return p.frame(e.Schema, e.Format)
+		}
+
+		// The uncompressed bodies are cached as well, as they are shared by outputs using different compressions.
+		uncompressed, err := p.Bodies(Encoding{Schema: e.Schema, Format: e.Format})
+		if err != nil {
+			return nil, err
+		}
+		compressed := make([][]byte, len(uncompressed))
+		for i, body := range uncompressed {
+			if compressed[i], err = Compress(body, e.Compression); err != nil {
+				return nil, err
+			}
+		}
+		return compressed, nil
+	})
+}
+
+// frame returns the bodies of the events in the given schema and format.
+func (p *Payload) frame(schema configv1alpha1.Schema, format configv1alpha1.Format) ([][]byte, error) {
+	if format == configv1alpha1.FormatEventList {
+		if schema != configv1alpha1.SchemaKubernetesAudit {
+			return nil, fmt.Errorf("format %q is not supported with schema %q", format, schema)
+		}
+		return [][]byte{p.data}, nil
+	}
+	if format == configv1alpha1.FormatJSONArray && schema == configv1alpha1.SchemaCEF {
+		return nil, fmt.Errorf("format %q is not supported with schema %q", format, schema)
+	}
+
+	records, err := cached(&p.mu, p.records, schema, func() ([][]byte, error) {
+		return p.transform(schema)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return frame(format, records)
+}
+
+// transform returns one record per event in the given schema.
+func (p *Payload) transform(schema configv1alpha1.Schema) ([][]byte, error) {
+	if schema == configv1alpha1.SchemaKubernetesAudit {
+		records, err := helper.EventListItems(p.data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode events: %w", err)
+		}
+		return records, nil
+	}
+
+	p.decodeOnce.Do(func() {
+		p.eventList, p.decodeErr = helper.DecodeEventList(p.data)
+	})
+	if p.decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode events: %w", p.decodeErr)
+	}
+	return transform.EventList(schema, p.eventList)
+}
+
+// cached returns the result of compute for the given key of the cache guarded by mu, computing it at most once.
+func cached[K comparable](mu *sync.Mutex, cache map[K]*result, key K, compute func() ([][]byte, error)) ([][]byte, error) {
+	mu.Lock()
+	r, ok := cache[key]
+	if !ok {
+		r = &result{}
+		cache[key] = r
+	}
+	mu.Unlock()
+
+	r.once.Do(func() {
+		r.bodies, r.err = compute()
+	})
+	return r.bodies, r.err
+}
+
+// WithPayload adds a payload to the context.
+func WithPayload(ctx context.Context, payload *Payload) context.Context {
+	return context.WithValue(ctx, payloadKey, payload)
+}
+
+// PayloadFromContext retrieves the payload for the given data from the context.
+// If the context holds no payload or a payload of different data, a new payload is returned.
+func PayloadFromContext(ctx context.Context, data []byte) *Payload {
+	if payload, ok := ctx.Value(payloadKey).(*Payload); ok && sameData(payload.data, data) {
+		return payload
+	}
+	return NewPayload(data)
+}
+
+// sameData reports whether a and b are the same slice, i.e. share their backing array and length.
+func sameData(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	return &a[0] == &b[0]
+}
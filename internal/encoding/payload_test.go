@@ -0,0 +1,257 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Payload", func() {
+	var (
+		data    []byte
+		payload *encoding.Payload
+	)
+
+	BeforeEach(func() {
+		data = []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[]}`)
+		payload = encoding.NewPayload(data)
+	})
+
+	It("should return the data without compression", func() {
+		Expect(&payload.Data()[0]).To(BeIdenticalTo(&data[0]))
+		uncompressed, err := payload.Compressed(encoding.Compression{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(&uncompressed[0]).To(BeIdenticalTo(&data[0]))
+	})
+
+	It("should compute each compression once for concurrent callers", func() {
+		gzipCompression := encoding.Compression{Algorithm: encoding.Gzip}
+
+		results := make([][]byte, 10)
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Go(func() {
+				defer GinkgoRecover()
+				compressed, err := payload.Compressed(gzipCompression)
+				Expect(err).NotTo(HaveOccurred())
+				results[i] = compressed
+			})
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			Expect(&result[0]).To(BeIdenticalTo(&results[0][0]))
+		}
+	})
+
+	It("should compute distinct compressions separately", func() {
+		gzipDefault, err := payload.Compressed(encoding.Compression{Algorithm: encoding.Gzip})
+		Expect(err).NotTo(HaveOccurred())
+		gzipFast, err := payload.Compressed(encoding.Compression{Algorithm: encoding.Gzip, Level: 1})
+		Expect(err).NotTo(HaveOccurred())
+		zstdDefault, err := payload.Compressed(encoding.Compression{Algorithm: encoding.Zstd})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(&gzipFast[0]).NotTo(BeIdenticalTo(&gzipDefault[0]))
+		Expect(zstdDefault).NotTo(Equal(gzipDefault))
+	})
+
+	Describe("#Bodies", func() {
+		BeforeEach(func() {
+			data = []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[` +
+				`{"level":"Metadata","auditID":"1","stage":"ResponseComplete","verb":"get","requestURI":"/healthz","user":{}},` + "\n" +
+				`  {"level":"Metadata","auditID":"2","stage":"ResponseComplete","verb":"list","requestURI":"/readyz","user":{}}]}`)
+			payload = encoding.NewPayload(data)
+		})
+
+		It("should return the data for the Kubernetes audit schema", func() {
+			bodies, err := payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaKubernetesAudit})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bodies).To(HaveLen(1))
+			Expect(&bodies[0][0]).To(BeIdenticalTo(&data[0]))
+		})
+
+		It("should frame the Kubernetes audit events in the given formats", func() {
+			first := `{"level":"Metadata","auditID":"1","stage":"ResponseComplete","verb":"get","requestURI":"/healthz","user":{}}`
+			second := `{"level":"Metadata","auditID":"2","stage":"ResponseComplete","verb":"list","requestURI":"/readyz","user":{}}`
+
+			bodies, err := payload.Bodies(encoding.Encoding{Format: configv1alpha1.FormatNDJSON})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bodies).To(ConsistOf(BeEquivalentTo(first + "\n" + second + "\n")))
+
+			bodies, err = payload.Bodies(encoding.Encoding{Format: configv1alpha1.FormatJSONArray})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bodies).To(ConsistOf(BeEquivalentTo("[" + first + "," + second + "]")))
+
+			bodies, err = payload.Bodies(encoding.Encoding{Format: configv1alpha1.FormatSingleEvent})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bodies).To(HaveExactElements(BeEquivalentTo(first), BeEquivalentTo(second)))
+		})
+
+		It("should transform the events into one line per event", func() {
+			bodies, err := payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaCEF})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bodies).To(HaveLen(1))
+			Expect(string(bodies[0])).To(And(
+				HavePrefix("CEF:0|Kubernetes|kube-apiserver|v1|get|get /healthz|"),
+				ContainSubstring("\nCEF:0|Kubernetes|kube-apiserver|v1|list|list /readyz|"),
+				HaveSuffix("\n"),
+			))
+			Expect(bytes.Count(bodies[0], []byte("\n"))).To(Equal(2))
+		})
+
+		It("should return one body per transformed event", func() {
+			bodies, err := payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaOCSF, Format: configv1alpha1.FormatSingleEvent})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bodies).To(HaveLen(2))
+			for _, body := range bodies {
+				Expect(json.Valid(body)).To(BeTrue())
+			}
+		})
+
+		It("should compress each body and share the uncompressed bodies", func() {
+			transformed, err := payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaECS, Format: configv1alpha1.FormatSingleEvent})
+			Expect(err).NotTo(HaveOccurred())
+			compressed, err := payload.Bodies(encoding.Encoding{
+				Schema:      configv1alpha1.SchemaECS,
+				Format:      configv1alpha1.FormatSingleEvent,
+				Compression: encoding.Compression{Algorithm: encoding.Gzip},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(compressed).To(HaveLen(2))
+
+			for i := range compressed {
+				reader, err := gzip.NewReader(bytes.NewReader(compressed[i]))
+				Expect(err).NotTo(HaveOccurred())
+				decompressed, err := io.ReadAll(reader)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decompressed).To(Equal(transformed[i]))
+			}
+
+			again, err := payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaECS, Format: configv1alpha1.FormatSingleEvent})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(&again[0][0]).To(BeIdenticalTo(&transformed[0][0]))
+		})
+
+		It("should reject unsupported combinations of schema and format", func() {
+			_, err := payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaECS, Format: configv1alpha1.FormatEventList})
+			Expect(err).To(MatchError(`format "EventList" is not supported with schema "ECS"`))
+			_, err = payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaCEF, Format: configv1alpha1.FormatJSONArray})
+			Expect(err).To(MatchError(`format "JSONArray" is not supported with schema "CEF"`))
+		})
+
+		It("should fail to transform data which is no event list", func() {
+			payload = encoding.NewPayload([]byte(`not json`))
+			_, err := payload.Bodies(encoding.Encoding{Schema: configv1alpha1.SchemaOCSF})
+			Expect(err).To(MatchError(ContainSubstring("failed to decode events")))
+			_, err = payload.Bodies(encoding.Encoding{Format: configv1alpha1.FormatNDJSON})
+			Expect(err).To(MatchError(ContainSubstring("failed to decode events")))
+		})
+
+		DescribeTable("should return the content type",
+			func(schema configv1alpha1.Schema, format configv1alpha1.Format, contentType string) {
+				Expect(encoding.Encoding{Schema: schema, Format: format}.ContentType()).To(Equal(contentType))
+			},
+			Entry("Kubernetes audit", configv1alpha1.SchemaKubernetesAudit, configv1alpha1.Format(""), "application/json"),
+			Entry("Kubernetes audit NDJSON", configv1alpha1.SchemaKubernetesAudit, configv1alpha1.FormatNDJSON, "application/x-ndjson"),
+			Entry("ECS", configv1alpha1.SchemaECS, configv1alpha1.Format(""), "application/x-ndjson"),
+			Entry("ECS JSON array", configv1alpha1.SchemaECS, configv1alpha1.FormatJSONArray, "application/json"),
+			Entry("OCSF single event", configv1alpha1.SchemaOCSF, configv1alpha1.FormatSingleEvent, "application/json"),
+			Entry("CEF", configv1alpha1.SchemaCEF, configv1alpha1.Format(""), "text/plain; charset=utf-8"),
+			Entry("CEF single event", configv1alpha1.SchemaCEF, configv1alpha1.FormatSingleEvent, "text/plain; charset=utf-8"),
+		)
+	})
+
+	Describe("#Events", func() {
+		It("should count the events and return the timestamps of those which have one", func() {
+			payload = encoding.NewPayload([]byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[` +
+				`{"auditID":"a","stageTimestamp":"2025-01-15T10:30:00.000000Z"},` +
+				`{"auditID":"b","requestReceivedTimestamp":"2025-01-15T10:29:00.000000Z"},` +
+				`{"auditID":"c"}]}`))
+
+			Expect(payload.Events()).To(Equal(3))
+			Expect(payload.EventTimestamps()).To(HaveExactElements(
+				BeTemporally("==", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)),
+				BeTemporally("==", time.Date(2025, 1, 15, 10, 29, 0, 0, time.UTC)),
+			))
+		})
+
+		It("should return an error for data which cannot be decoded", func() {
+			payload = encoding.NewPayload([]byte("invalid"))
+
+			_, err := payload.Events()
+			Expect(err).To(HaveOccurred())
+			_, err = payload.EventTimestamps()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#Derived", func() {
+		It("should derive the payload once per key", func() {
+			var calls atomic.Int32
+			derive := func(data []byte) ([]byte, error) {
+				calls.Add(1)
+				return bytes.ToUpper(data), nil
+			}
+
+			first, err := payload.Derived("upper", derive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(first.Data()).To(Equal(bytes.ToUpper(data)))
+			second, err := payload.Derived("upper", derive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+			Expect(calls.Load()).To(Equal(int32(1)))
+
+			other, err := payload.Derived("other", derive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(other).NotTo(BeIdenticalTo(first))
+			Expect(calls.Load()).To(Equal(int32(2)))
+		})
+
+		It("should return the error of the derivation", func() {
+			_, err := payload.Derived("failing", func([]byte) ([]byte, error) { return nil, errors.New("fake") })
+			Expect(err).To(MatchError("fake"))
+		})
+	})
+
+	Describe("#PayloadFromContext", func() {
+		It("should return the payload stored in the context for the same data", func() {
+			ctx := encoding.WithPayload(context.Background(), payload)
+			Expect(encoding.PayloadFromContext(ctx, data)).To(BeIdenticalTo(payload))
+		})
+
+		It("should return a new payload for different data", func() {
+			ctx := encoding.WithPayload(context.Background(), payload)
+			other := []byte(`{"kind":"EventList"}`)
+
+			fromContext := encoding.PayloadFromContext(ctx, other)
+			Expect(fromContext).NotTo(BeIdenticalTo(payload))
+			Expect(fromContext.Data()).To(Equal(other))
+		})
+
+		It("should return a new payload for a copy of the data", func() {
+			ctx := encoding.WithPayload(context.Background(), payload)
+			Expect(encoding.PayloadFromContext(ctx, append([]byte(nil), data...))).NotTo(BeIdenticalTo(payload))
+		})
+
+		It("should return a new payload if the context holds none", func() {
+			Expect(&encoding.PayloadFromContext(context.Background(), data).Data()[0]).To(BeIdenticalTo(&data[0]))
+		})
+	})
+})
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encryption
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode"
+
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+// Plaintext is a decrypted request body.
+type Plaintext struct {
+	// Data is the decrypted data.
+	Data []byte
+	// ContentType is the media type of the data before compression.
+	ContentType string
+	// ContentEncoding is the compression of the data, empty if the data is not compressed.
+	ContentEncoding string
+}
+
+// Decrypt decrypts a JWE in JSON or compact serialization with the first of the private keys which is a recipient.
+func Decrypt(ciphertext []byte, keys []crypto.PrivateKey) (*Plaintext, error) {
+	object, err := jose.ParseEncrypted(string(ciphertext), keyAlgorithms, contentEncryptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWE: %w", err)
+	}
+
+	var errs []error
+	for _, key := range keys {
+		_, header, data, err := object.DecryptMulti(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		plaintext := &Plaintext{Data: data}
+		plaintext.ContentType, _ = header.ExtraHeaders[jose.HeaderContentType].(string)
+		plaintext.ContentEncoding, _ = header.ExtraHeaders[HeaderContentEncoding].(string)
+		return plaintext, nil
+	}
+	return nil, fmt.Errorf("none of the %d private keys can decrypt the JWE: %w", len(keys), errors.Join(errs...))
+}
+
+// Split splits a stream of JWEs in JSON or compact serialization, e.g. a file of stored request bodies.
+// The JWEs may be separated by whitespace.
+func Split(data []byte) ([][]byte, error) {
+	var jwes [][]byte
+	for data = bytes.TrimSpace(data); len(data) > 0; data = bytes.TrimSpace(data) {
+		if data[0] != '{' {
+			// The compact serialization consists of base64url encoded parts separated by dots and contains no whitespace.
+			end := bytes.IndexFunc(data, unicode.IsSpace)
+			if end < 0 {
+				end = len(data)
+			}
+			jwes = append(jwes, data[:end])
+			data = data[end:]
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		var jwe json.RawMessage
+		if err := decoder.Decode(&jwe); err != nil {
+			return nil, fmt.Errorf("failed to decode JWE: %w", err)
+		}
+		jwes = append(jwes, jwe)
+		data = data[decoder.InputOffset():]
+	}
+	return jwes, nil
+}
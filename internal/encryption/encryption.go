@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package encryption implements the envelope encryption of request bodies as JSON Web Encryption (JWE).
+package encryption
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+const (
+	// MediaTypeJWE is the media type of a JWE in JSON serialization.
+	MediaTypeJWE = "application/jose+json"
+
+	// HeaderContentEncoding is the protected header holding the compression of the plaintext, if it is compressed.
+	HeaderContentEncoding jose.HeaderKey = "content-encoding"
+
+	// minRSAKeySize is the minimum size of RSA recipient keys in bits.
+	minRSAKeySize = 2048
+)
+
+var (
+	// keyAlgorithms are the supported algorithms to encrypt the content encryption key for a recipient.
+	keyAlgorithms = []jose.KeyAlgorithm{jose.RSA_OAEP_256, jose.ECDH_ES_A256KW}
+	// contentEncryptions are the supported algorithms to encrypt the plaintext.
+	contentEncryptions = []jose.ContentEncryption{jose.A256GCM}
+)
+
+// Encrypter encrypts request bodies for a fixed set of recipients.
+// It is safe for concurrent use.
+type Encrypter struct {
+	encrypter jose.Encrypter
+}
+
+// NewEncrypter creates an encrypter for the recipients whose public keys are loaded from the given files.
+// The content type and the content encoding of the plaintext are added as protected headers,
+// so that the recipients can restore the original request body.
+func NewEncrypter(recipientPublicKeyFiles []string, contentType, contentEncoding string) (*Encrypter, error) {
+	recipients := make([]jose.Recipient, 0, len(recipientPublicKeyFiles))
+	for _, path := range recipientPublicKeyFiles {
+		key, err := LoadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		recipient, err := newRecipient(key)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported public key in %s: %w", path, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	options := (&jose.EncrypterOptions{}).WithContentType(jose.ContentType(contentType))
+	if contentEncoding != "" {
+		options = options.WithHeader(HeaderContentEncoding, contentEncoding)
+	}
+	encrypter, err := jose.NewMultiEncrypter(contentEncryptions[0], recipients, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encrypter: %w", err)
+	}
+	return &Encrypter{encrypter: encrypter}, nil
+}
+
+// Encrypt encrypts the plaintext and returns the JWE in JSON serialization.
+func (e *Encrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	object, err := e.encrypter.Encrypt(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt data: %w", err)
+	}
+	return []byte(object.FullSerialize()), nil
+}
+
+// newRecipient returns the recipient for the public key, identified by the key's JWK thumbprint.
+func newRecipient(key crypto.PublicKey) (jose.Recipient, error) {
+	var algorithm jose.KeyAlgorithm
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k.Size()*8 < minRSAKeySize {
+			return jose.Recipient{}, fmt.Errorf("RSA key has %d bits, at least %d bits are required", k.Size()*8, minRSAKeySize)
+		}
+		algorithm = jose.RSA_OAEP_256
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return jose.Recipient{}, fmt.Errorf("ECDSA curve %s is not supported", k.Curve.Params().Name)
+		}
+		algorithm = jose.ECDH_ES_A256KW
+	default:
+		return jose.Recipient{}, fmt.Errorf("key type %T is not supported", key)
+	}
+
+	keyID, err := KeyID(key)
+	if err != nil {
+		return jose.Recipient{}, err
+	}
+	return jose.Recipient{Algorithm: algorithm, Key: key, KeyID: keyID}, nil
+}
+
+// KeyID returns the ID of a public key, which is its base64url encoded SHA-256 JWK thumbprint as defined in RFC 7638.
+func KeyID(key crypto.PublicKey) (string, error) {
+	jwk := jose.JSONWebKey{Key: key}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute key ID: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
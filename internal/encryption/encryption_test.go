@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encryption_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/auditlog-forwarder/internal/encryption"
+)
+
+var _ = Describe("Encryption", func() {
+	var (
+		dir        string
+		rsaKey     *rsa.PrivateKey
+		ecKey      *ecdsa.PrivateKey
+		publicKeys []string
+		plaintext  = []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[]}`)
+	)
+
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)).To(Succeed())
+		return path
+	}
+
+	writePublicKey := func(name string, key crypto.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		Expect(err).NotTo(HaveOccurred())
+		return writePEM(name, "PUBLIC KEY", der)
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		publicKeys = []string{writePublicKey("rsa.pub", &rsaKey.PublicKey), writePublicKey("ec.pub", &ecKey.PublicKey)}
+	})
+
+	It("should encrypt the data for all recipients", func() {
+		encrypter, err := encryption.NewEncrypter(publicKeys, "application/json", "gzip")
+		Expect(err).NotTo(HaveOccurred())
+
+		ciphertext, err := encrypter.Encrypt(plaintext)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ciphertext).NotTo(ContainSubstring("EventList"))
+
+		var jwe struct {
+			Recipients []struct {
+				Header map[string]any `json:"header"`
+			} `json:"recipients"`
+		}
+		Expect(json.Unmarshal(ciphertext, &jwe)).To(Succeed())
+		rsaKeyID, err := encryption.KeyID(&rsaKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		ecKeyID, err := encryption.KeyID(&ecKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jwe.Recipients).To(HaveLen(2))
+		Expect(jwe.Recipients[0].Header).To(And(HaveKeyWithValue("alg", "RSA-OAEP-256"), HaveKeyWithValue("kid", rsaKeyID)))
+		Expect(jwe.Recipients[1].Header).To(And(HaveKeyWithValue("alg", "ECDH-ES+A256KW"), HaveKeyWithValue("kid", ecKeyID)))
+
+		for _, key := range []crypto.PrivateKey{rsaKey, ecKey} {
+			decrypted, err := encryption.Decrypt(ciphertext, []crypto.PrivateKey{key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal(&encryption.Plaintext{Data: plaintext, ContentType: "application/json", ContentEncoding: "gzip"}))
+		}
+	})
+
+	It("should not add a content encoding for uncompressed data", func() {
+		encrypter, err := encryption.NewEncrypter(publicKeys[:1], "application/x-ndjson", "")
+		Expect(err).NotTo(HaveOccurred())
+		ciphertext, err := encrypter.Encrypt(plaintext)
+		Expect(err).NotTo(HaveOccurred())
+
+		decrypted, err := encryption.Decrypt(ciphertext, []crypto.PrivateKey{rsaKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decrypted).To(Equal(&encryption.Plaintext{Data: plaintext, ContentType: "application/x-ndjson"}))
+	})
+
+	It("should fail to decrypt without the key of a recipient", func() {
+		encrypter, err := encryption.NewEncrypter(publicKeys[:1], "application/json", "")
+		Expect(err).NotTo(HaveOccurred())
+		ciphertext, err := encrypter.Encrypt(plaintext)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = encryption.Decrypt(ciphertext, []crypto.PrivateKey{ecKey})
+		Expect(err).To(MatchError(ContainSubstring("none of the 1 private keys can decrypt the JWE")))
+	})
+
+	It("should fail to decrypt data which is no JWE", func() {
+		_, err := encryption.Decrypt(plaintext, []crypto.PrivateKey{rsaKey})
+		Expect(err).To(MatchError(ContainSubstring("failed to parse JWE")))
+	})
+
+	DescribeTable("should reject unsupported recipient keys",
+		func(key func() crypto.PublicKey, message string) {
+			_, err := encryption.NewEncrypter([]string{writePublicKey("key.pub", key())}, "application/json", "")
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("RSA key with less than 2048 bits", func() crypto.PublicKey {
+			key, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			return &key.PublicKey
+		}, "RSA key has 1024 bits, at least 2048 bits are required"),
+		Entry("Ed25519 key", func() crypto.PublicKey {
+			key, _, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			return key
+		}, "key type ed25519.PublicKey is not supported"),
+	)
+
+	It("should fail for missing recipient key files", func() {
+		_, err := encryption.NewEncrypter([]string{filepath.Join(dir, "missing.pub")}, "application/json", "")
+		Expect(err).To(MatchError(ContainSubstring("failed to read key file")))
+	})
+
+	Describe("#Split", func() {
+		It("should split a stream of JWEs in JSON and compact serialization", func() {
+			Expect(encryption.Split([]byte("\n{\"ciphertext\":\"a\"}{\"ciphertext\":\"b\"}\n eyJ.a.b.c.d\neyJ.e.f.g.h\n"))).To(Equal([][]byte{
+				[]byte(`{"ciphertext":"a"}`),
+				[]byte(`{"ciphertext":"b"}`),
+				[]byte("eyJ.a.b.c.d"),
+				[]byte("eyJ.e.f.g.h"),
+			}))
+		})
+
+		It("should fail for truncated JSON", func() {
+			_, err := encryption.Split([]byte(`{"ciphertext":`))
+			Expect(err).To(MatchError(ContainSubstring("failed to decode JWE")))
+		})
+	})
+
+	Describe("#LoadPrivateKey", func() {
+		It("should load PKCS #8, PKCS #1 and SEC 1 keys", func() {
+			pkcs8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encryption.LoadPrivateKey(writePEM("pkcs8.pem", "PRIVATE KEY", pkcs8))).To(Equal(ecKey))
+
+			Expect(encryption.LoadPrivateKey(writePEM("pkcs1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)))).To(Equal(rsaKey))
+
+			sec1, err := x509.MarshalECPrivateKey(ecKey)
+			Expect(err).NotTo(HaveOccurred())
+			path := filepath.Join(dir, "sec1.pem")
+			// OpenSSL writes the EC parameters in front of the key.
+			data := append(pem.EncodeToMemory(&pem.Block{Type: "EC PARAMETERS", Bytes: []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}}),
+				pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})...)
+			Expect(os.WriteFile(path, data, 0600)).To(Succeed())
+			Expect(encryption.LoadPrivateKey(path)).To(Equal(ecKey))
+		})
+
+		It("should fail for public keys", func() {
+			_, err := encryption.LoadPrivateKey(publicKeys[0])
+			Expect(err).To(MatchError(ContainSubstring(`unsupported PEM block of type "PUBLIC KEY"`)))
+		})
+	})
+})
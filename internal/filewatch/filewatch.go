@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package filewatch implements the reloading of files when they change.
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+// DefaultDebounce is the default delay after a filesystem event before the files are reloaded.
+// Kubernetes secret updates produce multiple events in rapid succession; this coalesces them.
+const DefaultDebounce = 500 * time.Millisecond
+
+// Watcher calls a reload function when watched files change.
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// Watch calls reload when one of the files changes until ctx is done or the returned [Watcher] is closed.
+// The parent directories are watched to handle Kubernetes volume mounts where files are symlinks that get atomically
+// swapped. Filesystem events are coalesced for the debounce duration, a duration of 0 reloads on every event.
+// Empty paths are skipped; if no files are given, nothing is watched.
+func Watch(ctx context.Context, log logr.Logger, files []string, debounce time.Duration, reload func()) (*Watcher, error) {
+	w := &Watcher{done: make(chan struct{})}
+
+	dirs := parentDirectories(files)
+	if len(dirs) == 0 {
+		close(w.done)
+		return w, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("failed to watch directory %s: %w", dir, err)
+		}
+	}
+	w.watcher = watcher
+
+	go func() {
+		defer close(w.done)
+		defer func() { _ = watcher.Close() }()
+		w.run(ctx, log, debounce, reload)
+	}()
+	return w, nil
+}
+
+// Close stops watching the files and waits until a running reload completed.
+func (w *Watcher) Close() error {
+	var err error
+	if w.watcher != nil {
+		err = w.watcher.Close()
+	}
+	<-w.done
+	return err
+}
+
+// run is the event loop of the watcher.
+func (w *Watcher) run(ctx context.Context, log logr.Logger, debounce time.Duration, reload func()) {
+	// The timer is armed on each relevant event, so that the reload happens once the events stopped for the
+	// debounce duration. debounceC is nil while no reload is pending.
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+	var debounceC <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-debounceC:
+			debounceC = nil
+			reload()
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// Rename is included because Kubernetes volume updates atomically rename the `..data` symlink into place,
+			// which on some platforms surfaces as a Rename rather than a Create on the target.
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) &&
+				!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			timer.Reset(debounce)
+			debounceC = timer.C
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Error(err, "File watcher error")
+		}
+	}
+}
+
+// parentDirectories returns the unique parent directories of the given files, skipping empty paths.
+func parentDirectories(files []string) []string {
+	var dirs []string
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		if dir := filepath.Dir(file); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
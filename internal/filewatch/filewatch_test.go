@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package filewatch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/auditlog-forwarder/internal/filewatch"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		file    string
+		reloads atomic.Int32
+		reload  func()
+	)
+
+	BeforeEach(func() {
+		file = filepath.Join(GinkgoT().TempDir(), "file")
+		Expect(os.WriteFile(file, []byte("initial"), 0600)).To(Succeed())
+		reloads.Store(0)
+		reload = func() { reloads.Add(1) }
+	})
+
+	It("should reload once after a burst of changes", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		watcher, err := filewatch.Watch(ctx, logr.Discard(), []string{file}, 100*time.Millisecond, reload)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(watcher.Close()).To(Succeed()) }()
+
+		for range 3 {
+			Expect(os.WriteFile(file, []byte("changed"), 0600)).To(Succeed())
+		}
+
+		Eventually(reloads.Load).Should(Equal(int32(1)))
+		Consistently(reloads.Load, 300*time.Millisecond).Should(Equal(int32(1)))
+	})
+
+	It("should stop reloading once closed", func() {
+		watcher, err := filewatch.Watch(context.Background(), logr.Discard(), []string{file}, 0, reload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watcher.Close()).To(Succeed())
+
+		Expect(os.WriteFile(file, []byte("changed"), 0600)).To(Succeed())
+		Consistently(reloads.Load, 200*time.Millisecond).Should(BeZero())
+	})
+
+	It("should not watch anything without files", func() {
+		watcher, err := filewatch.Watch(context.Background(), logr.Discard(), []string{""}, 0, reload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watcher.Close()).To(Succeed())
+	})
+
+	It("should fail for missing directories", func() {
+		_, err := filewatch.Watch(context.Background(), logr.Discard(), []string{filepath.Join(file, "missing", "file")}, 0, reload)
+		Expect(err).To(MatchError(ContainSubstring("failed to watch directory")))
+	})
+})
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	headerContentEncoding = "Content-Encoding"
+
+	// minRatioCheckSize is the decompressed size from which on the decompression ratio is enforced.
+	// Small bodies, e.g. of repetitive events, can legitimately have a high ratio.
+	minRatioCheckSize = 1 << 20
+
+	reasonBodyTooLarge               = "body_too_large"
+	reasonDecompressedBodyTooLarge   = "decompressed_body_too_large"
+	reasonDecompressionRatioExceeded = "decompression_ratio_exceeded"
+	reasonUnsupportedContentEncoding = "unsupported_content_encoding"
+	reasonMalformedBody              = "malformed_body"
+)
+
+// RequestLimits defines the limits for the bodies of incoming audit requests.
+// A zero value disables the respective limit.
+type RequestLimits struct {
+	// MaxBodySize is the maximum size of a request body as received, i.e. before decompression.
+	MaxBodySize int64
+	// MaxDecompressedBodySize is the maximum size of a compressed request body after decompression.
+	MaxDecompressedBodySize int64
+	// MaxDecompressionRatio is the maximum ratio between the decompressed and the compressed size of a request body.
+	MaxDecompressionRatio int64
+}
+
+// rejectionError is returned when a request body is rejected because of its size or encoding.
+type rejectionError struct {
+	statusCode int
+	reason     string
+	message    string
+}
+
+func (e *rejectionError) Error() string {
+	return e.message
+}
+
+var (
+	errDecompressedBodyTooLarge = &rejectionError{
+		statusCode: http.StatusRequestEntityTooLarge,
+		reason:     reasonDecompressedBodyTooLarge,
+		message:    "decompressed request body too large",
+	}
+	errDecompressionRatioExceeded = &rejectionError{
+		statusCode: http.StatusRequestEntityTooLarge,
+		reason:     reasonDecompressionRatioExceeded,
+		message:    "request body decompression ratio exceeded",
+	}
+)
+
+// readBody reads the request body, enforcing the request limits and decompressing gzip and zstd encoded bodies.
+// Bodies violating a limit or using an unsupported encoding result in a [*rejectionError].
+func (h *Handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body := r.Body
+	if h.requestLimits.MaxBodySize > 0 {
+		body = http.MaxBytesReader(w, r.Body, h.requestLimits.MaxBodySize)
+	}
+
+	contentEncoding := strings.ToLower(strings.TrimSpace(r.Header.Get(headerContentEncoding)))
+	switch contentEncoding {
+	case "", "identity":
+		data, err := io.ReadAll(body)
+		if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+			return nil, newBodyTooLargeError(maxBytesErr)
+		}
+		return data, err
+	case "gzip", "zstd":
+		data, err := h.decompress(body, contentEncoding)
+		if err == nil {
+			return data, nil
+		}
+		if maxBytesErr := new(http.MaxBytesError); errors.As(err, &maxBytesErr) {
+			return nil, newBodyTooLargeError(maxBytesErr)
+		}
+		if rejectionErr := new(rejectionError); errors.As(err, &rejectionErr) {
+			return nil, err
+		}
+		return nil, &rejectionError{
+			statusCode: http.StatusBadRequest,
+			reason:     reasonMalformedBody,
+			message:    fmt.Sprintf("failed to decompress %s request body: %v", contentEncoding, err),
+		}
+	default:
+		return nil, &rejectionError{
+			statusCode: http.StatusUnsupportedMediaType,
+			reason:     reasonUnsupportedContentEncoding,
+			message:    fmt.Sprintf("unsupported content encoding %q", contentEncoding),
+		}
+	}
+}
+
+// decompress reads and decompresses the given body while guarding against decompression bombs.
+func (h *Handler) decompress(body io.Reader, contentEncoding string) ([]byte, error) {
+	compressed := &countingReader{r: body}
+
+	var decompressed io.Reader
+	switch contentEncoding {
+	case "gzip":
+		gz, err := gzip.NewReader(compressed)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		decompressed = gz
+	case "zstd":
+		options := []zstd.DOption{zstd.WithDecoderConcurrency(1)}
+		if h.requestLimits.MaxDecompressedBodySize > 0 {
+			options = append(options, zstd.WithDecoderMaxMemory(uint64(h.requestLimits.MaxDecompressedBodySize))) //#nosec G115 -- the limit is positive.
+		}
+		decoder, err := zstd.NewReader(compressed, options...)
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		decompressed = decoder
+	}
+
+	if h.requestLimits.MaxDecompressedBodySize > 0 {
+		// Read one byte more than allowed to detect bodies exceeding the limit without reading them completely.
+		decompressed = io.LimitReader(decompressed, h.requestLimits.MaxDecompressedBodySize+1)
+	}
+
+	data, err := io.ReadAll(&bombGuard{
+		r:          decompressed,
+		compressed: compressed,
+		maxSize:    h.requestLimits.MaxDecompressedBodySize,
+		maxRatio:   h.requestLimits.MaxDecompressionRatio,
+	})
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, errDecompressedBodyTooLarge
+	}
+	return data, err
+}
+
+func newBodyTooLargeError(err *http.MaxBytesError) *rejectionError {
+	return &rejectionError{
+		statusCode: http.StatusRequestEntityTooLarge,
+		reason:     reasonBodyTooLarge,
+		message:    fmt.Sprintf("request body too large, limit is %d bytes", err.Limit),
+	}
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// bombGuard fails reading decompressed data once its size or its ratio to the compressed size exceeds the limits.
+type bombGuard struct {
+	r          io.Reader
+	compressed *countingReader
+	maxSize    int64
+	maxRatio   int64
+	n          int64
+}
+
+func (b *bombGuard) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+
+	if b.maxSize > 0 && b.n > b.maxSize {
+		return n, errDecompressedBodyTooLarge
+	}
+	if b.maxRatio > 0 && b.n > minRatioCheckSize && b.n > b.maxRatio*b.compressed.n {
+		return n, errDecompressionRatioExceeded
+	}
+	return n, err
+}
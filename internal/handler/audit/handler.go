@@ -6,63 +6,171 @@ package audit
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	loggerctx "github.com/gardener/auditlog-forwarder/internal/context"
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
 	"github.com/gardener/auditlog-forwarder/internal/metrics"
 	"github.com/gardener/auditlog-forwarder/internal/output"
 	"github.com/gardener/auditlog-forwarder/internal/processor"
+	"github.com/gardener/auditlog-forwarder/internal/tracing"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
 
+// errShuttingDown is returned for deliveries to Quorum outputs which are started after the shutdown began.
+var errShuttingDown = errors.New("handler is shutting down")
+
 const (
 	headerContentType = "Content-Type"
 	mimeAppJSON       = "application/json"
+	headerRetryAfter  = "Retry-After"
+
+	reasonTooManyRequests = "too_many_requests"
 )
 
 // Handler handles incoming audit events.
 // It processes events through configured processors and sends them to configured outputs.
 type Handler struct {
-	logger            logr.Logger
-	processors        []processor.Processor
-	guaranteedOutputs []output.Output
-	bestEffortOutputs []output.Output
-	shutdownCtx       context.Context
-	shutdownCancel    context.CancelFunc
-	bestEffortWg      sync.WaitGroup
-}
-
-// NewHandler creates a new [Handler].
-func NewHandler(logger logr.Logger, processors []processor.Processor, guaranteedOutputs, bestEffortOutputs []output.Output) (*Handler, error) {
-	if len(guaranteedOutputs) == 0 {
-		return nil, errors.New("at least one Guaranteed output must be configured")
-	}
+	logger              logr.Logger
+	processors          []processor.Processor
+	guaranteedOutputs   []output.Output
+	bestEffortOutputs   []output.Output
+	quorumOutputs       []output.Output
+	quorumMinSuccessful int
+	requestLimits       RequestLimits
+	// inFlight limits the number of concurrently processed requests, nil means unlimited.
+	inFlight        chan struct{}
+	retryAfter      time.Duration
+	queueConfig     BestEffortQueue
+	bestEffortQueue *bestEffortQueue
+	shutdownCtx     context.Context
+	shutdownCancel  context.CancelFunc
+	bestEffortWg    sync.WaitGroup
+	// quorumWg tracks deliveries to Quorum outputs which continue after the quorum was reached.
+	// Deliveries are only added while holding quorumMu and before quorumClosed is set by the shutdown,
+	// so that they are never added while the shutdown waits for them.
+	quorumWg     sync.WaitGroup
+	quorumMu     sync.Mutex
+	quorumClosed bool
 
-	shutdownCtx, shutdownCancel := context.WithCancel(context.Background()) //#nosec // G118: Handler.Shutdown method is calling the Cancel func.
+	// newestDelivered tracks the newest delivered event timestamp per output and delivery mode,
+	// so that concurrent deliveries of older events do not move the gauge backwards.
+	newestDeliveredMu sync.Mutex
+	newestDelivered   map[string]time.Time
+}
 
-	return &Handler{
+// NewHandler creates a new [Handler]. The audit events must either be forwarded to Guaranteed outputs or to a group
+// of Quorum outputs configured with [WithQuorumOutputs], which replaces the Guaranteed outputs.
+func NewHandler(logger logr.Logger, processors []processor.Processor, guaranteedOutputs, bestEffortOutputs []output.Output, options ...Option) (*Handler, error) {
+	h := &Handler{
 		logger:            logger,
 		processors:        processors,
 		guaranteedOutputs: guaranteedOutputs,
 		bestEffortOutputs: bestEffortOutputs,
-		shutdownCtx:       shutdownCtx,
-		shutdownCancel:    shutdownCancel,
-	}, nil
+		queueConfig:       defaultBestEffortQueue,
+	}
+
+	for _, opt := range options {
+		if err := opt(h); err != nil {
+			return nil, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+
+	if len(h.guaranteedOutputs) == 0 && len(h.quorumOutputs) == 0 {
+		return nil, errors.New("at least one Guaranteed output must be configured, or a group of Quorum outputs replacing it")
+	}
+	if len(h.guaranteedOutputs) > 0 && len(h.quorumOutputs) > 0 {
+		return nil, errors.New("outputs with Guaranteed and Quorum delivery modes cannot be combined, the group of Quorum outputs replaces the Guaranteed outputs")
+	}
+
+	h.guaranteedOutputs = trackOutputs(h.guaranteedOutputs, configv1alpha1.DeliveryModeGuaranteed)
+	h.quorumOutputs = trackOutputs(h.quorumOutputs, configv1alpha1.DeliveryModeQuorum)
+	h.bestEffortOutputs = trackOutputs(h.bestEffortOutputs, configv1alpha1.DeliveryModeBestEffort)
+	h.newestDelivered = make(map[string]time.Time, len(h.guaranteedOutputs)+len(h.quorumOutputs)+len(h.bestEffortOutputs))
+
+	for i, p := range h.processors {
+		if holder, ok := p.(processor.Holder); ok {
+			holder.Start(h.release(i))
+		}
+	}
+
+	h.shutdownCtx, h.shutdownCancel = context.WithCancel(context.Background()) //#nosec // G118: Handler.Shutdown method is calling the Cancel func.
+
+	if len(h.bestEffortOutputs) > 0 {
+		h.bestEffortQueue = newBestEffortQueue(h.queueConfig.Size, h.queueConfig.OverflowPolicy)
+		for range h.queueConfig.Workers {
+			h.bestEffortWg.Go(h.deliverToBestEffortOutputs)
+		}
+	}
+
+	return h, nil
+}
+
+// deliverToBestEffortOutputs delivers queued requests to the BestEffort outputs until the queue is closed and drained.
+// Requests still queued when the shutdown timeout is exceeded are dropped.
+func (h *Handler) deliverToBestEffortOutputs() {
+	for {
+		d, ok := h.bestEffortQueue.pop()
+		if !ok {
+			return
+		}
+		if h.shutdownCtx.Err() != nil {
+			drop(d, reasonShutdown)
+			continue
+		}
+		h.forwardToBestEffortOutputs(d.ctx, d.data, h.bestEffortOutputs, d.log)
+	}
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	metrics.AuditReceived.Inc()
+	start := time.Now()
+	defer func() { metrics.RequestDuration.Observe(time.Since(start).Seconds()) }()
+
+	reqID := uuid.NewString()
+	log := h.logger.WithValues("req_id", reqID)
 
-	log := h.logger.WithValues("req_id", uuid.NewString())
-	body, err := io.ReadAll(r.Body)
+	ctx, span := startServerSpan(r, reqID)
+	defer span.End()
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	w = recorder
+	defer func() { recordStatus(span, recorder.statusCode) }()
+
+	if h.inFlight != nil {
+		select {
+		case h.inFlight <- struct{}{}:
+			defer func() { <-h.inFlight }()
+		default:
+			w.Header().Set(headerRetryAfter, strconv.Itoa(int(math.Ceil(h.retryAfter.Seconds()))))
+			rejectRequest(w, log, &rejectionError{
+				statusCode: http.StatusTooManyRequests,
+				reason:     reasonTooManyRequests,
+				message:    "too many audit requests in flight",
+			})
+			return
+		}
+	}
+	metrics.AuditInFlight.Inc()
+	defer metrics.AuditInFlight.Dec()
+
+	// The body is read with the original writer, so that http.MaxBytesReader can close the connection.
+	body, err := h.readBody(recorder.ResponseWriter, r)
+	if rejectionErr := new(rejectionError); errors.As(err, &rejectionErr) {
+		rejectRequest(w, log, rejectionErr)
+		return
+	}
 	if err != nil {
 		log.Error(err, "Reading request body")
 		w.Header().Set(headerContentType, mimeAppJSON)
@@ -72,25 +180,41 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := loggerctx.WithLogger(r.Context(), log)
+	ctx = loggerctx.WithLogger(ctx, log)
 	log.Info("Received audit events")
+	metrics.RequestBodySize.Observe(float64(len(body)))
+	// The payload caches the number of events, so that the body is decoded for it once. It is reused for the
+	// processed data if the processors did not change the body.
+	ctx = encoding.WithPayload(ctx, encoding.NewPayload(body))
+	// The events of bodies which cannot be decoded are not counted.
+	receivedEvents, err := encoding.PayloadFromContext(ctx, body).Events()
+	if err == nil {
+		metrics.RequestEvents.Observe(float64(receivedEvents))
+		span.SetAttributes(tracing.AttributeEventCount.Int(receivedEvents))
+	}
 
-	processedData := body
-	for _, processor := range h.processors {
-		processedData, err = processor.Process(ctx, processedData)
-		if err != nil {
-			log.Error(err, "Processing audit events", "processor", processor.Name())
-			w.Header().Set(headerContentType, mimeAppJSON)
-			w.WriteHeader(http.StatusInternalServerError)
-			writeErrorResponse(w, log, http.StatusInternalServerError, "failed processing audit events")
-			metrics.AuditFailed.Inc()
+	processedData, err := processAll(ctx, h.processors, body)
+	if err != nil {
+		log.Error(err, "Processing audit events")
+		w.Header().Set(headerContentType, mimeAppJSON)
+		w.WriteHeader(http.StatusInternalServerError)
+		writeErrorResponse(w, log, http.StatusInternalServerError, "failed processing audit events")
+		metrics.AuditFailed.Inc()
+		return
+	}
+
+	// Requests whose events were all dropped by the processors, e.g. by sampling, are not forwarded.
+	payload := encoding.PayloadFromContext(ctx, processedData)
+	if receivedEvents > 0 {
+		if events, err := payload.Events(); err == nil && events == 0 {
+			log.Info("Dropped all audit events while processing")
+			w.WriteHeader(http.StatusOK)
+			metrics.AuditSucceeded.Inc()
 			return
 		}
 	}
 
-	// Send to Guaranteed outputs first - these must succeed for request to be successful
-	if err := forwardToGuaranteedOutputs(ctx, processedData, h.guaranteedOutputs, log); err != nil {
-		log.Error(err, "Failed to forward audit events to Guaranteed outputs")
+	if err := h.forward(ctx, payload, log); err != nil {
 		w.Header().Set(headerContentType, mimeAppJSON)
 		w.WriteHeader(http.StatusInternalServerError)
 		writeErrorResponse(w, log, http.StatusInternalServerError, "failed forwarding audit events")
@@ -98,69 +222,160 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fire off BestEffort outputs asynchronously - they don't block the response
-	if len(h.bestEffortOutputs) > 0 {
-		h.bestEffortWg.Go(func() {
-			forwardToBestEffortOutputs(h.shutdownCtx, processedData, h.bestEffortOutputs, log)
-		})
-	}
-
-	log.Info("Forwarded audit events to Guaranteed outputs")
+	log.Info("Forwarded audit events to required outputs")
 	w.WriteHeader(http.StatusOK)
 	metrics.AuditSucceeded.Inc()
 }
 
-// Shutdown initiates graceful shutdown of the handler, waiting for in-flight
-// BestEffort outputs to complete within the given timeout.
-// It waits for all active BestEffort goroutines to finish, canceling the
+// forward forwards the processed data to the outputs. It returns an error if the Guaranteed outputs, or the quorum
+// of the Quorum outputs replacing them, failed. Once they succeeded, the delivery to the BestEffort outputs is queued
+// and the data is committed to the processors keeping track of the forwarded events.
+func (h *Handler) forward(ctx context.Context, payload *encoding.Payload, log logr.Logger) error {
+	// The payload caches the encodings of the processed data, so that outputs requiring the same encoding share it.
+	processedData := payload.Data()
+	ctx = encoding.WithPayload(ctx, payload)
+	bgCtx := encoding.WithPayload(h.shutdownCtx, payload)
+	// Background deliveries are traced as part of the request, even if they end after it.
+	bgCtx = trace.ContextWithSpanContext(bgCtx, trace.SpanContextFromContext(ctx))
+
+	if len(h.quorumOutputs) > 0 {
+		// The required number of Quorum outputs must succeed for the request to be successful
+		if err := h.forwardToQuorumOutputs(ctx, bgCtx, processedData, h.quorumOutputs, h.quorumMinSuccessful, log); err != nil {
+			log.Error(err, "Failed to forward audit events to Quorum outputs")
+			return err
+		}
+	} else if err := h.forwardToGuaranteedOutputs(ctx, processedData, h.guaranteedOutputs, log); err != nil {
+		// All Guaranteed outputs must succeed for the request to be successful
+		log.Error(err, "Failed to forward audit events to Guaranteed outputs")
+		return err
+	}
+
+	// Queue the delivery to BestEffort outputs - they don't block the response
+	if h.bestEffortQueue != nil {
+		h.bestEffortQueue.push(bestEffortDelivery{ctx: bgCtx, data: processedData, log: log})
+	}
+
+	for _, p := range h.processors {
+		if committer, ok := p.(processor.Committer); ok {
+			committer.Commit(ctx, processedData)
+		}
+	}
+	return nil
+}
+
+// release returns the function with which the processor at the given index releases held audit events.
+// The released events are processed by the following processors and forwarded to the outputs.
+func (h *Handler) release(index int) processor.ReleaseFunc {
+	holder := h.processors[index]
+	following := h.processors[index+1:]
+	return func(ctx context.Context, data []byte) error {
+		log := h.logger.WithValues("req_id", uuid.NewString(), "releasedBy", holder.Name())
+		ctx = loggerctx.WithLogger(ctx, log)
+
+		processedData, err := processAll(ctx, following, data)
+		if err != nil {
+			log.Error(err, "Processing released audit events")
+			return err
+		}
+		payload := encoding.NewPayload(processedData)
+		if events, err := payload.Events(); err == nil && events == 0 {
+			return nil
+		}
+		if err := h.forward(ctx, payload, log); err != nil {
+			return err
+		}
+		log.Info("Forwarded released audit events to required outputs")
+		return nil
+	}
+}
+
+// Shutdown initiates graceful shutdown of the handler, releasing the audit events held by processors and waiting
+// for queued and in-flight BestEffort and background Quorum deliveries to complete within the given timeout.
+// Once the held events are released, requests to Quorum outputs are rejected, as their deliveries could not be awaited.
+// It waits for all active background goroutines to finish, canceling the
 // shutdown context only after timeout to stop any remaining work.
 func (h *Handler) Shutdown(timeout time.Duration) error {
 	h.logger.Info("Initiating handler shutdown", "timeout", timeout.String())
+	deadline := time.Now().Add(timeout)
+
+	// Held audit events are released first, so that their deliveries to the BestEffort outputs are still queued.
+	flushCtx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	for _, p := range h.processors {
+		if holder, ok := p.(processor.Holder); ok {
+			if err := holder.Flush(flushCtx); err != nil {
+				h.logger.Error(err, "Failed to flush held audit events", "processor", p.Name())
+			}
+		}
+	}
+
+	// Requests still in flight are rejected from now on, as their Quorum deliveries could not be awaited.
+	h.quorumMu.Lock()
+	h.quorumClosed = true
+	h.quorumMu.Unlock()
+	if h.bestEffortQueue != nil {
+		h.bestEffortQueue.close()
+	}
 
 	done := make(chan struct{})
 	go func() {
 		h.bestEffortWg.Wait()
+		h.quorumWg.Wait()
 		close(done)
 	}()
 
 	select {
 	case <-done:
-		h.logger.Info("All BestEffort outputs completed successfully or maximum retries reached")
+		h.logger.Info("All BestEffort and Quorum outputs completed successfully or maximum retries reached")
 		h.shutdownCancel()
 		return nil
-	case <-time.After(timeout):
+	case <-time.After(time.Until(deadline)):
 		// Cancel shutdown context to stop any ongoing retries
 		h.shutdownCancel()
-		return fmt.Errorf("shutdown timeout exceeded after %s, some BestEffort outputs may not have completed", timeout)
+		return fmt.Errorf("shutdown timeout exceeded after %s, some BestEffort or Quorum outputs may not have completed", timeout)
 	}
 }
 
+// rejectRequest responds to a rejected request with the status code and message of the given error.
+func rejectRequest(w http.ResponseWriter, log logr.Logger, rejectionErr *rejectionError) {
+	log.Error(rejectionErr, "Rejecting audit request", "reason", rejectionErr.reason)
+	w.Header().Set(headerContentType, mimeAppJSON)
+	w.WriteHeader(rejectionErr.statusCode)
+	writeErrorResponse(w, log, rejectionErr.statusCode, rejectionErr.message)
+	metrics.AuditRejected.WithLabelValues(rejectionErr.reason).Inc()
+	metrics.AuditFailed.Inc()
+}
+
 func writeErrorResponse(w http.ResponseWriter, log logr.Logger, statusCode int, message string) {
-	if _, err := fmt.Fprintf(w, `{"code":%d,"message":"%s"}`, statusCode, message); err != nil {
+	// Messages may contain details of errors, hence they are escaped.
+	escapedMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Error(err, "Encoding response message")
+		return
+	}
+	if _, err := fmt.Fprintf(w, `{"code":%d,"message":%s}`, statusCode, escapedMessage); err != nil {
 		log.Error(err, "Writing response body")
 	}
 }
 
 // forwardToGuaranteedOutputs forwards audit events to Guaranteed outputs.
 // All Guaranteed outputs must succeed for the request to be considered successful.
-func forwardToGuaranteedOutputs(ctx context.Context,
+func (h *Handler) forwardToGuaranteedOutputs(ctx context.Context,
 	data []byte,
 	outputs []output.Output,
 	log logr.Logger,
 ) error {
-	logAndMeterOutputErr := func(out output.Output, err error) error {
+	logOutputErr := func(out output.Output, err error) error {
 		log.Error(err, "Failed to forward to Guaranteed output", "output", out.Name())
-		metrics.OutputFailed.WithLabelValues(out.Name(), string(configv1alpha1.DeliveryModeGuaranteed)).Inc()
 		return fmt.Errorf("output %s failed: %w", out.Name(), err)
 	}
 
 	// Single output, no need to initialize a wait group and spawn goroutines
 	if len(outputs) == 1 {
 		out := outputs[0]
-		if err := out.Send(ctx, data); err != nil {
-			return logAndMeterOutputErr(out, err)
+		if err := h.send(ctx, out, data, configv1alpha1.DeliveryModeGuaranteed); err != nil {
+			return logOutputErr(out, err)
 		}
-		metrics.OutputSucceeded.WithLabelValues(out.Name(), string(configv1alpha1.DeliveryModeGuaranteed)).Inc()
 		return nil
 	}
 
@@ -171,10 +386,8 @@ func forwardToGuaranteedOutputs(ctx context.Context,
 		wg.Add(1)
 		go func(o output.Output) {
 			defer wg.Done()
-			if err := o.Send(ctx, data); err != nil {
-				errCh <- logAndMeterOutputErr(o, err)
-			} else {
-				metrics.OutputSucceeded.WithLabelValues(o.Name(), string(configv1alpha1.DeliveryModeGuaranteed)).Inc()
+			if err := h.send(ctx, o, data, configv1alpha1.DeliveryModeGuaranteed); err != nil {
+				errCh <- logOutputErr(o, err)
 			}
 		}(out)
 	}
@@ -195,9 +408,72 @@ func forwardToGuaranteedOutputs(ctx context.Context,
 	return nil
 }
 
-// forwardToBestEffortOutputs forwards audit events to BestEffort outputs asynchronously.
+// forwardToQuorumOutputs forwards audit events to Quorum outputs in parallel.
+// It returns as soon as minSuccessful outputs succeeded; delivery to the remaining outputs
+// continues in the background bound to bgCtx and is awaited by the shutdown.
+// It fails as soon as the quorum can no longer be reached or ctx is done, and if the shutdown already began.
+func (h *Handler) forwardToQuorumOutputs(
+	ctx context.Context,
+	bgCtx context.Context,
+	data []byte,
+	outputs []output.Output,
+	minSuccessful int,
+	log logr.Logger,
+) error {
+	sendCtx := loggerctx.WithLogger(bgCtx, log)
+
+	h.quorumMu.Lock()
+	if h.quorumClosed {
+		h.quorumMu.Unlock()
+		return errShuttingDown
+	}
+	h.quorumWg.Add(len(outputs))
+	h.quorumMu.Unlock()
+
+	// The channel is buffered so that outputs finishing after the quorum was decided never block.
+	resultCh := make(chan error, len(outputs))
+	for _, out := range outputs {
+		go func() {
+			defer h.quorumWg.Done()
+			if err := h.send(sendCtx, out, data, configv1alpha1.DeliveryModeQuorum); err != nil {
+				log.Error(err, "Failed to forward to Quorum output", "output", out.Name())
+				resultCh <- fmt.Errorf("output %s failed: %w", out.Name(), err)
+				return
+			}
+			resultCh <- nil
+		}()
+	}
+
+	var (
+		succeeded int
+		errs      []error
+	)
+	for range outputs {
+		select {
+		case err := <-resultCh:
+			if err == nil {
+				succeeded++
+				if succeeded >= minSuccessful {
+					return nil
+				}
+				continue
+			}
+
+			errs = append(errs, err)
+			if len(outputs)-len(errs) < minSuccessful {
+				return fmt.Errorf("quorum of %d out of %d Quorum outputs cannot be reached: %w", minSuccessful, len(outputs), errors.Join(errs...))
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("canceled while waiting for quorum of %d out of %d Quorum outputs: %w", minSuccessful, len(outputs), ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+// forwardToBestEffortOutputs forwards audit events to BestEffort outputs in parallel.
 // Failures are logged and tracked in metrics but do not affect the request status.
-func forwardToBestEffortOutputs(
+func (h *Handler) forwardToBestEffortOutputs(
 	ctx context.Context,
 	data []byte,
 	outputs []output.Output,
@@ -210,15 +486,81 @@ func forwardToBestEffortOutputs(
 		wg.Add(1)
 		go func(o output.Output) {
 			defer wg.Done()
-			if err := o.Send(ctx, data); err != nil {
+			if err := h.send(ctx, o, data, configv1alpha1.DeliveryModeBestEffort); err != nil {
 				log.Error(err, "Failed to forward to BestEffort output", "output", o.Name())
-				metrics.OutputFailed.WithLabelValues(o.Name(), string(configv1alpha1.DeliveryModeBestEffort)).Inc()
 			} else {
 				log.Info("Successfully forwarded to BestEffort output", "output", o.Name())
-				metrics.OutputSucceeded.WithLabelValues(o.Name(), string(configv1alpha1.DeliveryModeBestEffort)).Inc()
 			}
 		}(out)
 	}
 
 	wg.Wait()
 }
+
+// processAll processes audit events with the processors in order.
+func processAll(ctx context.Context, processors []processor.Processor, data []byte) ([]byte, error) {
+	for _, p := range processors {
+		processed, err := process(ctx, p, data)
+		if err != nil {
+			return nil, fmt.Errorf("processor %s failed: %w", p.Name(), err)
+		}
+		data = processed
+	}
+	return data, nil
+}
+
+// process processes audit events with the processor and records the metrics and span of the processing.
+func process(ctx context.Context, p processor.Processor, data []byte) ([]byte, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Processor.Process", trace.WithAttributes(tracing.AttributeProcessor.String(p.Name())))
+	defer span.End()
+
+	start := time.Now()
+	processed, err := p.Process(ctx, data)
+	metrics.ProcessorDuration.WithLabelValues(p.Name()).Observe(time.Since(start).Seconds())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "processing failed")
+	}
+	return processed, err
+}
+
+// send sends audit events to the output and records the metrics and span of the send.
+func (h *Handler) send(ctx context.Context, out output.Output, data []byte, deliveryMode configv1alpha1.DeliveryMode) error {
+	labels := []string{out.Name(), string(deliveryMode)}
+
+	ctx, span := tracing.Tracer().Start(ctx, "Output.Send", trace.WithAttributes(
+		tracing.AttributeOutput.String(out.Name()),
+		tracing.AttributeDeliveryMode.String(string(deliveryMode)),
+	))
+	defer span.End()
+
+	inFlight := metrics.OutputInFlight.WithLabelValues(labels...)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	ctx, attempts := output.WithAttemptCounter(ctx)
+	ctx, sent := output.WithSentPayload(ctx)
+	start := time.Now()
+	err := out.Send(ctx, data)
+	metrics.OutputSendDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	// Outputs without retries do not record their attempts.
+	if n := attempts(); n > 0 {
+		metrics.OutputSendAttempts.WithLabelValues(labels...).Observe(float64(n))
+	}
+
+	if err != nil {
+		metrics.OutputFailed.WithLabelValues(labels...).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "send failed")
+		return err
+	}
+	metrics.OutputSucceeded.WithLabelValues(labels...).Inc()
+	// The delivery lag is observed for the events the output sent, which differ from the received ones
+	// if they were processed by the output, e.g. filtered.
+	payload, recorded := sent()
+	if !recorded {
+		payload = encoding.PayloadFromContext(ctx, data)
+	}
+	h.observeDeliveryLag(payload, labels)
+	return nil
+}
@@ -6,19 +6,34 @@ package audit
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/klauspost/compress/zstd"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	prommodels "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/apis/audit"
 
@@ -26,8 +41,10 @@ import (
 	"github.com/gardener/auditlog-forwarder/internal/metrics"
 	"github.com/gardener/auditlog-forwarder/internal/output"
 	outputfactory "github.com/gardener/auditlog-forwarder/internal/output/factory"
+	outputhttp "github.com/gardener/auditlog-forwarder/internal/output/http"
 	"github.com/gardener/auditlog-forwarder/internal/processor"
 	"github.com/gardener/auditlog-forwarder/internal/processor/annotation"
+	"github.com/gardener/auditlog-forwarder/internal/processor/filter"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
 
@@ -43,6 +60,49 @@ func (t *testProcessor) Process(_ context.Context, data []byte) ([]byte, error)
 
 func (t *testProcessor) Name() string { return t.name }
 
+// testCommitter is a processor which records the committed data.
+type testCommitter struct {
+	testProcessor
+	committed [][]byte
+}
+
+func (t *testCommitter) Commit(_ context.Context, data []byte) {
+	t.committed = append(t.committed, data)
+}
+
+// testHolder is a processor which holds back all audit events until it is flushed.
+type testHolder struct {
+	release processor.ReleaseFunc
+	held    []byte
+}
+
+func (t *testHolder) Process(_ context.Context, data []byte) ([]byte, error) {
+	t.held = data
+	return []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[]}`), nil
+}
+
+func (t *testHolder) Name() string                        { return "holder" }
+func (t *testHolder) Start(release processor.ReleaseFunc) { t.release = release }
+func (t *testHolder) Flush(ctx context.Context) error     { return t.release(ctx, t.held) }
+
+// fakeOutput is an output whose Send behavior is controlled by the test.
+type fakeOutput struct {
+	name string
+	send func(context.Context, []byte) error
+}
+
+func (f *fakeOutput) Send(ctx context.Context, data []byte) error { return f.send(ctx, data) }
+func (f *fakeOutput) Name() string                                { return f.name }
+func (f *fakeOutput) Close() error                                { return nil }
+
+// fakeReportingOutput is a fakeOutput which reports a fixed status.
+type fakeReportingOutput struct {
+	fakeOutput
+	status output.Status
+}
+
+func (f *fakeReportingOutput) Status() output.Status { return f.status }
+
 var _ = Describe("Handler", func() {
 	var (
 		logger      logr.Logger
@@ -60,7 +120,7 @@ var _ = Describe("Handler", func() {
 			"test-key": "test-value",
 		}
 		processors = []processor.Processor{
-			annotation.New(annotations),
+			annotation.New(annotations, false),
 		}
 
 		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -87,6 +147,19 @@ var _ = Describe("Handler", func() {
 		metrics.AuditReceived = promauto.NewCounter(prometheus.CounterOpts{Name: randString()})
 		metrics.AuditSucceeded = promauto.NewCounter(prometheus.CounterOpts{Name: randString()})
 		metrics.AuditFailed = promauto.NewCounter(prometheus.CounterOpts{Name: randString()})
+		metrics.AuditRejected = promauto.NewCounterVec(prometheus.CounterOpts{Name: randString()}, []string{"reason"})
+		metrics.AuditInFlight = promauto.NewGauge(prometheus.GaugeOpts{Name: randString()})
+		metrics.BestEffortQueueLength = promauto.NewGauge(prometheus.GaugeOpts{Name: randString()})
+		metrics.BestEffortDropped = promauto.NewCounterVec(prometheus.CounterOpts{Name: randString()}, []string{"reason"})
+		metrics.RequestDuration = promauto.NewHistogram(prometheus.HistogramOpts{Name: randString()})
+		metrics.RequestBodySize = promauto.NewHistogram(prometheus.HistogramOpts{Name: randString()})
+		metrics.RequestEvents = promauto.NewHistogram(prometheus.HistogramOpts{Name: randString()})
+		metrics.ProcessorDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{Name: randString()}, []string{"processor"})
+		metrics.OutputSendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{Name: randString()}, []string{"output", "delivery_mode"})
+		metrics.OutputSendAttempts = promauto.NewHistogramVec(prometheus.HistogramOpts{Name: randString()}, []string{"output", "delivery_mode"})
+		metrics.OutputDeliveryLag = promauto.NewHistogramVec(prometheus.HistogramOpts{Name: randString()}, []string{"output", "delivery_mode"})
+		metrics.OutputNewestDeliveredTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{Name: randString()}, []string{"output", "delivery_mode"})
+		metrics.OutputInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{Name: randString()}, []string{"output", "delivery_mode"})
 		metrics.OutputSucceeded = promauto.NewCounterVec(prometheus.CounterOpts{Name: randString()}, []string{"output", "delivery_mode"})
 		metrics.OutputFailed = promauto.NewCounterVec(prometheus.CounterOpts{Name: randString()}, []string{"output", "delivery_mode"})
 	})
@@ -212,6 +285,32 @@ var _ = Describe("Handler", func() {
 			Expect(getMetricValue(metrics.OutputFailed)).To(Equal(0.0))
 		})
 
+		It("should not forward requests whose events were all dropped by the processors", func() {
+			dropAll := processor.Processor(&testProcessor{
+				name:      "drop-all",
+				transform: func([]byte) []byte { return []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[]}`) },
+			})
+			var err error
+			handler, err = NewHandler(logger, []processor.Processor{dropAll}, outputInsts, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items:    []audit.Event{{Verb: "list"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			response = nil
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Consistently(func() []byte { return response }, 50*time.Millisecond).Should(BeEmpty())
+			Expect(getMetricValue(metrics.AuditSucceeded)).To(Equal(1.0))
+			Expect(getMetricValue(metrics.OutputSucceeded)).To(Equal(0.0))
+		})
+
 		It("should return error when output fails", func() {
 			// Close the test server to simulate output failure
 			testServer.Close()
@@ -247,6 +346,29 @@ var _ = Describe("Handler", func() {
 			Expect(getMetricValue(metrics.OutputFailed)).To(Equal(1.0))
 		})
 
+		It("should commit the processed data once it was forwarded to the required outputs", func() {
+			committer := &testCommitter{testProcessor: testProcessor{
+				name:      "committer",
+				transform: func(data []byte) []byte { return append(data, []byte("->B")...) },
+			}}
+			var err error
+			handler, err = NewHandler(logger, []processor.Processor{committer}, outputInsts, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader([]byte("A")))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(committer.committed).To(Equal([][]byte{[]byte("A->B")}))
+
+			testServer.Close()
+			req = httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader([]byte("C")))
+			w = httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+			Expect(committer.committed).To(HaveLen(1))
+		})
+
 		It("should handle malformed request body", func() {
 			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader([]byte("invalid json")))
 			req.Header.Set("Content-Type", "application/json")
@@ -281,6 +403,715 @@ var _ = Describe("Handler", func() {
 		})
 	})
 
+	Describe("Latency and size metrics", func() {
+		var body []byte
+
+		BeforeEach(func() {
+			var err error
+			body, err = helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items:    []audit.Event{{Verb: "create"}, {Verb: "update"}, {Verb: "delete"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		serve := func() {
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+		}
+
+		It("should observe the request, processor and output metrics", func() {
+			var err error
+			handler, err = NewHandler(logger, processors, outputInsts, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			serve()
+
+			Expect(getMetricValue(metrics.RequestDuration)).To(Equal(1.0))
+			Expect(getHistogramSum(metrics.RequestBodySize)).To(Equal(float64(len(body))))
+			Expect(getHistogramSum(metrics.RequestEvents)).To(Equal(3.0))
+			Expect(getMetricValue(metrics.ProcessorDuration.WithLabelValues(processors[0].Name()).(prometheus.Histogram))).To(Equal(1.0))
+
+			outputLabels := []string{testServer.URL, string(configv1alpha1.DeliveryModeGuaranteed)}
+			Expect(getMetricValue(metrics.OutputSendDuration.WithLabelValues(outputLabels...).(prometheus.Histogram))).To(Equal(1.0))
+			Expect(getHistogramSum(metrics.OutputSendAttempts.WithLabelValues(outputLabels...).(prometheus.Histogram))).To(Equal(1.0))
+			Expect(getMetricValue(metrics.OutputInFlight.WithLabelValues(outputLabels...))).To(Equal(0.0))
+		})
+
+		It("should observe the attempts of retried sends", func() {
+			var requests atomic.Int32
+			flakyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if requests.Add(1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer flakyServer.Close()
+
+			flakyOutputs, err := outputfactory.NewHTTPOutputsWithOptions(context.Background(), []configv1alpha1.Output{{
+				DeliveryMode: configv1alpha1.DeliveryModeGuaranteed,
+				HTTP:         &configv1alpha1.OutputHTTP{URL: flakyServer.URL},
+			}}, configv1alpha1.DeliveryModeGuaranteed, outputhttp.WithBaseBackoff(time.Millisecond))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler, err = NewHandler(logger, nil, flakyOutputs, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			serve()
+
+			outputLabels := []string{flakyServer.URL, string(configv1alpha1.DeliveryModeGuaranteed)}
+			Expect(getMetricValue(metrics.OutputSendAttempts.WithLabelValues(outputLabels...).(prometheus.Histogram))).To(Equal(1.0))
+			Expect(getHistogramSum(metrics.OutputSendAttempts.WithLabelValues(outputLabels...).(prometheus.Histogram))).To(Equal(2.0))
+		})
+
+		It("should observe the delivery lag and the newest delivered event timestamp", func() {
+			out := &fakeOutput{name: randString(), send: func(context.Context, []byte) error { return nil }}
+			failing := &fakeOutput{name: randString(), send: func(context.Context, []byte) error { return errors.New("unavailable") }}
+
+			var err error
+			handler, err = NewHandler(logger, nil, []output.Output{out}, []output.Output{failing})
+			Expect(err).NotTo(HaveOccurred())
+
+			now := time.Now()
+			newest := metav1.NewMicroTime(now.Add(-time.Second))
+			body, err = helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items: []audit.Event{
+					{Verb: "create", StageTimestamp: metav1.NewMicroTime(now.Add(-2 * time.Second))},
+					{Verb: "update", RequestReceivedTimestamp: newest},
+					{Verb: "delete"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			serve()
+
+			labels := []string{out.name, string(configv1alpha1.DeliveryModeGuaranteed)}
+			lag := metrics.OutputDeliveryLag.WithLabelValues(labels...).(prometheus.Histogram)
+			Expect(getMetricValue(lag)).To(Equal(2.0))
+			Expect(getHistogramSum(lag)).To(BeNumerically(">=", 3.0))
+			newestDelivered := metrics.OutputNewestDeliveredTimestamp.WithLabelValues(labels...)
+			Expect(getMetricValue(newestDelivered)).To(BeNumerically("~", float64(newest.UnixMicro())/1e6, 1e-6))
+
+			// Older events must not move the newest delivered event timestamp backwards.
+			body, err = helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items:    []audit.Event{{Verb: "get", StageTimestamp: metav1.NewMicroTime(now.Add(-time.Minute))}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			serve()
+
+			Expect(getMetricValue(lag)).To(Equal(3.0))
+			Expect(getMetricValue(newestDelivered)).To(BeNumerically("~", float64(newest.UnixMicro())/1e6, 1e-6))
+
+			// Failed deliveries are not observed.
+			Expect(handler.Shutdown(time.Second)).To(Succeed())
+			Expect(getMetricValue(metrics.OutputDeliveryLag.WithLabelValues(failing.name, string(configv1alpha1.DeliveryModeBestEffort)).(prometheus.Histogram))).To(BeZero())
+		})
+
+		It("should only observe the events sent by outputs with processors", func() {
+			out := &fakeOutput{name: randString(), send: func(context.Context, []byte) error { return nil }}
+			creates, err := filter.New(logger, &configv1alpha1.Filter{Expression: `event.verb == "create"`})
+			Expect(err).NotTo(HaveOccurred())
+			handler, err = NewHandler(logger, nil, []output.Output{output.WithProcessors(out, creates)}, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			now := time.Now()
+			created := metav1.NewMicroTime(now.Add(-time.Minute))
+			body, err = helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items: []audit.Event{
+					{Verb: "create", StageTimestamp: created},
+					{Verb: "update", StageTimestamp: metav1.NewMicroTime(now.Add(-time.Second))},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			serve()
+
+			labels := []string{out.name, string(configv1alpha1.DeliveryModeGuaranteed)}
+			lag := metrics.OutputDeliveryLag.WithLabelValues(labels...).(prometheus.Histogram)
+			newestDelivered := metrics.OutputNewestDeliveredTimestamp.WithLabelValues(labels...)
+			Expect(getMetricValue(lag)).To(Equal(1.0))
+			Expect(getMetricValue(newestDelivered)).To(BeNumerically("~", float64(created.UnixMicro())/1e6, 1e-6))
+
+			// Requests whose events were all filtered are not observed.
+			body, err = helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items:    []audit.Event{{Verb: "update", StageTimestamp: metav1.NewMicroTime(now)}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			serve()
+
+			Expect(getMetricValue(lag)).To(Equal(1.0))
+			Expect(getMetricValue(newestDelivered)).To(BeNumerically("~", float64(created.UnixMicro())/1e6, 1e-6))
+		})
+
+		It("should track the newest delivered event timestamp per handler", func() {
+			out := &fakeOutput{name: randString(), send: func(context.Context, []byte) error { return nil }}
+			labels := []string{out.name, string(configv1alpha1.DeliveryModeGuaranteed)}
+			newestDelivered := metrics.OutputNewestDeliveredTimestamp.WithLabelValues(labels...)
+			deliver := func(timestamp time.Time) {
+				var err error
+				body, err = helper.EncodeEventList(&audit.EventList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+					Items:    []audit.Event{{Verb: "get", StageTimestamp: metav1.NewMicroTime(timestamp)}},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				serve()
+			}
+
+			var err error
+			handler, err = NewHandler(logger, nil, []output.Output{out}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			now := time.Now()
+			deliver(now)
+			Expect(handler.Shutdown(time.Second)).To(Succeed())
+
+			// A new handler, e.g. after the configuration was reloaded, does not know the events delivered before.
+			handler, err = NewHandler(logger, nil, []output.Output{out}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			older := now.Add(-time.Minute)
+			deliver(older)
+			Expect(getMetricValue(newestDelivered)).To(BeNumerically("~", float64(older.UnixMicro())/1e6, 1e-6))
+		})
+
+		It("should track in-flight BestEffort deliveries", func() {
+			release := make(chan struct{})
+			bestEffort := &fakeOutput{name: "best-effort", send: func(context.Context, []byte) error {
+				<-release
+				return nil
+			}}
+
+			var err error
+			handler, err = NewHandler(logger, nil, outputInsts, []output.Output{bestEffort})
+			Expect(err).NotTo(HaveOccurred())
+
+			serve()
+
+			inFlight := metrics.OutputInFlight.WithLabelValues("best-effort", string(configv1alpha1.DeliveryModeBestEffort))
+			Eventually(func() float64 { return getMetricValue(inFlight) }).Should(Equal(1.0))
+
+			close(release)
+			Eventually(func() float64 { return getMetricValue(inFlight) }).Should(Equal(0.0))
+		})
+	})
+
+	Describe("Tracing", func() {
+		var (
+			recorder         *tracetest.SpanRecorder
+			previousProvider trace.TracerProvider
+		)
+
+		BeforeEach(func() {
+			recorder = tracetest.NewSpanRecorder()
+			previousProvider = otel.GetTracerProvider()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+		})
+
+		AfterEach(func() {
+			otel.SetTracerProvider(previousProvider)
+			otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+		})
+
+		spanByName := func(name string) sdktrace.ReadOnlySpan {
+			for _, span := range recorder.Ended() {
+				if span.Name() == name {
+					return span
+				}
+			}
+			Fail("span " + name + " not found")
+			return nil
+		}
+
+		It("should trace the request, the processors and the output attempts and propagate the trace context", func() {
+			var traceparent atomic.Pointer[string]
+			tracedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header := r.Header.Get("traceparent")
+				traceparent.Store(&header)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer tracedServer.Close()
+
+			tracedOutputs, err := outputfactory.NewHTTPOutputsWithOptions(context.Background(), []configv1alpha1.Output{{
+				DeliveryMode: configv1alpha1.DeliveryModeGuaranteed,
+				HTTP:         &configv1alpha1.OutputHTTP{URL: tracedServer.URL},
+			}}, configv1alpha1.DeliveryModeGuaranteed)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler, err = NewHandler(logger, processors, tracedOutputs, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items:    []audit.Event{{Verb: "create"}, {Verb: "delete"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			serverSpan := spanByName("Handler.ServeHTTP")
+			Expect(serverSpan.SpanContext().TraceID().String()).To(Equal("0af7651916cd43dd8448eb211c80319c"))
+			Expect(serverSpan.Parent().SpanID().String()).To(Equal("b7ad6b7169203331"))
+			Expect(serverSpan.Attributes()).To(ContainElements(
+				HaveField("Key", BeEquivalentTo("req_id")),
+				attribute.Int("audit.event_count", 2),
+				attribute.Int("http.response.status_code", http.StatusOK),
+			))
+
+			processorSpan := spanByName("Processor.Process")
+			Expect(processorSpan.Parent().SpanID()).To(Equal(serverSpan.SpanContext().SpanID()))
+			Expect(processorSpan.Attributes()).To(ContainElement(attribute.String("processor.name", processors[0].Name())))
+
+			sendSpan := spanByName("Output.Send")
+			Expect(sendSpan.Parent().SpanID()).To(Equal(serverSpan.SpanContext().SpanID()))
+			Expect(sendSpan.Attributes()).To(ContainElements(
+				attribute.String("output.name", tracedServer.URL),
+				attribute.String("output.delivery_mode", string(configv1alpha1.DeliveryModeGuaranteed)),
+			))
+
+			attemptSpan := spanByName(http.MethodPost)
+			Expect(attemptSpan.Parent().SpanID()).To(Equal(sendSpan.SpanContext().SpanID()))
+			Expect(attemptSpan.Attributes()).To(ContainElements(
+				attribute.String("output.name", tracedServer.URL),
+				attribute.Int("output.attempt", 1),
+				attribute.Int("http.response.status_code", http.StatusOK),
+			))
+
+			Expect(*traceparent.Load()).To(Equal(fmt.Sprintf("00-%s-%s-01", attemptSpan.SpanContext().TraceID(), attemptSpan.SpanContext().SpanID())))
+		})
+
+		It("should mark the spans of failed requests", func() {
+			failing := &fakeOutput{name: "failing", send: func(context.Context, []byte) error { return errors.New("unavailable") }}
+
+			var err error
+			handler, err = NewHandler(logger, nil, []output.Output{failing}, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			req := httptest.NewRequest(http.MethodPost, "/audit", strings.NewReader(`{}`))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+
+			serverSpan := spanByName("Handler.ServeHTTP")
+			Expect(serverSpan.Status().Code).To(Equal(codes.Error))
+			Expect(serverSpan.Attributes()).To(ContainElement(attribute.Int("http.response.status_code", http.StatusInternalServerError)))
+
+			sendSpan := spanByName("Output.Send")
+			Expect(sendSpan.Status().Code).To(Equal(codes.Error))
+			Expect(sendSpan.Events()).To(ContainElement(HaveField("Name", "exception")))
+		})
+	})
+
+	Describe("Request limits", func() {
+		var (
+			received atomic.Pointer[[]byte]
+			events   []byte
+		)
+
+		newHandler := func(limits RequestLimits) {
+			out := &fakeOutput{name: "out", send: func(_ context.Context, data []byte) error {
+				received.Store(&data)
+				return nil
+			}}
+
+			var err error
+			handler, err = NewHandler(logger, nil, []output.Output{out}, nil, WithRequestLimits(limits))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		serve := func(body []byte, contentEncoding string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			if contentEncoding != "" {
+				req.Header.Set("Content-Encoding", contentEncoding)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			return w
+		}
+
+		gzipped := func(data []byte) []byte {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			_, err := gz.Write(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gz.Close()).To(Succeed())
+			return buf.Bytes()
+		}
+
+		expectRejected := func(w *httptest.ResponseRecorder, statusCode int, reason string) {
+			Expect(w.Code).To(Equal(statusCode))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(received.Load()).To(BeNil())
+			Expect(getMetricValue(metrics.AuditRejected.WithLabelValues(reason))).To(Equal(1.0))
+			Expect(getMetricValue(metrics.AuditFailed)).To(Equal(1.0))
+		}
+
+		BeforeEach(func() {
+			received.Store(nil)
+			events = []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[]}`)
+		})
+
+		It("should accept bodies within the limit", func() {
+			newHandler(RequestLimits{MaxBodySize: int64(len(events))})
+
+			Expect(serve(events, "").Code).To(Equal(http.StatusOK))
+			Expect(*received.Load()).To(Equal(events))
+		})
+
+		It("should reject bodies exceeding the limit", func() {
+			newHandler(RequestLimits{MaxBodySize: int64(len(events)) - 1})
+
+			w := serve(events, "")
+			expectRejected(w, http.StatusRequestEntityTooLarge, "body_too_large")
+			Expect(w.Body.String()).To(ContainSubstring("request body too large"))
+		})
+
+		It("should decompress gzip encoded bodies", func() {
+			newHandler(RequestLimits{MaxBodySize: 1024, MaxDecompressedBodySize: 1024, MaxDecompressionRatio: 100})
+
+			Expect(serve(gzipped(events), "gzip").Code).To(Equal(http.StatusOK))
+			Expect(*received.Load()).To(Equal(events))
+		})
+
+		It("should decompress zstd encoded bodies", func() {
+			encoder, err := zstd.NewWriter(nil)
+			Expect(err).NotTo(HaveOccurred())
+			compressed := encoder.EncodeAll(events, nil)
+			Expect(encoder.Close()).To(Succeed())
+			newHandler(RequestLimits{MaxBodySize: 1024, MaxDecompressedBodySize: 1024, MaxDecompressionRatio: 100})
+
+			Expect(serve(compressed, "zstd").Code).To(Equal(http.StatusOK))
+			Expect(*received.Load()).To(Equal(events))
+		})
+
+		It("should apply the limit to the compressed body", func() {
+			compressed := gzipped(events)
+			newHandler(RequestLimits{MaxBodySize: int64(len(compressed)) - 1})
+
+			expectRejected(serve(compressed, "gzip"), http.StatusRequestEntityTooLarge, "body_too_large")
+		})
+
+		It("should reject bodies exceeding the decompressed limit", func() {
+			newHandler(RequestLimits{MaxDecompressedBodySize: int64(len(events)) - 1})
+
+			expectRejected(serve(gzipped(events), "gzip"), http.StatusRequestEntityTooLarge, "decompressed_body_too_large")
+		})
+
+		It("should reject decompression bombs exceeding the ratio", func() {
+			bomb := gzipped(make([]byte, 8<<20))
+			newHandler(RequestLimits{MaxDecompressedBodySize: 16 << 20, MaxDecompressionRatio: 100})
+
+			expectRejected(serve(bomb, "gzip"), http.StatusRequestEntityTooLarge, "decompression_ratio_exceeded")
+		})
+
+		It("should reject malformed compressed bodies", func() {
+			newHandler(RequestLimits{})
+
+			expectRejected(serve(events, "gzip"), http.StatusBadRequest, "malformed_body")
+		})
+
+		It("should reject unsupported content encodings", func() {
+			newHandler(RequestLimits{})
+
+			w := serve(events, "br")
+			expectRejected(w, http.StatusUnsupportedMediaType, "unsupported_content_encoding")
+			Expect(w.Body.String()).To(Equal(`{"code":415,"message":"unsupported content encoding \"br\""}`))
+		})
+
+		It("should reject negative limits", func() {
+			var err error
+			handler, err = NewHandler(logger, nil, outputInsts, nil, WithRequestLimits(RequestLimits{MaxBodySize: -1}))
+			Expect(err).To(MatchError(ContainSubstring("request limits must not be negative")))
+			Expect(handler).To(BeNil())
+		})
+	})
+
+	Describe("Quorum", func() {
+		var (
+			body    []byte
+			succeed func(context.Context, []byte) error
+			fail    func(context.Context, []byte) error
+		)
+
+		BeforeEach(func() {
+			var err error
+			body, err = helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "audit.k8s.io/v1",
+					Kind:       "EventList",
+				},
+				Items: []audit.Event{{Verb: "create"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			succeed = func(context.Context, []byte) error { return nil }
+			fail = func(context.Context, []byte) error { return errors.New("unavailable") }
+		})
+
+		It("should create a handler with only Quorum outputs", func() {
+			var err error
+			handler, err = NewHandler(logger, processors, nil, nil,
+				WithQuorumOutputs([]output.Output{&fakeOutput{name: "a", send: succeed}}, 1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(handler.quorumOutputs).To(HaveLen(1))
+			Expect(handler.quorumMinSuccessful).To(Equal(1))
+		})
+
+		It("should reject an unreachable quorum", func() {
+			var err error
+			handler, err = NewHandler(logger, processors, nil, nil,
+				WithQuorumOutputs([]output.Output{&fakeOutput{name: "a", send: succeed}}, 2))
+			Expect(err).To(MatchError(ContainSubstring("must be between 1 and 1, got 2")))
+			Expect(handler).To(BeNil())
+		})
+
+		It("should succeed as soon as the quorum is reached and continue delivery in the background", func() {
+			release := make(chan struct{})
+			var slowDone atomic.Bool
+			slow := func(context.Context, []byte) error {
+				<-release
+				slowDone.Store(true)
+				return nil
+			}
+
+			var err error
+			handler, err = NewHandler(logger, processors, nil, nil, WithQuorumOutputs([]output.Output{
+				&fakeOutput{name: "a", send: succeed},
+				&fakeOutput{name: "b", send: fail},
+				&fakeOutput{name: "c", send: slow},
+			}, 1))
+			Expect(err).NotTo(HaveOccurred())
+
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(slowDone.Load()).To(BeFalse())
+			Expect(getMetricValue(metrics.AuditSucceeded)).To(Equal(1.0))
+
+			close(release)
+			Expect(handler.Shutdown(time.Second)).To(Succeed())
+			Expect(slowDone.Load()).To(BeTrue())
+			Expect(getMetricValue(metrics.OutputSucceeded)).To(Equal(2.0))
+			Expect(getMetricValue(metrics.OutputFailed)).To(Equal(1.0))
+		})
+
+		It("should fail when the quorum cannot be reached", func() {
+			var err error
+			handler, err = NewHandler(logger, processors, nil, nil, WithQuorumOutputs([]output.Output{
+				&fakeOutput{name: "a", send: succeed},
+				&fakeOutput{name: "b", send: fail},
+				&fakeOutput{name: "c", send: fail},
+			}, 2))
+			Expect(err).NotTo(HaveOccurred())
+
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+			Expect(w.Body.String()).To(ContainSubstring("failed forwarding audit events"))
+			Expect(getMetricValue(metrics.AuditFailed)).To(Equal(1.0))
+		})
+
+		It("should reject requests once the shutdown began", func() {
+			var sent atomic.Int32
+			count := func(context.Context, []byte) error {
+				sent.Add(1)
+				return nil
+			}
+
+			var err error
+			handler, err = NewHandler(logger, processors, nil, nil, WithQuorumOutputs([]output.Output{
+				&fakeOutput{name: "a", send: count},
+			}, 1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(handler.Shutdown(time.Second)).To(Succeed())
+
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+			Expect(sent.Load()).To(BeZero())
+		})
+
+		It("should reject Guaranteed outputs together with Quorum outputs", func() {
+			var err error
+			handler, err = NewHandler(logger, processors, outputInsts, nil, WithQuorumOutputs([]output.Output{
+				&fakeOutput{name: "a", send: succeed},
+			}, 1))
+			Expect(err).To(MatchError(ContainSubstring("outputs with Guaranteed and Quorum delivery modes cannot be combined")))
+			Expect(handler).To(BeNil())
+		})
+	})
+
+	Describe("Load shedding", func() {
+		var (
+			guaranteed *fakeOutput
+			release    chan struct{}
+		)
+
+		serve := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/audit", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			return w
+		}
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			guaranteed = &fakeOutput{name: "guaranteed", send: func(context.Context, []byte) error { return nil }}
+		})
+
+		Context("in-flight requests", func() {
+			It("should reject requests exceeding the limit with a Retry-After header", func() {
+				guaranteed.send = func(context.Context, []byte) error {
+					<-release
+					return nil
+				}
+
+				var err error
+				handler, err = NewHandler(logger, nil, []output.Output{guaranteed}, nil, WithMaxInFlightRequests(1, 1500*time.Millisecond))
+				Expect(err).NotTo(HaveOccurred())
+
+				done := make(chan int)
+				go func() {
+					done <- serve(`{"n":1}`).Code
+				}()
+				Eventually(func() float64 { return getMetricValue(metrics.AuditInFlight) }).Should(Equal(1.0))
+
+				w := serve(`{"n":2}`)
+				Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+				Expect(w.Header().Get("Retry-After")).To(Equal("2"))
+				Expect(w.Body.String()).To(ContainSubstring("too many audit requests in flight"))
+				Expect(getMetricValue(metrics.AuditRejected.WithLabelValues("too_many_requests"))).To(Equal(1.0))
+				Expect(getMetricValue(metrics.AuditFailed)).To(Equal(1.0))
+
+				close(release)
+				Eventually(done).Should(Receive(Equal(http.StatusOK)))
+				Expect(getMetricValue(metrics.AuditInFlight)).To(Equal(0.0))
+
+				Expect(serve(`{"n":3}`).Code).To(Equal(http.StatusOK))
+			})
+
+			It("should not limit requests by default", func() {
+				var err error
+				handler, err = NewHandler(logger, nil, []output.Output{guaranteed}, nil, WithMaxInFlightRequests(0, 0))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(handler.inFlight).To(BeNil())
+				Expect(serve(`{}`).Code).To(Equal(http.StatusOK))
+			})
+
+			It("should reject invalid limits", func() {
+				_, err := NewHandler(logger, nil, []output.Output{guaranteed}, nil, WithMaxInFlightRequests(-1, time.Second))
+				Expect(err).To(MatchError(ContainSubstring("must not be negative")))
+
+				_, err = NewHandler(logger, nil, []output.Output{guaranteed}, nil, WithMaxInFlightRequests(1, 0))
+				Expect(err).To(MatchError(ContainSubstring("retry after duration must be positive")))
+			})
+		})
+
+		Context("BestEffort queue", func() {
+			var (
+				mu        sync.Mutex
+				delivered []string
+				sending   chan struct{}
+			)
+
+			newHandler := func(policy configv1alpha1.OverflowPolicy) {
+				mu.Lock()
+				delivered = nil
+				mu.Unlock()
+				sending = make(chan struct{}, 10)
+
+				bestEffort := &fakeOutput{name: "best-effort", send: func(_ context.Context, data []byte) error {
+					sending <- struct{}{}
+					<-release
+					mu.Lock()
+					defer mu.Unlock()
+					delivered = append(delivered, string(data))
+					return nil
+				}}
+
+				var err error
+				handler, err = NewHandler(logger, nil, []output.Output{guaranteed}, []output.Output{bestEffort},
+					WithBestEffortQueue(BestEffortQueue{Size: 1, Workers: 1, OverflowPolicy: policy}))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			// fillQueue sends three requests: the first one is picked up by the only worker,
+			// the second one is queued and the third one overflows the queue.
+			fillQueue := func() {
+				Expect(serve(`{"n":1}`).Code).To(Equal(http.StatusOK))
+				Eventually(sending).Should(Receive())
+				Expect(serve(`{"n":2}`).Code).To(Equal(http.StatusOK))
+				Expect(serve(`{"n":3}`).Code).To(Equal(http.StatusOK))
+
+				Expect(getMetricValue(metrics.BestEffortQueueLength)).To(Equal(1.0))
+				Expect(getMetricValue(metrics.BestEffortDropped.WithLabelValues("queue_full"))).To(Equal(1.0))
+			}
+
+			deliveredRequests := func() []string {
+				mu.Lock()
+				defer mu.Unlock()
+				return slices.Clone(delivered)
+			}
+
+			It("should drop the oldest queued request when the queue is full", func() {
+				newHandler(configv1alpha1.OverflowPolicyDropOldest)
+				fillQueue()
+
+				close(release)
+				Expect(handler.Shutdown(time.Second)).To(Succeed())
+				Expect(deliveredRequests()).To(Equal([]string{`{"n":1}`, `{"n":3}`}))
+				Expect(getMetricValue(metrics.BestEffortQueueLength)).To(Equal(0.0))
+			})
+
+			It("should drop the newest request when the queue is full", func() {
+				newHandler(configv1alpha1.OverflowPolicyDropNewest)
+				fillQueue()
+
+				close(release)
+				Expect(handler.Shutdown(time.Second)).To(Succeed())
+				Expect(deliveredRequests()).To(Equal([]string{`{"n":1}`, `{"n":2}`}))
+			})
+
+			It("should drop queued requests when the shutdown timeout is exceeded", func() {
+				newHandler(configv1alpha1.OverflowPolicyDropOldest)
+				fillQueue()
+
+				Expect(handler.Shutdown(50 * time.Millisecond)).To(MatchError(ContainSubstring("shutdown timeout exceeded")))
+				close(release)
+				Eventually(func() float64 {
+					return getMetricValue(metrics.BestEffortDropped.WithLabelValues("shutdown"))
+				}).Should(Equal(1.0))
+				Expect(deliveredRequests()).To(Equal([]string{`{"n":1}`}))
+
+				Expect(serve(`{"n":4}`).Code).To(Equal(http.StatusOK))
+				Expect(getMetricValue(metrics.BestEffortDropped.WithLabelValues("shutdown"))).To(Equal(2.0))
+			})
+
+			It("should reject an invalid queue configuration", func() {
+				_, err := NewHandler(logger, nil, []output.Output{guaranteed}, nil, WithBestEffortQueue(BestEffortQueue{Size: 1}))
+				Expect(err).To(MatchError(ContainSubstring("must be at least 1")))
+
+				_, err = NewHandler(logger, nil, []output.Output{guaranteed}, nil,
+					WithBestEffortQueue(BestEffortQueue{Size: 1, Workers: 1, OverflowPolicy: "DropAll"}))
+				Expect(err).To(MatchError(ContainSubstring(`unsupported overflow policy "DropAll"`)))
+			})
+		})
+	})
+
 	Describe("Shutdown", func() {
 		var (
 			bestEffortServer   *httptest.Server
@@ -342,6 +1173,31 @@ var _ = Describe("Handler", func() {
 			Eventually(func() bool { return len(bestEffortResponse) > 0 }, 50*time.Millisecond).Should(BeTrue())
 		})
 
+		It("should release held events through the following processors to the outputs", func() {
+			var err error
+			handler, err = NewHandler(logger, []processor.Processor{&testHolder{}, annotation.New(annotations, false)}, outputInsts, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := helper.EncodeEventList(&audit.EventList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+				Items:    []audit.Event{{Verb: "create"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			response = nil
+			req := httptest.NewRequest(http.MethodPost, "/audit", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(response).To(BeEmpty())
+
+			Expect(handler.Shutdown(2 * time.Second)).To(Succeed())
+			forwarded, err := helper.DecodeEventList(response)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(forwarded.Items).To(HaveLen(1))
+			Expect(forwarded.Items[0].Annotations).To(HaveKeyWithValue("test-key", "test-value"))
+		})
+
 		It("should return timeout error if BestEffort outputs take too long", func() {
 			slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				time.Sleep(time.Second) // Longer than shutdown timeout
@@ -399,6 +1255,60 @@ var _ = Describe("Handler", func() {
 			Expect(initialCtx.Err()).To(Equal(context.Canceled))
 		})
 	})
+
+	Describe("Output status", func() {
+		It("should report the outcome of the last sends and the status reported by the outputs", func() {
+			expiry := time.Now().Add(time.Hour)
+			guaranteed := &fakeReportingOutput{
+				fakeOutput: fakeOutput{name: "guaranteed", send: func(context.Context, []byte) error { return nil }},
+				status:     output.Status{Circuit: output.CircuitPartiallyOpen, CertificateExpiry: &expiry},
+			}
+			bestEffort := &fakeOutput{name: "best-effort", send: func(context.Context, []byte) error { return errors.New("unavailable") }}
+
+			var err error
+			handler, err = NewHandler(logger, nil, []output.Output{guaranteed}, []output.Output{bestEffort})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(handler.OutputStatuses()).To(Equal([]OutputStatus{
+				{Name: "guaranteed", DeliveryMode: configv1alpha1.DeliveryModeGuaranteed, Circuit: output.CircuitPartiallyOpen, CertificateExpiry: &expiry},
+				{Name: "best-effort", DeliveryMode: configv1alpha1.DeliveryModeBestEffort},
+			}))
+
+			before := time.Now()
+			req := httptest.NewRequest(http.MethodPost, "/audit", strings.NewReader(`{"kind":"EventList"}`))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(handler.Shutdown(time.Second)).To(Succeed())
+
+			statuses := handler.OutputStatuses()
+			Expect(statuses).To(HaveLen(2))
+			Expect(*statuses[0].LastSuccess).To(BeTemporally(">=", before))
+			Expect(statuses[0].LastFailure).To(BeNil())
+			Expect(statuses[0].LastError).To(BeEmpty())
+			Expect(statuses[1].LastSuccess).To(BeNil())
+			Expect(*statuses[1].LastFailure).To(BeTemporally(">=", before))
+			Expect(statuses[1].LastError).To(Equal("unavailable"))
+			Expect(statuses[1].Circuit).To(BeEmpty())
+		})
+
+		It("should report the status of outputs with processors", func() {
+			expiry := time.Now().Add(time.Hour)
+			reporting := &fakeReportingOutput{
+				fakeOutput: fakeOutput{name: "guaranteed", send: func(context.Context, []byte) error { return nil }},
+				status:     output.Status{Circuit: output.CircuitOpen, CertificateExpiry: &expiry},
+			}
+			identity := &testProcessor{name: "identity", transform: func(data []byte) []byte { return data }}
+
+			var err error
+			handler, err = NewHandler(logger, nil, []output.Output{output.WithProcessors(reporting, identity)}, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(handler.OutputStatuses()).To(Equal([]OutputStatus{
+				{Name: "guaranteed", DeliveryMode: configv1alpha1.DeliveryModeGuaranteed, Circuit: output.CircuitOpen, CertificateExpiry: &expiry},
+			}))
+		})
+	})
 })
 
 // errorReader is a reader that always returns an error
@@ -408,7 +1318,7 @@ func (e *errorReader) Read(p []byte) (n int, err error) {
 	return 0, io.ErrUnexpectedEOF
 }
 
-// getMetricValue returns the sum of the Counter metrics associated with the Collector
+// getMetricValue returns the sum of the Counter or Gauge metrics associated with the Collector
 // e.g. the metric for a non-vector, or the sum of the metrics for vector labels.
 // If the metric is a Histogram then number of samples is used.
 func getMetricValue(col prometheus.Collector) float64 {
@@ -416,6 +1326,8 @@ func getMetricValue(col prometheus.Collector) float64 {
 	collect(col, func(m *prommodels.Metric) {
 		if h := m.GetHistogram(); h != nil {
 			total += float64(h.GetSampleCount())
+		} else if g := m.GetGauge(); g != nil {
+			total += g.GetValue()
 		} else {
 			total += m.GetCounter().GetValue()
 		}
@@ -423,6 +1335,15 @@ func getMetricValue(col prometheus.Collector) float64 {
 	return total
 }
 
+// getHistogramSum returns the sum of the observations of the Histogram metrics associated with the Collector.
+func getHistogramSum(col prometheus.Collector) float64 {
+	var total float64
+	collect(col, func(m *prommodels.Metric) {
+		total += m.GetHistogram().GetSampleSum()
+	})
+	return total
+}
+
 // collect calls the function for each metric associated with the Collector
 func collect(col prometheus.Collector, do func(*prommodels.Metric)) {
 	c := make(chan prometheus.Metric)
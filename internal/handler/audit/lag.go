@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"time"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+)
+
+// observeDeliveryLag records the delivery lag of the audit events of the sent payload and the newest delivered event
+// timestamp for the given output labels. It must be called when the output acknowledged the events.
+// The payload is nil if nothing was sent. The delivery lag of bodies which cannot be decoded is not observed.
+func (h *Handler) observeDeliveryLag(payload *encoding.Payload, labels []string) {
+	if payload == nil {
+		return
+	}
+	// The timestamps are cached by the payload, so that they are extracted once for all outputs sending it.
+	timestamps, err := payload.EventTimestamps()
+	if err != nil || len(timestamps) == 0 {
+		return
+	}
+
+	acknowledged := time.Now()
+	lag := metrics.OutputDeliveryLag.WithLabelValues(labels...)
+	var newest time.Time
+	for _, timestamp := range timestamps {
+		lag.Observe(acknowledged.Sub(timestamp).Seconds())
+		if timestamp.After(newest) {
+			newest = timestamp
+		}
+	}
+
+	h.newestDeliveredMu.Lock()
+	defer h.newestDeliveredMu.Unlock()
+	key := labels[0] + "/" + labels[1]
+	if !newest.After(h.newestDelivered[key]) {
+		return
+	}
+	h.newestDelivered[key] = newest
+	metrics.OutputNewestDeliveredTimestamp.WithLabelValues(labels...).Set(float64(newest.UnixNano()) / float64(time.Second))
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gardener/auditlog-forwarder/internal/output"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// Option is a functional option for configuring a [Handler].
+type Option func(*Handler) error
+
+// WithQuorumOutputs configures a group of outputs of which at least minSuccessful must succeed
+// for a request to be successful. The group replaces the Guaranteed outputs, which must not be configured then.
+// Delivery to the remaining outputs of the group continues in the background.
+// An empty group is a no-op.
+func WithQuorumOutputs(outputs []output.Output, minSuccessful int) Option {
+	return func(h *Handler) error {
+		if len(outputs) == 0 {
+			return nil
+		}
+		if minSuccessful < 1 || minSuccessful > len(outputs) {
+			return fmt.Errorf("minimum number of successful Quorum outputs must be between 1 and %d, got %d", len(outputs), minSuccessful)
+		}
+		h.quorumOutputs = outputs
+		h.quorumMinSuccessful = minSuccessful
+		return nil
+	}
+}
+
+// WithRequestLimits configures the limits for the bodies of incoming audit requests.
+func WithRequestLimits(limits RequestLimits) Option {
+	return func(h *Handler) error {
+		if limits.MaxBodySize < 0 || limits.MaxDecompressedBodySize < 0 || limits.MaxDecompressionRatio < 0 {
+			return fmt.Errorf("request limits must not be negative, got %+v", limits)
+		}
+		h.requestLimits = limits
+		return nil
+	}
+}
+
+// WithMaxInFlightRequests limits the number of concurrently processed requests.
+// Further requests are rejected with status code 429 and a Retry-After header of retryAfter rounded up to full seconds.
+// A limit of 0 disables the limiting.
+func WithMaxInFlightRequests(limit int, retryAfter time.Duration) Option {
+	return func(h *Handler) error {
+		if limit < 0 {
+			return fmt.Errorf("maximum number of in-flight requests must not be negative, got %d", limit)
+		}
+		if limit == 0 {
+			return nil
+		}
+		if retryAfter <= 0 {
+			return fmt.Errorf("retry after duration must be positive, got %s", retryAfter)
+		}
+		h.inFlight = make(chan struct{}, limit)
+		h.retryAfter = retryAfter
+		return nil
+	}
+}
+
+// WithBestEffortQueue configures the queue for deliveries to BestEffort outputs.
+// A zero value keeps the defaults.
+func WithBestEffortQueue(queue BestEffortQueue) Option {
+	return func(h *Handler) error {
+		if queue == (BestEffortQueue{}) {
+			return nil
+		}
+		if queue.Size < 1 || queue.Workers < 1 {
+			return fmt.Errorf("best effort queue size and number of workers must be at least 1, got %+v", queue)
+		}
+		switch queue.OverflowPolicy {
+		case configv1alpha1.OverflowPolicyDropOldest, configv1alpha1.OverflowPolicyDropNewest:
+		default:
+			return fmt.Errorf("unsupported overflow policy %q", queue.OverflowPolicy)
+		}
+		h.queueConfig = queue
+		return nil
+	}
+}
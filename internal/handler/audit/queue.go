@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+const (
+	reasonQueueFull = "queue_full"
+	reasonShutdown  = "shutdown"
+)
+
+// BestEffortQueue defines the queue for deliveries to BestEffort outputs.
+type BestEffortQueue struct {
+	// Size is the maximum number of requests waiting for delivery.
+	Size int
+	// Workers is the number of workers delivering requests concurrently.
+	Workers int
+	// OverflowPolicy defines which request is dropped when the queue is full.
+	OverflowPolicy configv1alpha1.OverflowPolicy
+}
+
+// defaultBestEffortQueue is used when the handler is not configured with [WithBestEffortQueue].
+var defaultBestEffortQueue = BestEffortQueue{
+	Size:           1000,
+	Workers:        10,
+	OverflowPolicy: configv1alpha1.OverflowPolicyDropOldest,
+}
+
+// bestEffortDelivery is a request waiting for delivery to the BestEffort outputs.
+type bestEffortDelivery struct {
+	ctx  context.Context
+	data []byte
+	log  logr.Logger
+}
+
+// bestEffortQueue is a bounded FIFO queue of deliveries to BestEffort outputs.
+// When it is full, either the oldest or the newest delivery is dropped according to the overflow policy.
+type bestEffortQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []bestEffortDelivery
+	size   int
+	policy configv1alpha1.OverflowPolicy
+	closed bool
+}
+
+func newBestEffortQueue(size int, policy configv1alpha1.OverflowPolicy) *bestEffortQueue {
+	q := &bestEffortQueue{
+		items:  make([]bestEffortDelivery, 0, size),
+		size:   size,
+		policy: policy,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds a delivery to the queue. Deliveries pushed after the queue was closed are dropped.
+func (q *bestEffortQueue) push(d bestEffortDelivery) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		drop(d, reasonShutdown)
+		return
+	}
+
+	if len(q.items) >= q.size {
+		if q.policy == configv1alpha1.OverflowPolicyDropNewest {
+			drop(d, reasonQueueFull)
+			return
+		}
+		drop(q.items[0], reasonQueueFull)
+		q.items[0] = bestEffortDelivery{}
+		q.items = q.items[1:]
+	}
+
+	q.items = append(q.items, d)
+	metrics.BestEffortQueueLength.Set(float64(len(q.items)))
+	q.cond.Signal()
+}
+
+// pop removes the oldest delivery from the queue, blocking until one is available.
+// It returns false once the queue is closed and drained.
+func (q *bestEffortQueue) pop() (bestEffortDelivery, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return bestEffortDelivery{}, false
+	}
+
+	d := q.items[0]
+	q.items[0] = bestEffortDelivery{}
+	q.items = q.items[1:]
+	metrics.BestEffortQueueLength.Set(float64(len(q.items)))
+	return d, true
+}
+
+// close stops accepting new deliveries and wakes up all waiting workers.
+// Deliveries already queued can still be popped.
+func (q *bestEffortQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+func drop(d bestEffortDelivery, reason string) {
+	d.log.Info("Dropped audit events for BestEffort outputs", "reason", reason)
+	metrics.BestEffortDropped.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gardener/auditlog-forwarder/internal/output"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// OutputStatus is the status of an output as reported by the status endpoint.
+type OutputStatus struct {
+	// Name is the name of the output.
+	Name string `json:"name"`
+	// DeliveryMode is the delivery mode of the output.
+	DeliveryMode configv1alpha1.DeliveryMode `json:"deliveryMode"`
+	// LastSuccess is the time of the last successful send, nil if there was none.
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	// LastFailure is the time of the last failed send, nil if there was none.
+	LastFailure *time.Time `json:"lastFailure,omitempty"`
+	// LastError is the error of the last failed send.
+	LastError string `json:"lastError,omitempty"`
+	// Circuit is the state of the circuit of the output, empty if the output does not report it.
+	Circuit output.CircuitState `json:"circuit,omitempty"`
+	// CertificateExpiry is the expiry of the client certificate of the output, nil if it has none.
+	CertificateExpiry *time.Time `json:"certificateExpiry,omitempty"`
+}
+
+// trackedOutput records the outcome of the sends to the wrapped output.
+type trackedOutput struct {
+	output.Output
+	deliveryMode configv1alpha1.DeliveryMode
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastErr     error
+}
+
+func trackOutputs(outputs []output.Output, deliveryMode configv1alpha1.DeliveryMode) []output.Output {
+	tracked := make([]output.Output, 0, len(outputs))
+	for _, out := range outputs {
+		tracked = append(tracked, &trackedOutput{Output: out, deliveryMode: deliveryMode})
+	}
+	return tracked
+}
+
+func (t *trackedOutput) Send(ctx context.Context, data []byte) error {
+	err := t.Output.Send(ctx, data)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.lastFailure = time.Now()
+		t.lastErr = err
+	} else {
+		t.lastSuccess = time.Now()
+	}
+	return err
+}
+
+// Check actively checks the wrapped output if it supports it.
+func (t *trackedOutput) Check(ctx context.Context) error {
+	if checker, ok := t.Output.(output.Checker); ok {
+		return checker.Check(ctx)
+	}
+	return nil
+}
+
+func (t *trackedOutput) status() OutputStatus {
+	status := OutputStatus{
+		Name:         t.Name(),
+		DeliveryMode: t.deliveryMode,
+	}
+	if reporter, ok := t.Output.(output.StatusReporter); ok {
+		s := reporter.Status()
+		status.Circuit = s.Circuit
+		status.CertificateExpiry = s.CertificateExpiry
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.lastSuccess.IsZero() {
+		lastSuccess := t.lastSuccess
+		status.LastSuccess = &lastSuccess
+	}
+	if !t.lastFailure.IsZero() {
+		lastFailure := t.lastFailure
+		status.LastFailure = &lastFailure
+		status.LastError = t.lastErr.Error()
+	}
+	return status
+}
+
+// OutputStatuses returns the status of all outputs in the order Guaranteed, Quorum and BestEffort.
+func (h *Handler) OutputStatuses() []OutputStatus {
+	var statuses []OutputStatus
+	for _, outputs := range [][]output.Output{h.guaranteedOutputs, h.quorumOutputs, h.bestEffortOutputs} {
+		for _, out := range outputs {
+			statuses = append(statuses, out.(*trackedOutput).status())
+		}
+	}
+	return statuses
+}
+
+// GuaranteedOutputs returns the Guaranteed outputs, e.g. for active health checks.
+// They implement [output.Checker]; outputs which do not support active checks always pass.
+func (h *Handler) GuaranteedOutputs() []output.Output {
+	return h.guaranteedOutputs
+}
+
+// QuorumOutputs returns the Quorum outputs and the number of them which must succeed, e.g. for active health checks.
+// They implement [output.Checker]; outputs which do not support active checks always pass.
+func (h *Handler) QuorumOutputs() ([]output.Output, int) {
+	return h.quorumOutputs, h.quorumMinSuccessful
+}
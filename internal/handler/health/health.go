@@ -0,0 +1,270 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/gardener/auditlog-forwarder/internal/handler/audit"
+	"github.com/gardener/auditlog-forwarder/internal/output"
+)
+
+const (
+	headerContentType = "Content-Type"
+	mimeAppJSON       = "application/json"
+	mimeTextPlain     = "text/plain; charset=utf-8"
+)
+
+var errNotChecked = errors.New("not checked yet")
+
+// OutputSource provides the status of the outputs and the Guaranteed and Quorum outputs for active health checks.
+// It is implemented by [audit.Handler].
+type OutputSource interface {
+	// OutputStatuses returns the status of all outputs.
+	OutputStatuses() []audit.OutputStatus
+	// GuaranteedOutputs returns the Guaranteed outputs. Those implementing [output.Checker] are actively checked.
+	GuaranteedOutputs() []output.Output
+	// QuorumOutputs returns the Quorum outputs and the number of them which must succeed.
+	// Those implementing [output.Checker] are actively checked.
+	QuorumOutputs() ([]output.Output, int)
+}
+
+// Status is the response of the status endpoint.
+type Status struct {
+	// Server is the status of the audit server.
+	Server ServerStatus `json:"server"`
+	// Outputs is the status of the outputs.
+	Outputs []audit.OutputStatus `json:"outputs"`
+}
+
+// ServerStatus is the status of the audit server.
+type ServerStatus struct {
+	// CertificateExpiry is the expiry of the server certificate, nil if it is unknown.
+	CertificateExpiry *time.Time `json:"certificateExpiry,omitempty"`
+}
+
+// Handler serves the liveness, readiness and status endpoints of the auditlog forwarder.
+type Handler struct {
+	logger            logr.Logger
+	outputs           OutputSource
+	serverCertificate *x509.Certificate
+	// checkInterval is the interval of active health checks of the Guaranteed and Quorum outputs, 0 if they are disabled.
+	checkInterval time.Duration
+	now           func() time.Time
+
+	configLoaded atomic.Bool
+	mu           sync.Mutex
+	// outputsErr is the result of the last active health check of the Guaranteed and Quorum outputs.
+	outputsErr error
+}
+
+// Option is a functional option for configuring a [Handler].
+type Option func(*Handler)
+
+// WithServerCertificate configures the server certificate whose validity is checked for readiness.
+func WithServerCertificate(cert *x509.Certificate) Option {
+	return func(h *Handler) {
+		h.serverCertificate = cert
+	}
+}
+
+// WithOutputChecks enables active health checks of the Guaranteed and Quorum outputs in the given interval.
+// The forwarder is only ready if all Guaranteed outputs and the minimum number of successful Quorum outputs
+// passed the last check.
+func WithOutputChecks(interval time.Duration) Option {
+	return func(h *Handler) {
+		h.checkInterval = interval
+	}
+}
+
+// New creates a new [Handler]. It is not ready until [Handler.SetConfigLoaded] is called.
+func New(logger logr.Logger, outputs OutputSource, options ...Option) *Handler {
+	h := &Handler{
+		logger:     logger,
+		outputs:    outputs,
+		now:        time.Now,
+		outputsErr: errNotChecked,
+	}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+// SetConfigLoaded marks the configuration as loaded.
+func (h *Handler) SetConfigLoaded() {
+	h.configLoaded.Store(true)
+}
+
+// Register registers the endpoints on the given mux.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.serveLiveness)
+	mux.HandleFunc("GET /readyz", h.serveReadiness)
+	mux.HandleFunc("GET /status", h.serveStatus)
+}
+
+// Run actively checks the Guaranteed and Quorum outputs in the configured interval until ctx is done.
+// It returns immediately if active health checks are disabled.
+func (h *Handler) Run(ctx context.Context) {
+	if h.checkInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.checkInterval)
+	defer ticker.Stop()
+	for {
+		h.checkOutputs(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkOutputs checks all Guaranteed and Quorum outputs concurrently, each bounded by the check interval.
+func (h *Handler) checkOutputs(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, h.checkInterval)
+	defer cancel()
+
+	guaranteedOutputs := h.outputs.GuaranteedOutputs()
+	quorumOutputs, minSuccessful := h.outputs.QuorumOutputs()
+	var (
+		guaranteedErrs = make([]error, len(guaranteedOutputs))
+		quorumErrs     = make([]error, len(quorumOutputs))
+		wg             sync.WaitGroup
+	)
+	checkAll(ctx, &wg, guaranteedOutputs, guaranteedErrs)
+	checkAll(ctx, &wg, quorumOutputs, quorumErrs)
+	wg.Wait()
+
+	err := errors.Join(guaranteedErrs...)
+	if failed := len(slices.DeleteFunc(quorumErrs, func(err error) bool { return err == nil })); len(quorumOutputs)-failed < minSuccessful {
+		err = errors.Join(err, fmt.Errorf("only %d of %d Quorum outputs are reachable, %d required: %w",
+			len(quorumOutputs)-failed, len(quorumOutputs), minSuccessful, errors.Join(quorumErrs...)))
+	}
+	if err != nil {
+		h.logger.Error(err, "Active health check of outputs failed")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.outputsErr = err
+}
+
+// checkAll starts the checks of the given outputs, storing their errors in errs at the index of the output.
+func checkAll(ctx context.Context, wg *sync.WaitGroup, outputs []output.Output, errs []error) {
+	for i, out := range outputs {
+		checker, ok := out.(output.Checker)
+		if !ok {
+			continue
+		}
+		wg.Go(func() {
+			if err := checker.Check(ctx); err != nil {
+				errs[i] = fmt.Errorf("output %q is not reachable: %w", out.Name(), err)
+			}
+		})
+	}
+}
+
+func (h *Handler) serveLiveness(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set(headerContentType, mimeTextPlain)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// serveReadiness responds with the result of each readiness check, similar to the readyz endpoint of Kubernetes components.
+func (h *Handler) serveReadiness(w http.ResponseWriter, _ *http.Request) {
+	type check struct {
+		name string
+		err  error
+	}
+	checks := []check{
+		{name: "config", err: h.checkConfig()},
+		{name: "tls", err: h.checkTLS()},
+	}
+	if h.checkInterval > 0 {
+		h.mu.Lock()
+		checks = append(checks, check{name: "outputs", err: h.outputsErr})
+		h.mu.Unlock()
+	}
+
+	var (
+		body   strings.Builder
+		failed bool
+	)
+	for _, c := range checks {
+		if c.err != nil {
+			failed = true
+			fmt.Fprintf(&body, "[-]%s failed: %v\n", c.name, c.err)
+			continue
+		}
+		fmt.Fprintf(&body, "[+]%s ok\n", c.name)
+	}
+
+	w.Header().Set(headerContentType, mimeTextPlain)
+	if failed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		body.WriteString("readyz check failed")
+	} else {
+		body.WriteString("readyz check passed")
+	}
+	_, _ = w.Write([]byte(body.String()))
+}
+
+func (h *Handler) checkConfig() error {
+	if !h.configLoaded.Load() {
+		return errors.New("configuration not loaded")
+	}
+	return nil
+}
+
+// checkTLS checks that the server certificate is valid and that no client certificate of an output has expired.
+func (h *Handler) checkTLS() error {
+	now := h.now()
+
+	var errs []error
+	if cert := h.serverCertificate; cert != nil {
+		if now.Before(cert.NotBefore) {
+			errs = append(errs, fmt.Errorf("server certificate is not valid before %s", cert.NotBefore.Format(time.RFC3339)))
+		}
+		if now.After(cert.NotAfter) {
+			errs = append(errs, fmt.Errorf("server certificate expired at %s", cert.NotAfter.Format(time.RFC3339)))
+		}
+	}
+	for _, status := range h.outputs.OutputStatuses() {
+		if status.CertificateExpiry != nil && now.After(*status.CertificateExpiry) {
+			errs = append(errs, fmt.Errorf("client certificate of output %q expired at %s", status.Name, status.CertificateExpiry.Format(time.RFC3339)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *Handler) serveStatus(w http.ResponseWriter, _ *http.Request) {
+	status := Status{Outputs: h.outputs.OutputStatuses()}
+	if status.Outputs == nil {
+		status.Outputs = []audit.OutputStatus{}
+	}
+	if h.serverCertificate != nil {
+		expiry := h.serverCertificate.NotAfter
+		status.Server.CertificateExpiry = &expiry
+	}
+
+	w.Header().Set(headerContentType, mimeAppJSON)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		h.logger.Error(err, "Failed to write status response")
+	}
+}
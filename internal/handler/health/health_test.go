@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package health_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/auditlog-forwarder/internal/handler/audit"
+	. "github.com/gardener/auditlog-forwarder/internal/handler/health"
+	"github.com/gardener/auditlog-forwarder/internal/output"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+type fakeSource struct {
+	statuses            []audit.OutputStatus
+	guaranteed          []output.Output
+	quorum              []output.Output
+	quorumMinSuccessful int
+}
+
+func (f *fakeSource) OutputStatuses() []audit.OutputStatus  { return f.statuses }
+func (f *fakeSource) GuaranteedOutputs() []output.Output    { return f.guaranteed }
+func (f *fakeSource) QuorumOutputs() ([]output.Output, int) { return f.quorum, f.quorumMinSuccessful }
+
+type fakeCheckedOutput struct {
+	name string
+	err  atomic.Pointer[error]
+}
+
+func (f *fakeCheckedOutput) Send(context.Context, []byte) error { return nil }
+func (f *fakeCheckedOutput) Name() string                       { return f.name }
+func (f *fakeCheckedOutput) Close() error                       { return nil }
+func (f *fakeCheckedOutput) Check(context.Context) error {
+	if err := f.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+type fakeProcessor struct{}
+
+func (fakeProcessor) Process(_ context.Context, data []byte) ([]byte, error) { return data, nil }
+func (fakeProcessor) Name() string                                           { return "fake" }
+
+var _ = Describe("Handler", func() {
+	var (
+		source  *fakeSource
+		handler *Handler
+		mux     *http.ServeMux
+	)
+
+	BeforeEach(func() {
+		source = &fakeSource{}
+		mux = http.NewServeMux()
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	newHandler := func(options ...Option) {
+		handler = New(logr.Discard(), source, options...)
+		handler.Register(mux)
+	}
+
+	Describe("/healthz", func() {
+		It("should always be live", func() {
+			newHandler()
+
+			w := get("/healthz")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("ok"))
+		})
+	})
+
+	Describe("/readyz", func() {
+		It("should not be ready before the configuration is loaded", func() {
+			newHandler()
+
+			w := get("/readyz")
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(w.Body.String()).To(ContainSubstring("[-]config failed: configuration not loaded"))
+			Expect(w.Body.String()).To(ContainSubstring("[+]tls ok"))
+		})
+
+		It("should be ready once the configuration is loaded", func() {
+			newHandler(WithServerCertificate(&x509.Certificate{NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour)}))
+			handler.SetConfigLoaded()
+
+			w := get("/readyz")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("[+]config ok\n[+]tls ok\nreadyz check passed"))
+		})
+
+		It("should not be ready if the server certificate is expired", func() {
+			newHandler(WithServerCertificate(&x509.Certificate{NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(-time.Minute)}))
+			handler.SetConfigLoaded()
+
+			w := get("/readyz")
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(w.Body.String()).To(ContainSubstring("[-]tls failed: server certificate expired at"))
+		})
+
+		It("should not be ready if the server certificate is not yet valid", func() {
+			newHandler(WithServerCertificate(&x509.Certificate{NotBefore: time.Now().Add(time.Hour), NotAfter: time.Now().Add(2 * time.Hour)}))
+			handler.SetConfigLoaded()
+
+			w := get("/readyz")
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(w.Body.String()).To(ContainSubstring("[-]tls failed: server certificate is not valid before"))
+		})
+
+		It("should not be ready if the client certificate of an output is expired", func() {
+			expired := time.Now().Add(-time.Minute)
+			source.statuses = []audit.OutputStatus{{Name: "out", CertificateExpiry: &expired}}
+			newHandler()
+			handler.SetConfigLoaded()
+
+			w := get("/readyz")
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(w.Body.String()).To(ContainSubstring(`[-]tls failed: client certificate of output "out" expired at`))
+		})
+
+		Context("with active checks of outputs", func() {
+			var out *fakeCheckedOutput
+
+			BeforeEach(func() {
+				out = &fakeCheckedOutput{name: "guaranteed"}
+				source.guaranteed = []output.Output{out}
+			})
+
+			It("should reflect the result of the last check", func() {
+				newHandler(WithOutputChecks(10 * time.Millisecond))
+				handler.SetConfigLoaded()
+
+				w := get("/readyz")
+				Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+				Expect(w.Body.String()).To(ContainSubstring("[-]outputs failed: not checked yet"))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go handler.Run(ctx)
+
+				Eventually(func() int { return get("/readyz").Code }).Should(Equal(http.StatusOK))
+				Expect(get("/readyz").Body.String()).To(ContainSubstring("[+]outputs ok"))
+
+				err := errors.New("connection refused")
+				out.err.Store(&err)
+				Eventually(func() string { return get("/readyz").Body.String() }).Should(
+					ContainSubstring(`[-]outputs failed: output "guaranteed" is not reachable: connection refused`))
+			})
+
+			It("should check Guaranteed outputs with processors", func() {
+				identity := &fakeProcessor{}
+				source.guaranteed = []output.Output{output.WithProcessors(out, identity)}
+				err := errors.New("connection refused")
+				out.err.Store(&err)
+
+				newHandler(WithOutputChecks(10 * time.Millisecond))
+				handler.SetConfigLoaded()
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go handler.Run(ctx)
+
+				Eventually(func() string { return get("/readyz").Body.String() }).Should(
+					ContainSubstring(`[-]outputs failed: output "guaranteed" is not reachable: connection refused`))
+			})
+
+			It("should only require the minimum number of successful Quorum outputs", func() {
+				quorum := []*fakeCheckedOutput{{name: "quorum-1"}, {name: "quorum-2"}, {name: "quorum-3"}}
+				source.guaranteed = nil
+				source.quorum = []output.Output{quorum[0], quorum[1], quorum[2]}
+				source.quorumMinSuccessful = 2
+				err := errors.New("connection refused")
+				quorum[0].err.Store(&err)
+
+				newHandler(WithOutputChecks(10 * time.Millisecond))
+				handler.SetConfigLoaded()
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go handler.Run(ctx)
+
+				Eventually(func() int { return get("/readyz").Code }).Should(Equal(http.StatusOK))
+
+				quorum[1].err.Store(&err)
+				Eventually(func() string { return get("/readyz").Body.String() }).Should(SatisfyAll(
+					ContainSubstring("[-]outputs failed: only 1 of 3 Quorum outputs are reachable, 2 required"),
+					ContainSubstring(`output "quorum-1" is not reachable: connection refused`),
+					ContainSubstring(`output "quorum-2" is not reachable: connection refused`),
+				))
+			})
+
+			It("should not check the outputs if disabled", func() {
+				newHandler()
+				handler.SetConfigLoaded()
+				handler.Run(context.Background())
+
+				w := get("/readyz")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).NotTo(ContainSubstring("outputs"))
+			})
+		})
+	})
+
+	Describe("/status", func() {
+		It("should report the status of the server and the outputs", func() {
+			now := time.Now().UTC().Truncate(time.Second)
+			source.statuses = []audit.OutputStatus{{
+				Name:              "out",
+				DeliveryMode:      configv1alpha1.DeliveryModeGuaranteed,
+				LastSuccess:       &now,
+				LastFailure:       &now,
+				LastError:         "unavailable",
+				Circuit:           output.CircuitClosed,
+				CertificateExpiry: &now,
+			}}
+			newHandler(WithServerCertificate(&x509.Certificate{NotAfter: now}))
+
+			w := get("/status")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			ts := now.Format(time.RFC3339)
+			Expect(w.Body.String()).To(MatchJSON(`{
+				"server": {"certificateExpiry": "` + ts + `"},
+				"outputs": [{
+					"name": "out",
+					"deliveryMode": "Guaranteed",
+					"lastSuccess": "` + ts + `",
+					"lastFailure": "` + ts + `",
+					"lastError": "unavailable",
+					"circuit": "Closed",
+					"certificateExpiry": "` + ts + `"
+				}]
+			}`))
+		})
+
+		It("should report an empty list of outputs", func() {
+			newHandler()
+
+			Expect(get("/status").Body.String()).To(MatchJSON(`{"server": {}, "outputs": []}`))
+		})
+	})
+})
@@ -1,8 +1,12 @@
 package helper
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apiserver/pkg/apis/audit"
@@ -38,3 +42,59 @@ func DecodeEventList(data []byte) (*audit.EventList, error) {
 func EncodeEventList(eventList *audit.EventList) ([]byte, error) {
 	return runtime.Encode(codecs.LegacyCodec(v1.SchemeGroupVersion), eventList)
 }
+
+// EventListItems returns the encoded audit events of the encoded EventList without decoding the events.
+// Each event is compacted, so that it does not contain line breaks.
+func EventListItems(data []byte) ([][]byte, error) {
+	var eventList struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &eventList); err != nil {
+		return nil, err
+	}
+
+	items := make([][]byte, 0, len(eventList.Items))
+	for _, item := range eventList.Items {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, item); err != nil {
+			return nil, err
+		}
+		items = append(items, buf.Bytes())
+	}
+	return items, nil
+}
+
+// EventTimestamps returns the number of audit events in the encoded EventList and the stage timestamp, or if unset
+// the request received timestamp, of each event without decoding the events. Events without timestamps are skipped.
+func EventTimestamps(data []byte) (int, []time.Time, error) {
+	var eventList struct {
+		Items []struct {
+			StageTimestamp           metav1.MicroTime `json:"stageTimestamp"`
+			RequestReceivedTimestamp metav1.MicroTime `json:"requestReceivedTimestamp"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &eventList); err != nil {
+		return 0, nil, err
+	}
+
+	timestamps := make([]time.Time, 0, len(eventList.Items))
+	for _, event := range eventList.Items {
+		switch {
+		case !event.StageTimestamp.IsZero():
+			timestamps = append(timestamps, event.StageTimestamp.Time)
+		case !event.RequestReceivedTimestamp.IsZero():
+			timestamps = append(timestamps, event.RequestReceivedTimestamp.Time)
+		}
+	}
+	return len(eventList.Items), timestamps, nil
+}
+
+// EncodeEvent encodes a single audit event as "audit.k8s.io/v1" Event with encoding/json.
+// The encoding is deterministic and equals the re-encoding of the decoded "audit.k8s.io/v1" Event.
+func EncodeEvent(event *audit.Event) ([]byte, error) {
+	out := &v1.Event{}
+	if err := runtimeScheme.Convert(event, out, nil); err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
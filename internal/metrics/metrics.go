@@ -14,8 +14,22 @@ const (
 	subsystemReceived  = "received"
 	subsystemSucceeded = "succeeded"
 	subsystemFailed    = "failed"
+	subsystemRejected  = "rejected"
 	subsystemOutput    = "output"
 	name               = "total"
+
+	// CertificateServer is the certificate label value of the server certificate.
+	CertificateServer = "server"
+	// CertificateClientCA is the certificate label value of the CA bundle used to verify client certificates.
+	CertificateClientCA = "client_ca"
+	// CertificateClient is the certificate label value of the client certificates presented to the audit server.
+	CertificateClient = "client"
+	// CertificateOutputClient is the certificate label value of the client certificate of an output.
+	CertificateOutputClient = "output_client"
+	// CertificateOutputServer is the certificate label value of the server certificate presented by an output.
+	CertificateOutputServer = "output_server"
+	// CertificateOutputCA is the certificate label value of the CA bundle used to verify the server certificate of an output.
+	CertificateOutputCA = "output_ca"
 )
 
 var (
@@ -40,6 +54,135 @@ var (
 		Help:      "Total number of failed processed audit requests.",
 	})
 
+	AuditRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemRejected,
+		Name:      name,
+		Help:      "Total number of rejected audit requests per reason.",
+	}, []string{"reason"})
+
+	AuditInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "in_flight_requests",
+		Help:      "Number of audit requests currently being processed.",
+	})
+
+	BestEffortQueueLength = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystemOutput,
+		Name:      "best_effort_queue_length",
+		Help:      "Number of audit requests waiting for delivery to BestEffort outputs.",
+	})
+
+	BestEffortDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystemOutput,
+		Name:      "best_effort_dropped_total",
+		Help:      "Total number of audit requests dropped before delivery to BestEffort outputs per reason.",
+	}, []string{"reason"})
+
+	RequestDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "End-to-end latency of audit requests in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	RequestBodySize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_body_size_bytes",
+		Help:      "Size of audit request bodies in bytes after decompression.",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+	})
+
+	RequestEvents = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_events",
+		Help:      "Number of audit events per request.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	ProcessorDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "duration_seconds",
+		Help:      "Latency of processing audit requests per processor in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 8),
+	}, []string{"processor"})
+
+	SampledOutEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "sampled_out_events_total",
+		Help:      "Total number of audit events dropped by sampling per rule.",
+	}, []string{"rule"})
+
+	DuplicateEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "duplicate_events_dropped_total",
+		Help:      "Total number of audit events dropped because they were already forwarded.",
+	})
+
+	MergedEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "merged_events_total",
+		Help:      "Total number of RequestReceived audit events dropped because the request completed.",
+	})
+
+	HeldEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "held_events",
+		Help:      "Number of RequestReceived audit events held until their request completes.",
+	})
+
+	HeldEventsReleaseFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "processor",
+		Name:      "held_events_release_failures_total",
+		Help:      "Total number of failed releases of held audit events, which are held again.",
+	})
+
+	OutputSendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystemOutput,
+		Name:      "send_duration_seconds",
+		Help:      "Latency of sends per output in seconds, including retries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"output", "delivery_mode"})
+
+	OutputSendAttempts = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystemOutput,
+		Name:      "send_attempts",
+		Help:      "Number of attempts per send and output.",
+		Buckets:   prometheus.LinearBuckets(1, 1, 10),
+	}, []string{"output", "delivery_mode"})
+
+	OutputDeliveryLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystemOutput,
+		Name:      "delivery_lag_seconds",
+		Help:      "Time between the stage timestamp of audit events and their successful delivery per output in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 3, 12),
+	}, []string{"output", "delivery_mode"})
+
+	OutputNewestDeliveredTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystemOutput,
+		Name:      "newest_delivered_event_timestamp_seconds",
+		Help:      "Unix timestamp of the newest audit event successfully delivered per output.",
+	}, []string{"output", "delivery_mode"})
+
+	OutputInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystemOutput,
+		Name:      "in_flight_sends",
+		Help:      "Number of sends currently in progress per output, e.g. background deliveries to BestEffort outputs.",
+	}, []string{"output", "delivery_mode"})
+
 	OutputSucceeded = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
 		Subsystem: subsystemOutput,
@@ -53,4 +196,16 @@ var (
 		Name:      "failed_total",
 		Help:      "Total number of failed sends per output.",
 	}, []string{"output", "delivery_mode"})
+
+	CertificateExpiry = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "certificate_expiry_timestamp_seconds",
+		Help:      "Unix timestamp of the expiry of the configured TLS certificates per certificate and output. For bundles, the earliest expiry is reported.",
+	}, []string{"certificate", "output"})
+
+	RevocationChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revocation_checks_total",
+		Help:      "Total number of revocation checks of TLS certificates per certificate, output and result.",
+	}, []string{"certificate", "output", "result"})
 )
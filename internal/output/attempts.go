@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"context"
+)
+
+type attemptsKey struct{}
+
+// WithAttemptCounter returns a copy of ctx in which the attempts of an [Output] to send data are counted,
+// and a function returning the number of attempts recorded so far.
+// The counter is not synchronized, it must only be used for a single Send call.
+func WithAttemptCounter(ctx context.Context) (context.Context, func() int) {
+	attempts := new(int)
+	return context.WithValue(ctx, attemptsKey{}, attempts), func() int { return *attempts }
+}
+
+// RecordAttempt records an attempt to send data in the counter of ctx, if any.
+func RecordAttempt(ctx context.Context) {
+	if attempts, ok := ctx.Value(attemptsKey{}).(*int); ok {
+		*attempts++
+	}
+}
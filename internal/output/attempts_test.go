@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Attempts", func() {
+	It("should count the recorded attempts", func() {
+		ctx, attempts := WithAttemptCounter(context.Background())
+		Expect(attempts()).To(Equal(0))
+
+		RecordAttempt(ctx)
+		RecordAttempt(ctx)
+		Expect(attempts()).To(Equal(2))
+	})
+
+	It("should ignore attempts without a counter", func() {
+		Expect(func() { RecordAttempt(context.Background()) }).NotTo(Panic())
+	})
+})
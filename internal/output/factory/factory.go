@@ -8,24 +8,44 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/gardener/auditlog-forwarder/internal/output"
 	"github.com/gardener/auditlog-forwarder/internal/output/http"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
 
+// OutputProcessors returns the processors which process the events before they are sent to the output with the given configuration.
+type OutputProcessors func(config *configv1alpha1.Output) []processor.Processor
+
 // NewHTTPOutputsWithOptions filters outputs by delivery mode and creates HTTP outputs with the given options.
 // It extracts only HTTP outputs matching the specified delivery mode and configures them with the provided options.
 func NewHTTPOutputsWithOptions(ctx context.Context, allOutputs []configv1alpha1.Output, deliveryMode configv1alpha1.DeliveryMode, httpOpts ...http.Option) ([]output.Output, error) {
+	return NewHTTPOutputsWithProcessors(ctx, allOutputs, deliveryMode, nil, httpOpts...)
+}
+
+// NewHTTPOutputsWithProcessors is like NewHTTPOutputsWithOptions, but additionally wraps each output with
+// the output specific processors returned by processors, which may be nil.
+func NewHTTPOutputsWithProcessors(ctx context.Context, allOutputs []configv1alpha1.Output, deliveryMode configv1alpha1.DeliveryMode, processors OutputProcessors, httpOpts ...http.Option) ([]output.Output, error) {
 	var outputs []output.Output
 	for _, outputConfig := range allOutputs {
 		if outputConfig.HTTP != nil && outputConfig.DeliveryMode == deliveryMode {
-			httpOutput, err := http.New(ctx, outputConfig.HTTP, httpOpts...)
+			opts := append(slices.Clone(httpOpts),
+				http.WithSchema(outputConfig.Schema),
+				http.WithFormat(outputConfig.Format),
+				http.WithEncryption(outputConfig.Encryption),
+			)
+			httpOutput, err := http.New(ctx, outputConfig.HTTP, opts...)
 			if err != nil {
 				// Preserve the primary cause but surface any secondary damage from
 				// closing outputs we already built.
 				return nil, errors.Join(fmt.Errorf("failed to create HTTP output: %w", err), closeOutputs(outputs))
 			}
+			if processors != nil {
+				outputs = append(outputs, output.WithProcessors(httpOutput, processors(&outputConfig)...))
+				continue
+			}
 			outputs = append(outputs, httpOutput)
 		}
 	}
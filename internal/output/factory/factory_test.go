@@ -5,6 +5,7 @@
 package factory_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
@@ -13,7 +14,9 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"io"
 	"math/big"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
@@ -22,10 +25,12 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 
 	"github.com/gardener/auditlog-forwarder/internal/output"
 	"github.com/gardener/auditlog-forwarder/internal/output/factory"
 	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
 
@@ -209,6 +214,40 @@ var _ = Describe("Output Factory", func() {
 			Expect(result[1].Name()).To(Equal(testServer.URL + "/output-2"))
 		})
 
+		It("should wrap outputs with their output specific processors", func() {
+			var received []byte
+			testServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received, _ = io.ReadAll(r.Body)
+			})
+			outputs := []configv1alpha1.Output{
+				{
+					DeliveryMode: configv1alpha1.DeliveryModeGuaranteed,
+					Pseudonymize: true,
+					HTTP: &configv1alpha1.OutputHTTP{
+						URL: testServer.URL,
+					},
+				},
+			}
+
+			var configs []*configv1alpha1.Output
+			result, err := factory.NewHTTPOutputsWithProcessors(
+				context.Background(),
+				outputs,
+				configv1alpha1.DeliveryModeGuaranteed,
+				func(config *configv1alpha1.Output) []processor.Processor {
+					configs = append(configs, config)
+					return []processor.Processor{upperProcessor{}}
+				},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(1))
+			Expect(result[0].Name()).To(Equal(testServer.URL))
+			Expect(configs).To(ConsistOf(PointTo(HaveField("Pseudonymize", BeTrue()))))
+
+			Expect(result[0].Send(context.Background(), []byte("events"))).To(Succeed())
+			Expect(string(received)).To(Equal("EVENTS"))
+		})
+
 		It("should return error when HTTP output creation fails", func() {
 			outputs := []configv1alpha1.Output{
 				{
@@ -380,3 +419,11 @@ func (f *fakeOutput) Close() error {
 	f.closed = true
 	return f.closeErr
 }
+
+type upperProcessor struct{}
+
+func (upperProcessor) Process(_ context.Context, data []byte) ([]byte, error) {
+	return bytes.ToUpper(data), nil
+}
+
+func (upperProcessor) Name() string { return "upper" }
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// authenticator adds credentials to requests of an HTTP output.
+type authenticator interface {
+	// authenticate sets the credentials on the given request header.
+	authenticate(header http.Header) error
+	// reload re-reads the credential files. On failure, the previous credentials are kept.
+	reload() error
+	// files returns the credential files which are watched for changes.
+	files() []string
+}
+
+// newAuthenticator creates the authenticator for the given configuration and loads its credentials.
+// newTokenClient creates the client for requests to the OAuth2 token endpoint.
+func newAuthenticator(config *configv1alpha1.OutputHTTPAuth, newTokenClient func() (*http.Client, error)) (authenticator, error) {
+	var a authenticator
+	switch {
+	case config.BearerTokenFile != "":
+		a = &bearerTokenAuth{file: config.BearerTokenFile}
+	case config.Basic != nil:
+		a = &basicAuth{usernameFile: config.Basic.UsernameFile, passwordFile: config.Basic.PasswordFile}
+	case config.OAuth2 != nil:
+		a = &oauth2Auth{config: config.OAuth2, newClient: newTokenClient}
+	default:
+		return nil, errors.New("no authentication method configured")
+	}
+
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// bearerTokenAuth authenticates requests with a bearer token read from a file.
+type bearerTokenAuth struct {
+	file  string
+	token atomic.Pointer[string]
+}
+
+func (b *bearerTokenAuth) authenticate(header http.Header) error {
+	header.Set("Authorization", "Bearer "+*b.token.Load())
+	return nil
+}
+
+func (b *bearerTokenAuth) reload() error {
+	token, err := readCredentialFile(b.file)
+	if err != nil {
+		return fmt.Errorf("failed to read bearer token: %w", err)
+	}
+	b.token.Store(&token)
+	return nil
+}
+
+func (b *bearerTokenAuth) files() []string {
+	return []string{b.file}
+}
+
+// basicAuth authenticates requests with a username and password read from files.
+type basicAuth struct {
+	usernameFile string
+	passwordFile string
+	// header is the precomputed value of the Authorization header.
+	header atomic.Pointer[string]
+}
+
+func (b *basicAuth) authenticate(header http.Header) error {
+	header.Set("Authorization", *b.header.Load())
+	return nil
+}
+
+func (b *basicAuth) reload() error {
+	username, err := readCredentialFile(b.usernameFile)
+	if err != nil {
+		return fmt.Errorf("failed to read basic auth username: %w", err)
+	}
+	password, err := readCredentialFile(b.passwordFile)
+	if err != nil {
+		return fmt.Errorf("failed to read basic auth password: %w", err)
+	}
+	value := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	b.header.Store(&value)
+	return nil
+}
+
+func (b *basicAuth) files() []string {
+	return []string{b.usernameFile, b.passwordFile}
+}
+
+// oauth2Auth authenticates requests with access tokens obtained via the OAuth2 client credentials flow.
+// Tokens are cached by the token source and refreshed shortly before they expire.
+type oauth2Auth struct {
+	config      *configv1alpha1.OAuth2ClientCredentials
+	newClient   func() (*http.Client, error)
+	tokenSource atomic.Pointer[oauth2.TokenSource]
+}
+
+func (o *oauth2Auth) authenticate(header http.Header) error {
+	token, err := (*o.tokenSource.Load()).Token()
+	if err != nil {
+		return fmt.Errorf("failed to obtain OAuth2 token: %w", err)
+	}
+	header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	return nil
+}
+
+// reload re-reads the client secret and replaces the token source, which drops the cached token.
+// The client for the token endpoint is recreated as well, so that it uses the current TLS credentials.
+func (o *oauth2Auth) reload() error {
+	clientSecret, err := readCredentialFile(o.config.ClientSecretFile)
+	if err != nil {
+		return fmt.Errorf("failed to read OAuth2 client secret: %w", err)
+	}
+	client, err := o.newClient()
+	if err != nil {
+		return fmt.Errorf("failed to create OAuth2 token client: %w", err)
+	}
+
+	endpointParams := url.Values{}
+	for key, value := range o.config.EndpointParams {
+		endpointParams.Set(key, value)
+	}
+
+	cc := &clientcredentials.Config{
+		ClientID:       o.config.ClientID,
+		ClientSecret:   clientSecret,
+		TokenURL:       o.config.TokenURL,
+		Scopes:         o.config.Scopes,
+		EndpointParams: endpointParams,
+	}
+
+	// The token source outlives single requests, hence it must not be bound to a request context.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+	tokenSource := cc.TokenSource(ctx)
+	o.tokenSource.Store(&tokenSource)
+	return nil
+}
+
+func (o *oauth2Auth) files() []string {
+	return []string{o.config.ClientSecretFile}
+}
+
+// createTokenClient creates the client for requests to the OAuth2 token endpoint with the TLS and proxy configuration
+// of the output. The configured TLS server name only applies to the output, the token endpoint is verified by its host.
+func createTokenClient(config *configv1alpha1.OutputHTTP, logger logr.Logger) (*http.Client, error) {
+	tokenConfig := config.DeepCopy()
+	tokenConfig.URL = config.Auth.OAuth2.TokenURL
+	if tokenConfig.TLS != nil {
+		tokenConfig.TLS.ServerName = ""
+	}
+	client, _, err := createHTTPClient(tokenConfig, "", logger)
+	return client, err
+}
+
+// readCredentialFile reads a credential from a file, trimming surrounding whitespace.
+func readCredentialFile(file string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return "", err
+	}
+
+	credential := strings.TrimSpace(string(data))
+	if credential == "" {
+		return "", fmt.Errorf("file %s is empty", file)
+	}
+	return credential, nil
+}
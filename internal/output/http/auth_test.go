@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Headers and Authentication", func() {
+	var (
+		tmpDir     string
+		ctx        context.Context
+		cancel     context.CancelFunc
+		testServer *httptest.Server
+		received   atomic.Pointer[http.Header]
+		httpOutput *httpoutput.Output
+	)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		tmpDir = GinkgoT().TempDir()
+		ctx, cancel = context.WithCancel(context.Background())
+		received.Store(nil)
+
+		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Clone()
+			received.Store(&header)
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		cancel()
+		if httpOutput != nil {
+			Expect(httpOutput.Close()).To(Succeed())
+		}
+		testServer.Close()
+	})
+
+	It("should add the configured headers to requests", func() {
+		var err error
+		httpOutput, err = httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL:     testServer.URL,
+			Headers: map[string]string{"X-Tenant": "shoot--foo--bar", "X-Source": "auditlog-forwarder"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(received.Load().Get("X-Tenant")).To(Equal("shoot--foo--bar"))
+		Expect(received.Load().Get("X-Source")).To(Equal("auditlog-forwarder"))
+		Expect(received.Load().Get("Content-Type")).To(Equal("application/json"))
+	})
+
+	It("should authenticate with a bearer token and pick up token rotation", func() {
+		tokenFile := writeFile("token", "token-1\n")
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL:  testServer.URL,
+			Auth: &configv1alpha1.OutputHTTPAuth{BearerTokenFile: tokenFile},
+		}, httpoutput.WithTLSReloadDebounce(50*time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(received.Load().Get("Authorization")).To(Equal("Bearer token-1"))
+
+		writeFile("token", "token-2")
+		Eventually(func() string {
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+			return received.Load().Get("Authorization")
+		}).WithTimeout(5 * time.Second).WithPolling(100 * time.Millisecond).Should(Equal("Bearer token-2"))
+	})
+
+	It("should keep the previous token when the token file becomes empty", func() {
+		tokenFile := writeFile("token", "token-1")
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL:  testServer.URL,
+			Auth: &configv1alpha1.OutputHTTPAuth{BearerTokenFile: tokenFile},
+		}, httpoutput.WithTLSReloadDebounce(0))
+		Expect(err).NotTo(HaveOccurred())
+
+		writeFile("token", "")
+		Consistently(func() string {
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+			return received.Load().Get("Authorization")
+		}).WithTimeout(300 * time.Millisecond).WithPolling(50 * time.Millisecond).Should(Equal("Bearer token-1"))
+	})
+
+	It("should fail to create the output when the token file does not exist", func() {
+		out, err := httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL:  testServer.URL,
+			Auth: &configv1alpha1.OutputHTTPAuth{BearerTokenFile: filepath.Join(tmpDir, "missing")},
+		})
+		Expect(err).To(MatchError(ContainSubstring("failed to set up authentication")))
+		Expect(out).To(BeNil())
+	})
+
+	It("should authenticate with basic auth", func() {
+		var err error
+		httpOutput, err = httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL: testServer.URL,
+			Auth: &configv1alpha1.OutputHTTPAuth{Basic: &configv1alpha1.BasicAuth{
+				UsernameFile: writeFile("username", "forwarder"),
+				PasswordFile: writeFile("password", "s3cr3t"),
+			}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		req := &http.Request{Header: *received.Load()}
+		username, password, ok := req.BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("forwarder"))
+		Expect(password).To(Equal("s3cr3t"))
+	})
+
+	It("should authenticate with an OAuth2 token obtained via client credentials and cache it", func() {
+		var tokenRequests atomic.Int32
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			tokenRequests.Add(1)
+
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.PostForm.Get("grant_type")).To(Equal("client_credentials"))
+			Expect(r.PostForm.Get("scope")).To(Equal("audit.write"))
+			Expect(r.PostForm.Get("audience")).To(Equal("audit-sink"))
+			clientID, clientSecret, ok := r.BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(clientID).To(Equal("forwarder"))
+			Expect(clientSecret).To(Equal("s3cr3t"))
+
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "access-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})).To(Succeed())
+		}))
+		defer tokenServer.Close()
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL: testServer.URL,
+			Auth: &configv1alpha1.OutputHTTPAuth{OAuth2: &configv1alpha1.OAuth2ClientCredentials{
+				TokenURL:         tokenServer.URL,
+				ClientID:         "forwarder",
+				ClientSecretFile: writeFile("client-secret", "s3cr3t"),
+				Scopes:           []string{"audit.write"},
+				EndpointParams:   map[string]string{"audience": "audit-sink"},
+			}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		for range 3 {
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		}
+		Expect(received.Load().Get("Authorization")).To(Equal("Bearer access-token"))
+		Expect(tokenRequests.Load()).To(Equal(int32(1)))
+	})
+
+	It("should obtain the OAuth2 token with the TLS configuration of the output", func() {
+		caKey, caCert, caPEM := generateCA("test-ca")
+		clientCertPEM, clientKeyPEM := generateClientCert(caKey, caCert, "forwarder")
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(caCert)
+		newTLSServer := func(handler http.Handler) *httptest.Server {
+			server := httptest.NewUnstartedServer(handler)
+			server.TLS = &tls.Config{
+				Certificates: []tls.Certificate{generateServerCert(caKey, caCert, "127.0.0.1")},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+			}
+			server.StartTLS()
+			return server
+		}
+
+		var tokenClients atomic.Pointer[string]
+		tokenServer := newTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			clientCN := r.TLS.PeerCertificates[0].Subject.CommonName
+			tokenClients.Store(&clientCN)
+
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "access-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})).To(Succeed())
+		}))
+		defer tokenServer.Close()
+		outputServer := newTLSServer(testServer.Config.Handler)
+		defer outputServer.Close()
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL: outputServer.URL,
+			TLS: &configv1alpha1.ClientTLS{
+				CAFile:   writeFile("ca.crt", string(caPEM)),
+				CertFile: writeFile("tls.crt", string(clientCertPEM)),
+				KeyFile:  writeFile("tls.key", string(clientKeyPEM)),
+			},
+			Auth: &configv1alpha1.OutputHTTPAuth{OAuth2: &configv1alpha1.OAuth2ClientCredentials{
+				TokenURL:         tokenServer.URL,
+				ClientID:         "forwarder",
+				ClientSecretFile: writeFile("client-secret", "s3cr3t"),
+			}},
+		}, httpoutput.WithMaxSendAttempts(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(received.Load().Get("Authorization")).To(Equal("Bearer access-token"))
+		Expect(*tokenClients.Load()).To(Equal("forwarder"))
+	})
+
+	It("should fail to send when no OAuth2 token can be obtained", func() {
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer tokenServer.Close()
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, &configv1alpha1.OutputHTTP{
+			URL: testServer.URL,
+			Auth: &configv1alpha1.OutputHTTPAuth{OAuth2: &configv1alpha1.OAuth2ClientCredentials{
+				TokenURL:         tokenServer.URL,
+				ClientID:         "forwarder",
+				ClientSecretFile: writeFile("client-secret", "s3cr3t"),
+			}},
+		}, httpoutput.WithMaxSendAttempts(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(MatchError(ContainSubstring("failed to obtain OAuth2 token")))
+		Expect(received.Load()).To(BeNil())
+	})
+})
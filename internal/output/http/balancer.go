@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// endpoint is a single target of an HTTP output together with its passive health state.
+// All fields except url are guarded by the mutex of the owning balancer.
+type endpoint struct {
+	url string
+
+	outstanding         int
+	consecutiveFailures int
+	ejectedUntil        time.Time
+}
+
+// balancer selects the endpoint for each request attempt of an HTTP output and tracks
+// the passive health of the endpoints based on the outcome of the attempts.
+type balancer struct {
+	policy           configv1alpha1.LoadBalancingPolicy
+	failureThreshold int
+	ejectionCooldown time.Duration
+	now              func() time.Time
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	// next is the round-robin position; it is also used to spread ties of the least-outstanding policy.
+	next int
+}
+
+// newBalancer creates a balancer for the given endpoint URLs.
+// A failureThreshold of 0 disables ejection.
+func newBalancer(urls []string, policy configv1alpha1.LoadBalancingPolicy, failureThreshold int, ejectionCooldown time.Duration) *balancer {
+	b := &balancer{
+		policy:           policy,
+		failureThreshold: failureThreshold,
+		ejectionCooldown: ejectionCooldown,
+		now:              time.Now,
+	}
+	b.setEndpoints(urls)
+	return b
+}
+
+// setEndpoints replaces the set of endpoints. The health state of endpoints that
+// are part of both the old and the new set is preserved.
+// It reports whether the set of endpoint URLs changed.
+func (b *balancer) setEndpoints(urls []string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if slices.EqualFunc(b.endpoints, urls, func(e *endpoint, u string) bool { return e.url == u }) {
+		return false
+	}
+
+	existing := make(map[string]*endpoint, len(b.endpoints))
+	for _, e := range b.endpoints {
+		existing[e.url] = e
+	}
+
+	endpoints := make([]*endpoint, 0, len(urls))
+	for _, u := range urls {
+		if e, ok := existing[u]; ok {
+			endpoints = append(endpoints, e)
+			continue
+		}
+		endpoints = append(endpoints, &endpoint{url: u})
+	}
+	b.endpoints = endpoints
+	return true
+}
+
+// urls returns the URLs of the current endpoints.
+func (b *balancer) urls() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	urls := make([]string, 0, len(b.endpoints))
+	for _, e := range b.endpoints {
+		urls = append(urls, e.url)
+	}
+	return urls
+}
+
+// pick selects the endpoint for the next request attempt and marks a request as outstanding on it.
+// Healthy endpoints that were not yet tried are preferred, so that retries go to a different endpoint.
+// If all endpoints are ejected, ejection is ignored rather than failing the request.
+// Every call must be followed by a call to done for the returned endpoint.
+func (b *balancer) pick(tried map[*endpoint]struct{}) *endpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	healthy := func(e *endpoint) bool { return !now.Before(e.ejectedUntil) }
+	untried := func(e *endpoint) bool { _, ok := tried[e]; return !ok }
+
+	candidates := b.filter(func(e *endpoint) bool { return healthy(e) && untried(e) })
+	if len(candidates) == 0 {
+		candidates = b.filter(healthy)
+	}
+	if len(candidates) == 0 {
+		candidates = b.filter(untried)
+	}
+	if len(candidates) == 0 {
+		candidates = b.endpoints
+	}
+
+	offset := b.next % len(candidates)
+	b.next++
+
+	selected := candidates[offset]
+	if b.policy == configv1alpha1.LoadBalancingPolicyLeastOutstandingRequests {
+		for i := range candidates {
+			if c := candidates[(offset+i)%len(candidates)]; c.outstanding < selected.outstanding {
+				selected = c
+			}
+		}
+	}
+
+	selected.outstanding++
+	return selected
+}
+
+// done records the outcome of a request attempt to the given endpoint.
+// It reports whether the endpoint got ejected because of this attempt.
+func (b *balancer) done(e *endpoint, success bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e.outstanding--
+	if success {
+		e.consecutiveFailures = 0
+		return false
+	}
+
+	e.consecutiveFailures++
+	if b.failureThreshold == 0 || e.consecutiveFailures < b.failureThreshold {
+		return false
+	}
+
+	e.consecutiveFailures = 0
+	e.ejectedUntil = b.now().Add(b.ejectionCooldown)
+	return true
+}
+
+func (b *balancer) filter(keep func(*endpoint) bool) []*endpoint {
+	var endpoints []*endpoint
+	for _, e := range b.endpoints {
+		if keep(e) {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
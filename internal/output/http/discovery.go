@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// dnsLookupTimeout bounds a single resolution of the endpoints of an HTTP output.
+const dnsLookupTimeout = 5 * time.Second
+
+// Resolver resolves DNS records for the discovery of endpoints.
+// [*net.Resolver] implements this interface.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// discovery resolves the endpoints of an HTTP output from the DNS records of the host of its URL.
+type discovery struct {
+	resolver        Resolver
+	baseURL         *url.URL
+	recordType      configv1alpha1.DNSRecordType
+	service         string
+	refreshInterval time.Duration
+}
+
+func newDiscovery(rawURL string, config *configv1alpha1.DNSDiscovery, resolver Resolver) (*discovery, error) {
+	baseURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	return &discovery{
+		resolver:        resolver,
+		baseURL:         baseURL,
+		recordType:      config.RecordType,
+		service:         config.Service,
+		refreshInterval: config.RefreshInterval.Duration,
+	}, nil
+}
+
+// resolve returns the sorted endpoint URLs for the currently published DNS records.
+// The endpoint URLs keep the scheme and path of the base URL; only the host and port are replaced.
+func (d *discovery) resolve(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+	defer cancel()
+
+	var hostPorts []string
+	switch d.recordType {
+	case configv1alpha1.DNSRecordTypeSRV:
+		_, records, err := d.resolver.LookupSRV(ctx, d.service, "tcp", d.baseURL.Hostname())
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up SRV records: %w", err)
+		}
+		for _, record := range records {
+			hostPorts = append(hostPorts, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+		}
+	default:
+		addrs, err := d.resolver.LookupHost(ctx, d.baseURL.Hostname())
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up host: %w", err)
+		}
+		port := d.baseURL.Port()
+		if port == "" {
+			port = defaultPort(d.baseURL.Scheme)
+		}
+		for _, addr := range addrs {
+			hostPorts = append(hostPorts, net.JoinHostPort(addr, port))
+		}
+	}
+
+	if len(hostPorts) == 0 {
+		return nil, fmt.Errorf("no %s records found for %s", d.recordType, d.baseURL.Hostname())
+	}
+
+	urls := make([]string, 0, len(hostPorts))
+	for _, hostPort := range hostPorts {
+		u := *d.baseURL
+		u.Host = hostPort
+		urls = append(urls, u.String())
+	}
+	slices.Sort(urls)
+	return slices.Compact(urls), nil
+}
+
+// runDiscovery periodically resolves the endpoints and updates the balancer until ctx is done or the output is closed.
+// Failed resolutions are logged and the previously known endpoints are kept.
+func (o *Output) runDiscovery(ctx context.Context) {
+	ticker := time.NewTicker(o.discovery.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.closed:
+			return
+		case <-ticker.C:
+			o.refreshEndpoints(ctx)
+		}
+	}
+}
+
+// refreshEndpoints resolves the endpoints once and updates the balancer.
+func (o *Output) refreshEndpoints(ctx context.Context) {
+	urls, err := o.discovery.resolve(ctx)
+	if err != nil {
+		o.logger.Error(err, "Failed to discover endpoints, keeping previous endpoints", "url", o.url)
+		return
+	}
+
+	if o.balancer.setEndpoints(urls) {
+		o.logger.Info("Discovered endpoints changed", "url", o.url, "endpoints", urls)
+	}
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "http" {
+		return "80"
+	}
+	return "443"
+}
@@ -8,3 +8,5 @@ var (
 	BackoffFunc = &backoffFunc
 	SleepFunc   = &sleepFunc
 )
+
+var NewProxyFunc = newProxyFunc
@@ -6,38 +6,46 @@ package http
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	loggerctx "github.com/gardener/auditlog-forwarder/internal/context"
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+	"github.com/gardener/auditlog-forwarder/internal/encryption"
+	"github.com/gardener/auditlog-forwarder/internal/filewatch"
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
 	"github.com/gardener/auditlog-forwarder/internal/output"
+	"github.com/gardener/auditlog-forwarder/internal/revocation"
+	"github.com/gardener/auditlog-forwarder/internal/tracing"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+	confighelper "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1/helper"
 )
 
 const (
-	headerContentType = "Content-Type"
-	mimeAppJSON       = "application/json"
-
+	headerContentType     = "Content-Type"
 	headerContentEncoding = "Content-Encoding"
-	contentEncodingGzip   = "gzip"
-
-	// defaultTLSReloadDebounce is the default delay after a filesystem event before reloading TLS credentials.
-	// Kubernetes secret updates produce multiple events in rapid succession; this coalesces them.
-	defaultTLSReloadDebounce = 500 * time.Millisecond
 )
 
 var _ output.Output = (*Output)(nil)
@@ -55,48 +63,69 @@ var (
 type Output struct {
 	url    string
 	client atomic.Pointer[http.Client]
-	// compression algorithm to use (currently only "gzip" or empty for none)
-	compression string
+	// balancer selects the endpoint for each request attempt.
+	balancer *balancer
+	// discovery resolves the endpoints via DNS (nil if DNS discovery is not configured).
+	discovery *discovery
+	// resolver is used by discovery to resolve DNS records.
+	resolver Resolver
+	// hostHeader overrides the Host header of requests whose endpoint was discovered via DNS.
+	hostHeader string
+	// tlsServerName overrides the server name used to verify the server certificate of discovered endpoints.
+	tlsServerName string
+	// headers are static headers added to every request.
+	headers map[string]string
+	// auth adds credentials to every request (nil if authentication is not configured).
+	auth authenticator
+	// compression is the compression of the request body (empty algorithm for none)
+	compression encoding.Compression
+	// schema is the schema the audit events are transformed into before compression
+	schema configv1alpha1.Schema
+	// format is the framing of the audit events into request bodies
+	format configv1alpha1.Format
+	// encryptionConfig is the configuration of the encryption of the request bodies (nil for none)
+	encryptionConfig *configv1alpha1.Encryption
+	// encrypter encrypts the request bodies after compression (nil if encryption is not configured)
+	encrypter *encryption.Encrypter
 
 	maxSendAttempts int
 	baseBackoff     time.Duration
 	maxBackoff      time.Duration
 
-	// tlsReloadDebounce is the delay before reloading TLS credentials after a filesystem event
+	// tlsReloadDebounce is the delay before reloading TLS and authentication credentials after a filesystem event
 	tlsReloadDebounce time.Duration
-	// logger is used by background operations of the HTTP output (currently the TLS file watcher).
+	// logger is used by background operations of the HTTP output (the credential file watcher and endpoint discovery).
 	logger logr.Logger
-	// watcher is the fsnotify watcher for TLS credential files (nil if TLS is not configured).
-	// Once assigned in startTLSWatcher it is never reassigned; closeOnce guards shutdown.
-	watcher *fsnotify.Watcher
+	// watcher reloads the TLS and authentication credential files when they change.
+	// Once assigned in startCredentialWatcher it is never reassigned; closeOnce guards shutdown.
+	watcher *filewatch.Watcher
 	// closeOnce ensures Close is idempotent and runs the shutdown sequence exactly once.
 	closeOnce sync.Once
-	// wg tracks the watchTLSFiles goroutine so Close can wait for it to exit.
+	// closed is closed by Close to stop background goroutines that are not bound to the watcher.
+	closed chan struct{}
+	// wg tracks the runDiscovery goroutine so Close can wait for it to exit.
 	wg sync.WaitGroup
 }
 
 // New creates a new HTTP output with the given configuration.
-// The context controls the lifetime of the TLS credential file watcher.
+// The context controls the lifetime of the credential file watcher.
 func New(ctx context.Context, config *configv1alpha1.OutputHTTP, options ...Option) (*Output, error) {
 	if config == nil {
 		return nil, fmt.Errorf("HTTP output configuration is nil")
 	}
 
-	client, err := createHTTPClient(config.TLS)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
-	}
-
 	o := &Output{
 		url:               config.URL,
-		compression:       config.Compression,
+		headers:           config.Headers,
+		resolver:          net.DefaultResolver,
+		compression:       encoding.Compression{Algorithm: strings.TrimSpace(config.Compression)},
 		maxSendAttempts:   4,
 		baseBackoff:       500 * time.Millisecond,
 		maxBackoff:        3 * time.Second,
-		tlsReloadDebounce: defaultTLSReloadDebounce,
+		tlsReloadDebounce: filewatch.DefaultDebounce,
 		logger:            logr.Discard(),
+		closed:            make(chan struct{}),
 	}
-	o.client.Store(client)
 
 	for _, opt := range options {
 		if err := opt(o); err != nil {
@@ -104,10 +133,48 @@ func New(ctx context.Context, config *configv1alpha1.OutputHTTP, options ...Opti
 		}
 	}
 
-	if config.TLS != nil {
-		if err := o.startTLSWatcher(ctx, config.TLS); err != nil {
-			return nil, fmt.Errorf("failed to start TLS file watcher: %w", err)
+	if err := o.setupLoadBalancing(config); err != nil {
+		return nil, fmt.Errorf("failed to set up load balancing: %w", err)
+	}
+
+	client, expiry, err := createHTTPClient(config, o.tlsServerName, o.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	o.client.Store(client)
+	o.observeCertificateExpiry(expiry)
+
+	if config.CompressionLevel != nil {
+		o.compression.Level = int(*config.CompressionLevel)
+	}
+
+	if o.encryptionConfig != nil {
+		encrypter, err := encryption.NewEncrypter(o.encryptionConfig.RecipientPublicKeyFiles, o.encoding().ContentType(), o.compression.Algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up encryption: %w", err)
 		}
+		o.encrypter = encrypter
+	}
+
+	if config.Auth != nil {
+		auth, err := newAuthenticator(config.Auth, func() (*http.Client, error) {
+			return createTokenClient(config, o.logger)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up authentication: %w", err)
+		}
+		o.auth = auth
+	}
+
+	if o.discovery != nil {
+		o.refreshEndpoints(ctx)
+		o.wg.Go(func() {
+			o.runDiscovery(ctx)
+		})
+	}
+
+	if err := o.startCredentialWatcher(ctx, config); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to start credential file watcher: %w", err), o.Close())
 	}
 
 	return o, nil
@@ -117,55 +184,43 @@ func New(ctx context.Context, config *configv1alpha1.OutputHTTP, options ...Opti
 func (o *Output) Send(ctx context.Context, data []byte) error {
 	logger := loggerctx.LoggerFromContext(ctx).WithName("http").WithValues("url", o.url)
 
-	payload := data
-	if o.compression == contentEncodingGzip {
-		var buf bytes.Buffer
-		gz := gzip.NewWriter(&buf)
-		if _, err := gz.Write(data); err != nil {
-			// call gz.Close for the sake of completeness
-			// ignore the error as this would probably be the same error as the error returned by gz.Write
-			_ = gz.Close()
-			return fmt.Errorf("failed to gzip data: %w", err)
+	// The encoded bodies are shared with other outputs using the same encoding.
+	bodies, err := encoding.PayloadFromContext(ctx, data).Bodies(o.encoding())
+	if err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	// Bodies are sent one after another to keep the order of the events.
+	for _, body := range bodies {
+		// Bodies are encrypted once, so that retries send the same ciphertext.
+		if o.encrypter != nil {
+			if body, err = o.encrypter.Encrypt(body); err != nil {
+				return err
+			}
 		}
-		// explicitly close the writer in order to make it flush residual data and write the gzip footer
-		// we do not use defer here because we want to write all data to the buffer before passing it to the http request
-		if err := gz.Close(); err != nil { // flush
-			return fmt.Errorf("failed to finalize gzip writer: %w", err)
+		if err := o.sendBody(ctx, body, logger); err != nil {
+			return err
 		}
-		payload = buf.Bytes()
 	}
+	return nil
+}
 
+// sendBody sends a single request body, retrying failed attempts with backoff.
+func (o *Output) sendBody(ctx context.Context, payload []byte, logger logr.Logger) error {
 	var lastErr error
+	tried := make(map[*endpoint]struct{}, o.maxSendAttempts)
 	for attempt := 1; attempt <= o.maxSendAttempts; attempt++ {
-		bodyReader := bytes.NewReader(payload)
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bodyReader)
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+		lastErr = o.sendAttempt(ctx, attempt, tried, payload, logger)
+		if lastErr == nil {
+			return nil
 		}
 
-		req.Header.Set(headerContentType, mimeAppJSON)
-		if o.compression == contentEncodingGzip {
-			req.Header.Set(headerContentEncoding, contentEncodingGzip)
+		var statusErr *statusError
+		if errors.As(lastErr, &statusErr) && !isRetryableStatus(statusErr.statusCode) {
+			return lastErr
 		}
-
-		resp, err := o.client.Load().Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to send request: %w", err)
-		} else {
-			body, readErr := readAndCloseBody(resp, logger)
-			if readErr != nil {
-				return readErr
-			}
-
-			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-				return nil
-			}
-
-			reqErr := fmt.Errorf("output returned status %d: %s", resp.StatusCode, string(body))
-			if !isRetryableStatus(resp.StatusCode) {
-				return reqErr
-			}
-			lastErr = reqErr
+		if errors.Is(lastErr, errReadResponse) {
+			return lastErr
 		}
 
 		if attempt < o.maxSendAttempts {
@@ -178,136 +233,160 @@ func (o *Output) Send(ctx context.Context, data []byte) error {
 	return lastErr
 }
 
-// Name returns the URL of this HTTP output.
-func (o *Output) Name() string {
-	return o.url
+// sendAttempt performs a single attempt to send the payload to one of the endpoints and traces it in a client span.
+func (o *Output) sendAttempt(ctx context.Context, attempt int, tried map[*endpoint]struct{}, payload []byte, logger logr.Logger) error {
+	output.RecordAttempt(ctx)
+	ctx, span := tracing.Tracer().Start(ctx, http.MethodPost,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(tracing.AttributeOutput.String(o.Name()), tracing.AttributeAttempt.Int(attempt)),
+	)
+	defer span.End()
+
+	// The header is built per attempt, so that a token that expired during backoff is refreshed.
+	header, err := o.requestHeader()
+	if err == nil {
+		ep := o.balancer.pick(tried)
+		tried[ep] = struct{}{}
+		err = o.sendToEndpoint(ctx, ep, header, payload, logger)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "attempt failed")
+	}
+	return err
 }
 
-// Close triggers shutdown of the TLS file watcher goroutine and releases resources.
-// It is safe to call multiple times; only the first call performs the shutdown.
-// Close blocks until the background watcher goroutine has exited, so any
-// in-flight TLS reload has completed by the time Close returns.
-func (o *Output) Close() error {
-	var err error
-	o.closeOnce.Do(func() {
-		if o.watcher != nil {
-			err = o.watcher.Close()
+// requestHeader returns the configured static headers and the credentials for a request.
+func (o *Output) requestHeader() (http.Header, error) {
+	header := make(http.Header, len(o.headers)+3)
+	for name, value := range o.headers {
+		header.Set(name, value)
+	}
+	if o.auth != nil {
+		if err := o.auth.authenticate(header); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
 		}
-		o.wg.Wait()
-	})
-	return err
+	}
+	return header, nil
 }
 
-// startTLSWatcher begins watching the directories containing TLS credential files.
-// When files change, the HTTP client is rebuilt with freshly-loaded credentials.
-//
-// If tlsConfig has no file paths set (all of CAFile, CertFile, KeyFile empty),
-// no watcher is created and no goroutine is spawned — there is nothing to
-// watch, so allocating an fsnotify handle and parking a goroutine on empty
-// channels would only waste an FD.
-func (o *Output) startTLSWatcher(ctx context.Context, tlsConfig *configv1alpha1.ClientTLS) error {
-	dirs := tlsDirectories(tlsConfig)
-	if len(dirs) == 0 {
-		return nil
-	}
+// sendToEndpoint performs a single request attempt to the given endpoint and records its outcome in the balancer.
+func (o *Output) sendToEndpoint(ctx context.Context, ep *endpoint, header http.Header, payload []byte, logger logr.Logger) error {
+	healthy := false
+	defer func() {
+		if o.balancer.done(ep, healthy) {
+			logger.Info("Ejected endpoint after consecutive failures", "endpoint", ep.url, "cooldown", o.balancer.ejectionCooldown.String())
+		}
+	}()
 
-	watcher, err := fsnotify.NewWatcher()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url, bytes.NewReader(payload))
 	if err != nil {
-		return fmt.Errorf("failed to create file watcher: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if o.hostHeader != "" {
+		req.Host = o.hostHeader
 	}
 
-	// Watch the parent directories of all configured TLS files.
-	// This handles Kubernetes secret mounts where files are symlinks that get atomically swapped.
-	for _, dir := range dirs {
-		if err := watcher.Add(dir); err != nil {
-			_ = watcher.Close()
-			return fmt.Errorf("failed to watch directory %s: %w", dir, err)
+	req.Header = header
+	// Propagate the trace context of the attempt, so that the receiver can continue the trace.
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if o.encrypter != nil {
+		// The compression is applied before the encryption and recorded in the protected header of the JWE.
+		req.Header.Set(headerContentType, encryption.MediaTypeJWE)
+	} else {
+		req.Header.Set(headerContentType, o.encoding().ContentType())
+		if o.compression.Algorithm != "" {
+			req.Header.Set(headerContentEncoding, o.compression.Algorithm)
 		}
 	}
 
-	o.watcher = watcher
-
-	o.wg.Go(func() {
-		o.watchTLSFiles(ctx, tlsConfig)
-	})
-	return nil
-}
-
-// watchTLSFiles is the event loop for the TLS file watcher.
-func (o *Output) watchTLSFiles(ctx context.Context, tlsConfig *configv1alpha1.ClientTLS) {
-	watcher := o.watcher
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(semconv.URLFull(ep.url))
+	resp, err := o.client.Load().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
-	// debounceTimer is created stopped; debounceC is set to the timer's
-	// channel exactly when the timer is armed and cleared when it fires or is
-	// stopped — this keeps the case a no-op until a reload is actually pending.
-	debounceTimer := time.NewTimer(0)
-	if !debounceTimer.Stop() {
-		<-debounceTimer.C
+	body, err := readAndCloseBody(resp, logger)
+	if err != nil {
+		return err
 	}
-	var debounceC <-chan time.Time
 
-	armDebounce := func() {
-		if debounceC != nil {
-			if !debounceTimer.Stop() {
-				<-debounceTimer.C
-			}
-		}
-		debounceTimer.Reset(o.tlsReloadDebounce)
-		debounceC = debounceTimer.C
+	// Client errors are caused by the request, not by the endpoint.
+	healthy = resp.StatusCode < http.StatusInternalServerError
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
 	}
 
-	// Guarantee the timer is stopped on every exit path so it does not linger
-	// past this goroutine's lifetime.
-	defer func() {
-		if debounceC != nil && !debounceTimer.Stop() {
-			<-debounceTimer.C
-		}
-	}()
+	return &statusError{statusCode: resp.StatusCode, body: body}
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
+// encoding returns the encoding of the request body.
+func (o *Output) encoding() encoding.Encoding {
+	return encoding.Encoding{Schema: o.schema, Format: o.format, Compression: o.compression}
+}
 
-		case <-debounceC:
-			debounceC = nil
-			o.reloadTLSClient(tlsConfig)
+// Name returns the URL of this HTTP output.
+func (o *Output) Name() string {
+	return o.url
+}
 
-		case event, ok := <-watcher.Events:
-			if !ok {
-				return
-			}
-			// Only react to events that indicate file content changed.
-			// Rename is included because Kubernetes secret updates atomically
-			// rename the `..data` symlink into place, which on some platforms
-			// surfaces as a Rename rather than a Create on the target.
-			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) &&
-				!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
-				continue
-			}
+// Close triggers shutdown of the credential file watcher and endpoint discovery goroutines and releases resources.
+// It is safe to call multiple times; only the first call performs the shutdown.
+// Close blocks until the background goroutines have exited, so any
+// in-flight credential reload has completed by the time Close returns.
+func (o *Output) Close() error {
+	var err error
+	o.closeOnce.Do(func() {
+		close(o.closed)
+		if o.watcher != nil {
+			err = o.watcher.Close()
+		}
+		o.wg.Wait()
+	})
+	return err
+}
 
-			// (Re)arm the debounce on each qualifying event. Coalescing many
-			// rapid events into a single reload happens naturally because the
-			// fire time is pushed forward on every reset.
-			armDebounce()
+// startCredentialWatcher begins watching the TLS, authentication and proxy credential files.
+// When files change, the HTTP client is rebuilt and the authentication credentials are re-read.
+func (o *Output) startCredentialWatcher(ctx context.Context, config *configv1alpha1.OutputHTTP) error {
+	var files []string
+	if config.TLS != nil {
+		files = append(files, config.TLS.CAFile, config.TLS.CertFile, config.TLS.KeyFile)
+		if config.TLS.Revocation != nil {
+			files = append(files, config.TLS.Revocation.CRLFiles...)
+		}
+	}
+	if config.Proxy != nil && config.Proxy.BasicAuth != nil {
+		files = append(files, config.Proxy.BasicAuth.UsernameFile, config.Proxy.BasicAuth.PasswordFile)
+	}
+	if o.auth != nil {
+		files = append(files, o.auth.files()...)
+	}
 
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return
-			}
-			o.logger.Error(err, "File watcher error")
+	watcher, err := filewatch.Watch(ctx, o.logger, files, o.tlsReloadDebounce, func() {
+		if config.TLS != nil || config.Proxy != nil {
+			o.reloadClient(config)
 		}
+		o.reloadAuth()
+	})
+	if err != nil {
+		return err
 	}
+	o.watcher = watcher
+	return nil
 }
 
-// reloadTLSClient rebuilds the HTTP client with freshly-loaded TLS credentials.
+// reloadClient rebuilds the HTTP client with freshly-loaded TLS and proxy credentials.
 // On failure, the existing client is kept.
-func (o *Output) reloadTLSClient(tlsConfig *configv1alpha1.ClientTLS) {
-	client, err := createHTTPClient(tlsConfig)
+func (o *Output) reloadClient(config *configv1alpha1.OutputHTTP) {
+	client, expiry, err := createHTTPClient(config, o.tlsServerName, o.logger)
 	if err != nil {
-		o.logger.Error(err, "Failed to reload TLS credentials, keeping existing client")
+		o.logger.Error(err, "Failed to reload client credentials, keeping existing client")
 		return
 	}
+	o.observeCertificateExpiry(expiry)
 
 	old := o.client.Swap(client)
 	if old != nil {
@@ -318,78 +397,179 @@ func (o *Output) reloadTLSClient(tlsConfig *configv1alpha1.ClientTLS) {
 		// TLS session state, defeating the point of the atomic swap.
 		old.CloseIdleConnections()
 	}
-	o.logger.Info("Reloaded TLS credentials")
+	o.logger.Info("Reloaded client credentials")
 }
 
-// tlsDirectories returns the unique parent directories of all configured TLS files.
-func tlsDirectories(tlsConfig *configv1alpha1.ClientTLS) []string {
-	seen := make(map[string]struct{})
-	var dirs []string
-
-	for _, file := range []string{tlsConfig.CAFile, tlsConfig.CertFile, tlsConfig.KeyFile} {
-		if file == "" {
-			continue
-		}
-		dir := filepath.Dir(file)
-		if _, ok := seen[dir]; !ok {
-			seen[dir] = struct{}{}
-			dirs = append(dirs, dir)
-		}
+// reloadAuth re-reads the authentication credentials.
+// On failure, the existing credentials are kept.
+func (o *Output) reloadAuth() {
+	if o.auth == nil {
+		return
 	}
+	if err := o.auth.reload(); err != nil {
+		o.logger.Error(err, "Failed to reload authentication credentials, keeping existing credentials")
+		return
+	}
+	o.logger.Info("Reloaded authentication credentials")
+}
 
-	return dirs
+// certificateExpiry is the expiry of the TLS material of an HTTP client. Zero values mean not configured.
+type certificateExpiry struct {
+	clientCert time.Time
+	ca         time.Time
 }
 
-// createHTTPClient creates an HTTP client with optional TLS configuration.
-func createHTTPClient(tlsConfig *configv1alpha1.ClientTLS) (*http.Client, error) {
+// observeCertificateExpiry exports the expiry of the configured client certificate and CA bundle.
+func (o *Output) observeCertificateExpiry(expiry certificateExpiry) {
+	if !expiry.clientCert.IsZero() {
+		metrics.CertificateExpiry.WithLabelValues(metrics.CertificateOutputClient, o.Name()).Set(float64(expiry.clientCert.Unix()))
+	}
+	if !expiry.ca.IsZero() {
+		metrics.CertificateExpiry.WithLabelValues(metrics.CertificateOutputCA, o.Name()).Set(float64(expiry.ca.Unix()))
+	}
+}
+
+// createHTTPClient creates an HTTP client with optional TLS and proxy configuration.
+// A non-empty serverName overrides the name used to verify the server certificate.
+// It also returns the expiry of the loaded client certificate and CA bundle.
+// The logger is used by the revocation checking of the server certificate.
+func createHTTPClient(config *configv1alpha1.OutputHTTP, serverName string, logger logr.Logger) (*http.Client, certificateExpiry, error) {
 	client := &http.Client{
 		Timeout: 15 * time.Second,
 	}
+	var expiry certificateExpiry
 
-	if tlsConfig == nil {
-		return client, nil
+	tlsConfig := config.TLS
+	if tlsConfig == nil && serverName == "" && config.Proxy == nil {
+		return client, expiry, nil
 	}
 
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			MinVersion: tls.VersionTLS12,
+			ServerName: serverName,
 		},
 	}
+	if tlsConfig != nil {
+		if err := confighelper.ApplyTLSParameters(transport.TLSClientConfig, tlsConfig.TLSParameters); err != nil {
+			return nil, expiry, fmt.Errorf("failed to apply TLS parameters: %w", err)
+		}
+		// A configured server name takes precedence over the host of a URL discovered via DNS.
+		if tlsConfig.ServerName != "" {
+			transport.TLSClientConfig.ServerName = tlsConfig.ServerName
+		}
+		// A custom TLS configuration disables HTTP/2 unless it is explicitly requested.
+		transport.ForceAttemptHTTP2 = slices.Contains(tlsConfig.ALPNProtocols, "h2")
+	}
+
+	if tlsConfig != nil && tlsConfig.Revocation != nil {
+		// The CRLs are re-read whenever the client is recreated on changes of the credential files.
+		checker, err := revocation.New(logger.WithName("revocation"), tlsConfig.Revocation, metrics.CertificateOutputServer, config.URL)
+		if err != nil {
+			return nil, expiry, fmt.Errorf("failed to configure revocation checking: %w", err)
+		}
+		transport.TLSClientConfig.VerifyConnection = checker.VerifyConnection
+	}
+
+	// Without proxy configuration, the proxy environment variables are honoured like by the default transport.
+	proxyConfig := config.Proxy
+	if proxyConfig == nil {
+		proxyConfig = &configv1alpha1.OutputHTTPProxy{FromEnvironment: true}
+	}
+	proxy, err := newProxyFunc(proxyConfig)
+	if err != nil {
+		return nil, expiry, fmt.Errorf("failed to configure proxy: %w", err)
+	}
+	transport.Proxy = proxy
+
+	if tlsConfig == nil {
+		client.Transport = transport
+		return client, expiry, nil
+	}
 
 	if tlsConfig.CAFile != "" {
-		caCertPool, err := loadCACertPool(tlsConfig.CAFile)
+		caCertPool, caExpiry, err := loadCACertPool(tlsConfig.CAFile)
 		if err != nil {
-			return nil, err
+			return nil, expiry, err
 		}
 		transport.TLSClientConfig.RootCAs = caCertPool
+		expiry.ca = caExpiry
 	}
 
 	if tlsConfig.CertFile != "" && tlsConfig.KeyFile != "" {
 		cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			return nil, expiry, fmt.Errorf("failed to load client certificate: %w", err)
 		}
 		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		expiry.clientCert = cert.Leaf.NotAfter
 	}
 
 	client.Transport = transport
-	return client, nil
+	return client, expiry, nil
 }
 
-// loadCACertPool reads a PEM-encoded CA certificate file and returns a cert pool.
-func loadCACertPool(caFile string) (*x509.CertPool, error) {
+// loadCACertPool reads a PEM-encoded CA certificate file and returns a cert pool
+// together with the earliest expiry of its certificates.
+func loadCACertPool(caFile string) (*x509.CertPool, time.Time, error) {
 	caCert, err := os.ReadFile(filepath.Clean(caFile))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+		return nil, time.Time{}, fmt.Errorf("failed to read CA certificate file: %w", err)
 	}
 
 	caCertPool := x509.NewCertPool()
 	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return nil, fmt.Errorf("failed to parse CA certificate")
+		return nil, time.Time{}, fmt.Errorf("failed to parse CA certificate")
+	}
+	expiry, err := helper.CertificatesExpiry(caCert)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to determine expiry of CA certificate: %w", err)
 	}
-	return caCertPool, nil
+	return caCertPool, expiry, nil
 }
 
+// setupLoadBalancing creates the balancer and, if configured, the DNS discovery of the endpoints.
+// Without load balancing configuration, the balancer has URL as its only endpoint.
+func (o *Output) setupLoadBalancing(config *configv1alpha1.OutputHTTP) error {
+	lb := config.LoadBalancing
+	if lb == nil {
+		o.balancer = newBalancer([]string{config.URL}, configv1alpha1.LoadBalancingPolicyRoundRobin, 0, 0)
+		return nil
+	}
+
+	var ejectionCooldown time.Duration
+	if lb.EjectionCooldown != nil {
+		ejectionCooldown = lb.EjectionCooldown.Duration
+	}
+	o.balancer = newBalancer(append([]string{config.URL}, lb.Endpoints...), lb.Policy, int(lb.FailureThreshold), ejectionCooldown)
+
+	if lb.DNS == nil {
+		return nil
+	}
+
+	d, err := newDiscovery(config.URL, lb.DNS, o.resolver)
+	if err != nil {
+		return err
+	}
+	o.discovery = d
+	o.hostHeader = d.baseURL.Host
+	o.tlsServerName = d.baseURL.Hostname()
+	return nil
+}
+
+// statusError is returned when an output responds with a non-successful status code.
+type statusError struct {
+	statusCode int
+	body       []byte
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("output returned status %d: %s", e.statusCode, string(e.body))
+}
+
+// errReadResponse is wrapped by errors which occur while reading the response body.
+var errReadResponse = errors.New("failed to read response body")
+
 func isRetryableStatus(statusCode int) bool {
 	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
 }
@@ -403,7 +583,7 @@ func readAndCloseBody(resp *http.Response, logger logr.Logger) ([]byte, error) {
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("%w: %w", errReadResponse, err)
 	}
 
 	return body, nil
@@ -8,15 +8,27 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
 
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+	"github.com/gardener/auditlog-forwarder/internal/encryption"
 	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
@@ -137,6 +149,160 @@ var _ = Describe("HTTP Output", func() {
 			Expect(receivedBody).To(Equal(testData))
 		})
 
+		It("should ignore whitespace around the configured compression", func() {
+			testServer.Close()
+			var receivedEncoding string
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedEncoding = r.Header.Get("Content-Encoding")
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL, Compression: " gzip\n"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(httpOutput.Send(context.Background(), []byte(`{"events": ["test"]}`))).To(Succeed())
+			Expect(receivedEncoding).To(Equal("gzip"))
+		})
+
+		It("should send events compressed with zstd at the configured level", func() {
+			testServer.Close()
+			var receivedEncoding string
+			var receivedBody []byte
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedEncoding = r.Header.Get("Content-Encoding")
+				body, err := io.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				decoder, err := zstd.NewReader(nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer decoder.Close()
+				receivedBody, err = decoder.DecodeAll(body, nil)
+				Expect(err).NotTo(HaveOccurred())
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			config := &configv1alpha1.OutputHTTP{
+				URL:              testServer.URL,
+				Compression:      "zstd",
+				CompressionLevel: ptr.To[int32](19),
+			}
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(), config)
+			Expect(err).NotTo(HaveOccurred())
+
+			testData := []byte(`{"events": ["test"]}`)
+			Expect(httpOutput.Send(context.Background(), testData)).To(Succeed())
+
+			Expect(receivedEncoding).To(Equal("zstd"))
+			Expect(receivedBody).To(Equal(testData))
+		})
+
+		It("should send the events transformed into the configured schema", func() {
+			testServer.Close()
+			var receivedContentType string
+			var receivedBody []byte
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedContentType = r.Header.Get("Content-Type")
+				body, err := io.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				receivedBody = body
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL},
+				httpoutput.WithSchema(configv1alpha1.SchemaCEF))
+			Expect(err).NotTo(HaveOccurred())
+
+			testData := []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[` +
+				`{"level":"Metadata","auditID":"1","stage":"ResponseComplete","verb":"get","requestURI":"/healthz","user":{}}]}`)
+			Expect(httpOutput.Send(context.Background(), testData)).To(Succeed())
+
+			Expect(receivedContentType).To(Equal("text/plain; charset=utf-8"))
+			Expect(string(receivedBody)).To(HavePrefix("CEF:0|Kubernetes|kube-apiserver|v1|get|get /healthz|3|"))
+		})
+
+		It("should send one request per event in the single event format", func() {
+			testServer.Close()
+			var receivedBodies []string
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				receivedBodies = append(receivedBodies, string(body))
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL},
+				httpoutput.WithFormat(configv1alpha1.FormatSingleEvent))
+			Expect(err).NotTo(HaveOccurred())
+
+			testData := []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[{"auditID":"1"},{"auditID":"2"}]}`)
+			Expect(httpOutput.Send(context.Background(), testData)).To(Succeed())
+
+			Expect(receivedBodies).To(Equal([]string{`{"auditID":"1"}`, `{"auditID":"2"}`}))
+		})
+
+		It("should send the compressed events encrypted for the recipients", func() {
+			testServer.Close()
+			var receivedHeader http.Header
+			var receivedBody []byte
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedHeader = r.Header.Clone()
+				body, err := io.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				receivedBody = body
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			Expect(err).NotTo(HaveOccurred())
+			publicKeyFile := filepath.Join(GinkgoT().TempDir(), "recipient.pub")
+			Expect(os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)).To(Succeed())
+
+			httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL, Compression: "gzip"},
+				httpoutput.WithEncryption(&configv1alpha1.Encryption{RecipientPublicKeyFiles: []string{publicKeyFile}}))
+			Expect(err).NotTo(HaveOccurred())
+
+			testData := []byte(`{"events": ["test"]}`)
+			Expect(httpOutput.Send(context.Background(), testData)).To(Succeed())
+
+			Expect(receivedHeader.Get("Content-Type")).To(Equal("application/jose+json"))
+			Expect(receivedHeader.Values("Content-Encoding")).To(BeEmpty())
+
+			plaintext, err := encryption.Decrypt(receivedBody, []crypto.PrivateKey{key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plaintext.ContentType).To(Equal("application/json"))
+			Expect(plaintext.ContentEncoding).To(Equal("gzip"))
+			Expect(encoding.Decompress(plaintext.Data, plaintext.ContentEncoding)).To(Equal(testData))
+		})
+
+		It("should fail to create an output with invalid recipient keys", func() {
+			_, err := httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL},
+				httpoutput.WithEncryption(&configv1alpha1.Encryption{RecipientPublicKeyFiles: []string{filepath.Join(GinkgoT().TempDir(), "missing.pub")}}))
+			Expect(err).To(MatchError(ContainSubstring("failed to set up encryption")))
+		})
+
+		It("should reuse the compressed payload from the context", func() {
+			config := &configv1alpha1.OutputHTTP{
+				URL:         testServer.URL,
+				Compression: "gzip",
+			}
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(), config)
+			Expect(err).NotTo(HaveOccurred())
+
+			testData := []byte(`{"events": ["test"]}`)
+			payload := encoding.NewPayload(testData)
+			compressed, err := payload.Compressed(encoding.Compression{Algorithm: encoding.Gzip})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(httpOutput.Send(encoding.WithPayload(context.Background(), payload), testData)).To(Succeed())
+			Expect(response).To(Equal(compressed))
+		})
+
 		It("should handle server errors", func() {
 			responseCode = http.StatusInternalServerError
 
@@ -0,0 +1,286 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Load Balancing", func() {
+	var (
+		serverA, serverB     *httptest.Server
+		requestsA, requestsB atomic.Int32
+		statusA, statusB     atomic.Int32
+		httpOutput           *httpoutput.Output
+		lb                   *configv1alpha1.LoadBalancing
+	)
+
+	newServer := func(requests, status *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(int(status.Load()))
+		}))
+	}
+
+	BeforeEach(func() {
+		requestsA.Store(0)
+		requestsB.Store(0)
+		statusA.Store(http.StatusOK)
+		statusB.Store(http.StatusOK)
+		serverA = newServer(&requestsA, &statusA)
+		serverB = newServer(&requestsB, &statusB)
+
+		lb = &configv1alpha1.LoadBalancing{
+			Policy:           configv1alpha1.LoadBalancingPolicyRoundRobin,
+			Endpoints:        []string{serverB.URL},
+			FailureThreshold: 1,
+			EjectionCooldown: &metav1.Duration{Duration: time.Hour},
+		}
+
+		originalBackoff := *httpoutput.BackoffFunc
+		originalSleep := *httpoutput.SleepFunc
+		*httpoutput.BackoffFunc = func(_ int, _, _ time.Duration) time.Duration { return 0 }
+		*httpoutput.SleepFunc = func(_ context.Context, _ time.Duration) error { return nil }
+		DeferCleanup(func() {
+			*httpoutput.BackoffFunc = originalBackoff
+			*httpoutput.SleepFunc = originalSleep
+		})
+	})
+
+	AfterEach(func() {
+		if httpOutput != nil {
+			Expect(httpOutput.Close()).To(Succeed())
+		}
+		serverA.Close()
+		serverB.Close()
+	})
+
+	It("should distribute requests across the URL and the endpoints in round-robin order", func() {
+		var err error
+		httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: serverA.URL, LoadBalancing: lb})
+		Expect(err).NotTo(HaveOccurred())
+
+		for range 4 {
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		}
+
+		Expect(requestsA.Load()).To(Equal(int32(2)))
+		Expect(requestsB.Load()).To(Equal(int32(2)))
+	})
+
+	It("should retry on a different endpoint and eject the failing one", func() {
+		statusA.Store(http.StatusServiceUnavailable)
+
+		var err error
+		httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: serverA.URL, LoadBalancing: lb})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(requestsA.Load()).To(Equal(int32(1)))
+		Expect(requestsB.Load()).To(Equal(int32(1)))
+
+		// The failing endpoint is ejected for the cooldown and no longer selected.
+		for range 3 {
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		}
+		Expect(requestsA.Load()).To(Equal(int32(1)))
+		Expect(requestsB.Load()).To(Equal(int32(4)))
+	})
+
+	It("should keep sending to ejected endpoints when all endpoints are ejected", func() {
+		statusA.Store(http.StatusServiceUnavailable)
+		statusB.Store(http.StatusServiceUnavailable)
+
+		var err error
+		httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: serverA.URL, LoadBalancing: lb},
+			httpoutput.WithMaxSendAttempts(2))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(MatchError(ContainSubstring("output returned status 503")))
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(MatchError(ContainSubstring("output returned status 503")))
+		Expect(requestsA.Load() + requestsB.Load()).To(Equal(int32(4)))
+	})
+
+	It("should not eject endpoints responding with client errors", func() {
+		statusA.Store(http.StatusBadRequest)
+
+		var err error
+		httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: serverA.URL, LoadBalancing: lb})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(MatchError(ContainSubstring("output returned status 400")))
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(MatchError(ContainSubstring("output returned status 400")))
+		Expect(requestsA.Load()).To(Equal(int32(2)))
+	})
+
+	It("should prefer the endpoint with the least outstanding requests", func() {
+		release := make(chan struct{})
+		blocking := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requestsA.Add(1)
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer blocking.Close()
+
+		lb.Policy = configv1alpha1.LoadBalancingPolicyLeastOutstandingRequests
+
+		var err error
+		httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: blocking.URL, LoadBalancing: lb})
+		Expect(err).NotTo(HaveOccurred())
+
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			defer GinkgoRecover()
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		})
+		Eventually(requestsA.Load).Should(Equal(int32(1)))
+
+		for range 3 {
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		}
+		Expect(requestsB.Load()).To(Equal(int32(3)))
+
+		close(release)
+		wg.Wait()
+		Expect(requestsA.Load()).To(Equal(int32(1)))
+	})
+
+	Describe("DNS discovery", func() {
+		var (
+			resolver *fakeResolver
+			port     string
+			host     atomic.Value
+		)
+
+		BeforeEach(func() {
+			serverA.Close()
+			serverA = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestsA.Add(1)
+				host.Store(r.Host)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			u, err := url.Parse(serverA.URL)
+			Expect(err).NotTo(HaveOccurred())
+			port = u.Port()
+
+			resolver = &fakeResolver{}
+			lb.Endpoints = nil
+			lb.DNS = &configv1alpha1.DNSDiscovery{
+				RecordType:      configv1alpha1.DNSRecordTypeA,
+				RefreshInterval: &metav1.Duration{Duration: 20 * time.Millisecond},
+			}
+		})
+
+		It("should send requests to the resolved addresses with the original Host header", func() {
+			resolver.setHosts("127.0.0.1")
+
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(),
+				&configv1alpha1.OutputHTTP{URL: "http://audit.example:" + port + "/audit", LoadBalancing: lb},
+				httpoutput.WithResolver(resolver))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+			Expect(requestsA.Load()).To(Equal(int32(1)))
+			Expect(host.Load()).To(Equal("audit.example:" + port))
+		})
+
+		It("should periodically refresh the resolved addresses", func() {
+			resolver.setHosts("127.0.0.2")
+
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(),
+				&configv1alpha1.OutputHTTP{URL: "http://audit.example:" + port + "/audit", LoadBalancing: lb},
+				httpoutput.WithResolver(resolver), httpoutput.WithMaxSendAttempts(1))
+			Expect(err).NotTo(HaveOccurred())
+
+			resolver.setHosts("127.0.0.1")
+			Eventually(func() error {
+				return httpOutput.Send(context.Background(), []byte(`{}`))
+			}).WithTimeout(2 * time.Second).WithPolling(20 * time.Millisecond).Should(Succeed())
+		})
+
+		It("should keep the URL as endpoint when the resolution fails", func() {
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(),
+				&configv1alpha1.OutputHTTP{URL: serverA.URL, LoadBalancing: lb},
+				httpoutput.WithResolver(resolver))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+			Expect(requestsA.Load()).To(Equal(int32(1)))
+		})
+
+		It("should send requests to the targets of SRV records", func() {
+			resolver.srv = []*net.SRV{{Target: "localhost.", Port: mustParsePort(port)}}
+			lb.DNS.RecordType = configv1alpha1.DNSRecordTypeSRV
+			lb.DNS.Service = "audit"
+
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(),
+				&configv1alpha1.OutputHTTP{URL: "http://audit.example/audit", LoadBalancing: lb},
+				httpoutput.WithResolver(resolver))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+			Expect(requestsA.Load()).To(Equal(int32(1)))
+			Expect(host.Load()).To(Equal("audit.example"))
+			Expect(resolver.srvName.Load()).To(Equal("audit.example"))
+		})
+	})
+})
+
+// fakeResolver is a Resolver returning preconfigured records.
+type fakeResolver struct {
+	mu      sync.Mutex
+	hosts   []string
+	srv     []*net.SRV
+	srvName atomic.Value
+}
+
+func (f *fakeResolver) setHosts(hosts ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hosts = hosts
+}
+
+func (f *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.hosts) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return f.hosts, nil
+}
+
+func (f *fakeResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.srvName.Store(name)
+	if len(f.srv) == 0 {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return "_" + service + "._" + proto + "." + name, f.srv, nil
+}
+
+func mustParsePort(port string) uint16 {
+	p, err := net.LookupPort("tcp", port)
+	Expect(err).NotTo(HaveOccurred())
+	return uint16(p) //#nosec G115 -- port numbers fit into uint16.
+}
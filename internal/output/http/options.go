@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
 
 // Option is a functional option for configuring an HTTP Output.
@@ -61,3 +63,39 @@ func WithTLSReloadDebounce(d time.Duration) Option {
 		return nil
 	}
 }
+
+// WithSchema sets the schema the audit events are transformed into before they are sent.
+// Defaults to forwarding the "audit.k8s.io/v1" EventList unchanged.
+func WithSchema(schema configv1alpha1.Schema) Option {
+	return func(o *Output) error {
+		o.schema = schema
+		return nil
+	}
+}
+
+// WithFormat sets how the audit events of a request are framed into request bodies.
+// Defaults to "EventList" for the "KubernetesAudit" schema and to "NDJSON" otherwise.
+func WithFormat(format configv1alpha1.Format) Option {
+	return func(o *Output) error {
+		o.format = format
+		return nil
+	}
+}
+
+// WithEncryption encrypts the request bodies for the recipients of the given configuration after compression.
+// Defaults to sending the bodies unencrypted.
+func WithEncryption(encryption *configv1alpha1.Encryption) Option {
+	return func(o *Output) error {
+		o.encryptionConfig = encryption
+		return nil
+	}
+}
+
+// WithResolver sets the resolver used to discover endpoints via DNS.
+// Defaults to [net.DefaultResolver].
+func WithResolver(resolver Resolver) Option {
+	return func(o *Output) error {
+		o.resolver = resolver
+		return nil
+	}
+}
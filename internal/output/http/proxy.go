@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// newProxyFunc returns the function selecting the proxy for a request based on the given configuration.
+// The proxy credentials are read once; the function is recreated whenever the HTTP client is rebuilt.
+func newProxyFunc(config *configv1alpha1.OutputHTTPProxy) (func(*http.Request) (*url.URL, error), error) {
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  config.URL,
+		HTTPSProxy: config.URL,
+	}
+	if config.FromEnvironment {
+		proxyConfig = httpproxy.FromEnvironment()
+	}
+
+	if len(config.NoProxy) > 0 {
+		noProxy := config.NoProxy
+		if proxyConfig.NoProxy != "" {
+			noProxy = append([]string{proxyConfig.NoProxy}, noProxy...)
+		}
+		proxyConfig.NoProxy = strings.Join(noProxy, ",")
+	}
+
+	var user *url.Userinfo
+	if config.BasicAuth != nil {
+		username, err := readCredentialFile(config.BasicAuth.UsernameFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read proxy username: %w", err)
+		}
+		password, err := readCredentialFile(config.BasicAuth.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read proxy password: %w", err)
+		}
+		user = url.UserPassword(username, password)
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxyFunc(req.URL)
+		if err != nil || proxyURL == nil || user == nil {
+			return proxyURL, err
+		}
+
+		// The transport derives the Proxy-Authorization header from the user information of the proxy URL.
+		withUser := *proxyURL
+		withUser.User = user
+		return &withUser, nil
+	}, nil
+}
@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Proxy", func() {
+	var (
+		tmpDir     string
+		ctx        context.Context
+		cancel     context.CancelFunc
+		target     *httptest.Server
+		proxy      *connectProxy
+		port       string
+		config     *configv1alpha1.OutputHTTP
+		httpOutput *httpoutput.Output
+	)
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		tmpDir = GinkgoT().TempDir()
+		ctx, cancel = context.WithCancel(context.Background())
+
+		target = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		proxy = newConnectProxy(target.Listener.Addr().String())
+
+		var err error
+		_, port, err = net.SplitHostPort(target.Listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+
+		// The host of the URL only resolves to the target when the request goes through the proxy.
+		config = &configv1alpha1.OutputHTTP{
+			URL: "https://audit.example.com:" + port + "/audit",
+			TLS: &configv1alpha1.ClientTLS{
+				CAFile: writeFile("ca.crt", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: target.Certificate().Raw}))),
+			},
+			Proxy: &configv1alpha1.OutputHTTPProxy{URL: proxy.server.URL},
+		}
+	})
+
+	AfterEach(func() {
+		cancel()
+		if httpOutput != nil {
+			Expect(httpOutput.Close()).To(Succeed())
+		}
+		proxy.server.Close()
+		target.Close()
+	})
+
+	It("should send requests through the proxy", func() {
+		var err error
+		httpOutput, err = httpoutput.New(ctx, config, httpoutput.WithMaxSendAttempts(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(proxy.connects.Load()).To(Equal(int32(1)))
+		Expect(proxy.lastTarget()).To(Equal("audit.example.com:" + port))
+		Expect(proxy.lastAuthorization()).To(BeEmpty())
+	})
+
+	It("should authenticate to the proxy and pick up credential rotation", func() {
+		config.Proxy.BasicAuth = &configv1alpha1.BasicAuth{
+			UsernameFile: writeFile("username", "forwarder"),
+			PasswordFile: writeFile("password", "password-1"),
+		}
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, config, httpoutput.WithMaxSendAttempts(1), httpoutput.WithTLSReloadDebounce(50*time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(proxy.lastAuthorization()).To(Equal(basicAuthorization("forwarder", "password-1")))
+
+		writeFile("password", "password-2")
+		Eventually(func() string {
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+			return proxy.lastAuthorization()
+		}).WithTimeout(5 * time.Second).WithPolling(100 * time.Millisecond).Should(Equal(basicAuthorization("forwarder", "password-2")))
+	})
+
+	It("should honour the proxy environment variables if no proxy is configured", func() {
+		GinkgoT().Setenv("HTTPS_PROXY", proxy.server.URL)
+		GinkgoT().Setenv("NO_PROXY", "")
+		config.Proxy = nil
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, config, httpoutput.WithMaxSendAttempts(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(proxy.connects.Load()).To(Equal(int32(1)))
+		Expect(proxy.lastTarget()).To(Equal("audit.example.com:" + port))
+	})
+
+	It("should fail to create the output when the proxy credentials cannot be read", func() {
+		config.Proxy.BasicAuth = &configv1alpha1.BasicAuth{
+			UsernameFile: writeFile("username", "forwarder"),
+			PasswordFile: filepath.Join(tmpDir, "missing"),
+		}
+
+		out, err := httpoutput.New(ctx, config)
+		Expect(err).To(MatchError(ContainSubstring("failed to read proxy password")))
+		Expect(out).To(BeNil())
+	})
+
+	Describe("#NewProxyFunc", func() {
+		proxyFor := func(proxyConfig *configv1alpha1.OutputHTTPProxy, rawURL string) *url.URL {
+			proxyFunc, err := httpoutput.NewProxyFunc(proxyConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			req, err := http.NewRequest(http.MethodPost, rawURL, nil)
+			Expect(err).NotTo(HaveOccurred())
+			proxyURL, err := proxyFunc(req)
+			Expect(err).NotTo(HaveOccurred())
+			return proxyURL
+		}
+
+		It("should bypass the proxy for hosts matching noProxy", func() {
+			proxyConfig := &configv1alpha1.OutputHTTPProxy{
+				URL:     "http://proxy.example.com:3128",
+				NoProxy: []string{".internal.example.com", "10.0.0.0/8"},
+			}
+
+			Expect(proxyFor(proxyConfig, "https://audit.example.com/audit")).To(Equal(&url.URL{Scheme: "http", Host: "proxy.example.com:3128"}))
+			Expect(proxyFor(proxyConfig, "https://audit.internal.example.com/audit")).To(BeNil())
+			Expect(proxyFor(proxyConfig, "https://10.1.2.3/audit")).To(BeNil())
+		})
+
+		It("should use the proxy environment variables", func() {
+			GinkgoT().Setenv("HTTPS_PROXY", "http://env-proxy.example.com:3128")
+			GinkgoT().Setenv("NO_PROXY", "skip.example.com")
+			proxyConfig := &configv1alpha1.OutputHTTPProxy{
+				FromEnvironment: true,
+				NoProxy:         []string{"other.example.com"},
+			}
+
+			Expect(proxyFor(proxyConfig, "https://audit.example.com/audit")).To(Equal(&url.URL{Scheme: "http", Host: "env-proxy.example.com:3128"}))
+			Expect(proxyFor(proxyConfig, "https://skip.example.com/audit")).To(BeNil())
+			Expect(proxyFor(proxyConfig, "https://other.example.com/audit")).To(BeNil())
+		})
+	})
+})
+
+func basicAuthorization(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// connectProxy is a stand-in for a forward proxy. It tunnels all CONNECT requests to a fixed address,
+// regardless of the requested target, and records the requested target and the proxy authorization.
+type connectProxy struct {
+	server   *httptest.Server
+	connects atomic.Int32
+
+	mu            sync.Mutex
+	target        string
+	authorization string
+}
+
+func newConnectProxy(upstream string) *connectProxy {
+	p := &connectProxy{}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		p.connects.Add(1)
+		p.mu.Lock()
+		p.target = r.Host
+		p.authorization = r.Header.Get("Proxy-Authorization")
+		p.mu.Unlock()
+
+		upstreamConn, err := net.Dial("tcp", upstream)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer upstreamConn.Close()
+
+		clientConn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer clientConn.Close()
+
+		if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+			return
+		}
+
+		// Closing the upstream connection once the client is gone also ends the copy in the other direction.
+		done := make(chan struct{})
+		go func() {
+			_, _ = io.Copy(upstreamConn, buf)
+			_ = upstreamConn.Close()
+			close(done)
+		}()
+		_, _ = io.Copy(clientConn, upstreamConn)
+		_ = clientConn.Close()
+		<-done
+	}))
+	return p
+}
+
+func (p *connectProxy) lastTarget() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.target
+}
+
+func (p *connectProxy) lastAuthorization() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.authorization
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gardener/auditlog-forwarder/internal/output"
+)
+
+var (
+	_ output.StatusReporter = (*Output)(nil)
+	_ output.Checker        = (*Output)(nil)
+)
+
+// Status returns the circuit state of the endpoints and the expiry of the client certificate.
+func (o *Output) Status() output.Status {
+	status := output.Status{Circuit: o.balancer.circuitState()}
+
+	if transport, ok := o.client.Load().Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		if certs := transport.TLSClientConfig.Certificates; len(certs) > 0 && certs[0].Leaf != nil {
+			expiry := certs[0].Leaf.NotAfter
+			status.CertificateExpiry = &expiry
+		}
+	}
+
+	return status
+}
+
+// Check sends a HEAD request to each endpoint until one of them responds without a server error.
+// Any other response, e.g. 405 Method Not Allowed, proves that the endpoint is reachable.
+// The requests are not recorded in the passive health of the endpoints.
+func (o *Output) Check(ctx context.Context) error {
+	var errs []error
+	for _, url := range o.balancer.urls() {
+		err := o.checkEndpoint(ctx, url)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("endpoint %s: %w", url, err))
+	}
+	return errors.Join(errs...)
+}
+
+func (o *Output) checkEndpoint(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if o.hostHeader != "" {
+		req.Host = o.hostHeader
+	}
+
+	resp, err := o.client.Load().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		o.logger.Error(err, "Failed to close check response body", "endpoint", url)
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// circuitState returns whether none, some or all endpoints are ejected.
+func (b *balancer) circuitState() output.CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	ejected := 0
+	for _, e := range b.endpoints {
+		if now.Before(e.ejectedUntil) {
+			ejected++
+		}
+	}
+
+	switch {
+	case ejected == 0:
+		return output.CircuitClosed
+	case ejected == len(b.endpoints):
+		return output.CircuitOpen
+	default:
+		return output.CircuitPartiallyOpen
+	}
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gardener/auditlog-forwarder/internal/output"
+	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		serverA, serverB *httptest.Server
+		statusA, statusB atomic.Int32
+		methods          chan string
+		httpOutput       *httpoutput.Output
+	)
+
+	newServer := func(status *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case methods <- r.Method:
+			default:
+			}
+			w.WriteHeader(int(status.Load()))
+		}))
+	}
+
+	BeforeEach(func() {
+		methods = make(chan string, 10)
+		statusA.Store(http.StatusOK)
+		statusB.Store(http.StatusOK)
+		serverA = newServer(&statusA)
+		serverB = newServer(&statusB)
+
+		var err error
+		httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{
+			URL: serverA.URL,
+			LoadBalancing: &configv1alpha1.LoadBalancing{
+				Policy:           configv1alpha1.LoadBalancingPolicyRoundRobin,
+				Endpoints:        []string{serverB.URL},
+				FailureThreshold: 1,
+				EjectionCooldown: &metav1.Duration{Duration: time.Hour},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		originalBackoff := *httpoutput.BackoffFunc
+		originalSleep := *httpoutput.SleepFunc
+		*httpoutput.BackoffFunc = func(_ int, _, _ time.Duration) time.Duration { return 0 }
+		*httpoutput.SleepFunc = func(_ context.Context, _ time.Duration) error { return nil }
+		DeferCleanup(func() {
+			*httpoutput.BackoffFunc = originalBackoff
+			*httpoutput.SleepFunc = originalSleep
+		})
+	})
+
+	AfterEach(func() {
+		Expect(httpOutput.Close()).To(Succeed())
+		serverA.Close()
+		serverB.Close()
+	})
+
+	Describe("#Status", func() {
+		It("should report the circuit state of the endpoints", func() {
+			Expect(httpOutput.Status()).To(Equal(output.Status{Circuit: output.CircuitClosed}))
+
+			statusA.Store(http.StatusServiceUnavailable)
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+			Expect(httpOutput.Status().Circuit).To(Equal(output.CircuitPartiallyOpen))
+
+			statusB.Store(http.StatusServiceUnavailable)
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).NotTo(Succeed())
+			Expect(httpOutput.Status().Circuit).To(Equal(output.CircuitOpen))
+		})
+
+		It("should report the expiry of the client certificate", func() {
+			tmpDir := GinkgoT().TempDir()
+			caKey, caCert, _ := generateCA("Test CA")
+			certPEM, keyPEM := generateClientCert(caKey, caCert, "client")
+			certFile := filepath.Join(tmpDir, "client.crt")
+			keyFile := filepath.Join(tmpDir, "client.key")
+			Expect(os.WriteFile(certFile, certPEM, 0600)).To(Succeed())
+			Expect(os.WriteFile(keyFile, keyPEM, 0600)).To(Succeed())
+
+			block, _ := pem.Decode(certPEM)
+			cert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+
+			tlsOutput, err := httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{
+				URL: "https://127.0.0.1:1",
+				TLS: &configv1alpha1.ClientTLS{CertFile: certFile, KeyFile: keyFile},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { Expect(tlsOutput.Close()).To(Succeed()) }()
+
+			Expect(tlsOutput.Status().CertificateExpiry).To(HaveValue(BeTemporally("==", cert.NotAfter)))
+		})
+	})
+
+	Describe("#Check", func() {
+		It("should succeed if an endpoint responds without a server error", func() {
+			statusA.Store(http.StatusMethodNotAllowed)
+
+			Expect(httpOutput.Check(context.Background())).To(Succeed())
+			Expect(methods).To(Receive(Equal(http.MethodHead)))
+		})
+
+		It("should try the next endpoint if one responds with a server error", func() {
+			statusA.Store(http.StatusServiceUnavailable)
+
+			Expect(httpOutput.Check(context.Background())).To(Succeed())
+			Expect(methods).To(HaveLen(2))
+		})
+
+		It("should fail if no endpoint is reachable", func() {
+			statusA.Store(http.StatusServiceUnavailable)
+			serverB.Close()
+
+			err := httpOutput.Check(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("unexpected status code 503")))
+			Expect(err).To(MatchError(ContainSubstring("failed to send request")))
+		})
+
+		It("should not eject endpoints", func() {
+			statusA.Store(http.StatusServiceUnavailable)
+			statusB.Store(http.StatusServiceUnavailable)
+
+			Expect(httpOutput.Check(context.Background())).NotTo(Succeed())
+			Expect(httpOutput.Status().Circuit).To(Equal(output.CircuitClosed))
+		})
+	})
+})
@@ -23,7 +23,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
 	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
@@ -254,6 +256,45 @@ var _ = Describe("TLS Hot Reload", func() {
 			return httpOutput.Send(context.Background(), []byte(`{"test": 2}`))
 		}, 500*time.Millisecond, 50*time.Millisecond).Should(Succeed())
 	})
+
+	It("should export the expiry of the client certificate and CA and update it on reload", func() {
+		caKey, caCert, caPEM := generateCA("Test CA")
+		firstExpiry := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+		clientCertPEM, clientKeyPEM := generateClientCertExpiringAt(caKey, caCert, "client", firstExpiry)
+
+		caFile := filepath.Join(tmpDir, "ca.crt")
+		certFile := filepath.Join(tmpDir, "client.crt")
+		keyFile := filepath.Join(tmpDir, "client.key")
+		Expect(os.WriteFile(caFile, caPEM, 0600)).To(Succeed())
+		Expect(os.WriteFile(certFile, clientCertPEM, 0600)).To(Succeed())
+		Expect(os.WriteFile(keyFile, clientKeyPEM, 0600)).To(Succeed())
+
+		config := &configv1alpha1.OutputHTTP{
+			URL: "https://cert-expiry.example.com",
+			TLS: &configv1alpha1.ClientTLS{
+				CAFile:   caFile,
+				CertFile: certFile,
+				KeyFile:  keyFile,
+			},
+		}
+
+		var err error
+		httpOutput, err = httpoutput.New(ctx, config, httpoutput.WithTLSReloadDebounce(50*time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+
+		clientGauge := metrics.CertificateExpiry.WithLabelValues(metrics.CertificateOutputClient, config.URL)
+		caGauge := metrics.CertificateExpiry.WithLabelValues(metrics.CertificateOutputCA, config.URL)
+		Expect(testutil.ToFloat64(clientGauge)).To(Equal(float64(firstExpiry.Unix())))
+		Expect(testutil.ToFloat64(caGauge)).To(Equal(float64(caCert.NotAfter.Unix())))
+
+		secondExpiry := firstExpiry.Add(24 * time.Hour)
+		clientCertPEM, clientKeyPEM = generateClientCertExpiringAt(caKey, caCert, "client", secondExpiry)
+		Expect(os.WriteFile(certFile, clientCertPEM, 0600)).To(Succeed())
+		Expect(os.WriteFile(keyFile, clientKeyPEM, 0600)).To(Succeed())
+
+		Eventually(func() float64 { return testutil.ToFloat64(clientGauge) }, 2*time.Second, 50*time.Millisecond).
+			Should(Equal(float64(secondExpiry.Unix())))
+	})
 })
 
 // generateCA creates a self-signed CA certificate.
@@ -283,6 +324,11 @@ func generateCA(cn string) (*ecdsa.PrivateKey, *x509.Certificate, []byte) {
 
 // generateClientCert creates a client certificate signed by the given CA.
 func generateClientCert(caKey *ecdsa.PrivateKey, caCert *x509.Certificate, cn string) (certPEM, keyPEM []byte) {
+	return generateClientCertExpiringAt(caKey, caCert, cn, time.Now().Add(24*time.Hour))
+}
+
+// generateClientCertExpiringAt creates a client certificate signed by the given CA which expires at notAfter.
+func generateClientCertExpiringAt(caKey *ecdsa.PrivateKey, caCert *x509.Certificate, cn string, notAfter time.Time) (certPEM, keyPEM []byte) {
 	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	Expect(err).NotTo(HaveOccurred())
 
@@ -290,7 +336,7 @@ func generateClientCert(caKey *ecdsa.PrivateKey, caCert *x509.Certificate, cn st
 		SerialNumber: big.NewInt(time.Now().UnixNano()),
 		Subject:      pkix.Name{CommonName: cn},
 		NotBefore:    time.Now().Add(-1 * time.Hour),
-		NotAfter:     time.Now().Add(24 * time.Hour),
+		NotAfter:     notAfter,
 		KeyUsage:     x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 	}
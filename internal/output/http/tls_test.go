@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("TLS Parameters", func() {
+	var (
+		testServer   *httptest.Server
+		serverCAFile string
+		serverNames  chan string
+		httpOutput   *httpoutput.Output
+	)
+
+	BeforeEach(func() {
+		serverNames = make(chan string, 10)
+		testServer = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serverNames <- r.TLS.ServerName
+			w.WriteHeader(http.StatusOK)
+		}))
+		testServer.TLS = &tls.Config{MinVersion: tls.VersionTLS13}
+		testServer.StartTLS()
+
+		// The certificate of httptest servers is valid for example.com, *.example.com and 127.0.0.1.
+		serverCAFile = filepath.Join(GinkgoT().TempDir(), "server-ca.crt")
+		serverCAPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testServer.TLS.Certificates[0].Certificate[0]})
+		Expect(os.WriteFile(serverCAFile, serverCAPEM, 0600)).To(Succeed())
+
+		originalBackoff := *httpoutput.BackoffFunc
+		originalSleep := *httpoutput.SleepFunc
+		*httpoutput.BackoffFunc = func(_ int, _, _ time.Duration) time.Duration { return 0 }
+		*httpoutput.SleepFunc = func(_ context.Context, _ time.Duration) error { return nil }
+		DeferCleanup(func() {
+			*httpoutput.BackoffFunc = originalBackoff
+			*httpoutput.SleepFunc = originalSleep
+		})
+	})
+
+	AfterEach(func() {
+		if httpOutput != nil {
+			Expect(httpOutput.Close()).To(Succeed())
+		}
+		testServer.Close()
+	})
+
+	newOutput := func(clientTLS configv1alpha1.ClientTLS) {
+		clientTLS.CAFile = serverCAFile
+		var err error
+		httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL, TLS: &clientTLS})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should connect with matching TLS parameters", func() {
+		newOutput(configv1alpha1.ClientTLS{TLSParameters: configv1alpha1.TLSParameters{
+			MinVersion:       configv1alpha1.TLSVersion13,
+			CurvePreferences: []string{"X25519"},
+		}})
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+	})
+
+	It("should fail if the versions of client and server do not overlap", func() {
+		newOutput(configv1alpha1.ClientTLS{TLSParameters: configv1alpha1.TLSParameters{MaxVersion: configv1alpha1.TLSVersion12}})
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(MatchError(ContainSubstring("protocol version not supported")))
+	})
+
+	It("should verify the server certificate for the configured server name", func() {
+		newOutput(configv1alpha1.ClientTLS{ServerName: "example.com"})
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		Expect(serverNames).To(Receive(Equal("example.com")))
+	})
+
+	It("should fail if the server certificate is not valid for the configured server name", func() {
+		newOutput(configv1alpha1.ClientTLS{ServerName: "audit.invalid"})
+
+		Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(MatchError(ContainSubstring("not audit.invalid")))
+	})
+
+	Context("with revocation checking", func() {
+		var (
+			caKey   *ecdsa.PrivateKey
+			caCert  *x509.Certificate
+			caFile  string
+			crlFile string
+		)
+
+		writeCRL := func(revoked ...*big.Int) {
+			var entries []x509.RevocationListEntry
+			for _, serial := range revoked {
+				entries = append(entries, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: time.Now()})
+			}
+			der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+				Number:                    big.NewInt(time.Now().UnixNano()),
+				ThisUpdate:                time.Now().Add(-time.Minute),
+				NextUpdate:                time.Now().Add(time.Hour),
+				RevokedCertificateEntries: entries,
+			}, caCert, caKey)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0600)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var caPEM []byte
+			caKey, caCert, caPEM = generateCA("Test CA")
+			tmpDir := GinkgoT().TempDir()
+			caFile = filepath.Join(tmpDir, "ca.crt")
+			crlFile = filepath.Join(tmpDir, "ca.crl")
+			Expect(os.WriteFile(caFile, caPEM, 0600)).To(Succeed())
+
+			testServer.Close()
+			testServer = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			testServer.TLS = &tls.Config{
+				Certificates: []tls.Certificate{generateServerCert(caKey, caCert, "127.0.0.1")},
+				MinVersion:   tls.VersionTLS12,
+			}
+			testServer.StartTLS()
+		})
+
+		newRevocationOutput := func(failurePolicy configv1alpha1.RevocationFailurePolicy) {
+			var err error
+			httpOutput, err = httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL, TLS: &configv1alpha1.ClientTLS{
+				CAFile: caFile,
+				Revocation: &configv1alpha1.Revocation{
+					CRLFiles:      []string{crlFile},
+					FailurePolicy: failurePolicy,
+				},
+			}}, httpoutput.WithTLSReloadDebounce(50*time.Millisecond))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		It("should accept a server certificate which is not revoked", func() {
+			writeCRL()
+			newRevocationOutput(configv1alpha1.RevocationFailurePolicyFailClosed)
+
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+		})
+
+		It("should reject a revoked server certificate once the CRL is reloaded", func() {
+			writeCRL()
+			newRevocationOutput(configv1alpha1.RevocationFailurePolicyFailClosed)
+			Expect(httpOutput.Send(context.Background(), []byte(`{}`))).To(Succeed())
+
+			writeCRL(testServer.TLS.Certificates[0].Leaf.SerialNumber)
+			Eventually(func() error {
+				return httpOutput.Send(context.Background(), []byte(`{}`))
+			}, 2*time.Second, 100*time.Millisecond).Should(MatchError(ContainSubstring("certificate is revoked")))
+		})
+
+		It("should fail for invalid CRL files", func() {
+			Expect(os.WriteFile(crlFile, []byte("invalid"), 0600)).To(Succeed())
+
+			_, err := httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL, TLS: &configv1alpha1.ClientTLS{
+				CAFile:     caFile,
+				Revocation: &configv1alpha1.Revocation{CRLFiles: []string{crlFile}},
+			}})
+			Expect(err).To(MatchError(ContainSubstring("failed to configure revocation checking")))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	httpoutput "github.com/gardener/auditlog-forwarder/internal/output/http"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		recorder         *tracetest.SpanRecorder
+		previousProvider trace.TracerProvider
+		requests         atomic.Int32
+		traceparents     chan string
+		testServer       *httptest.Server
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		previousProvider = otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+
+		requests.Store(0)
+		traceparents = make(chan string, 2)
+		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparents <- r.Header.Get("traceparent")
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		testServer.Close()
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	It("should trace each attempt and propagate its trace context", func() {
+		out, err := httpoutput.New(context.Background(), &configv1alpha1.OutputHTTP{URL: testServer.URL},
+			httpoutput.WithBaseBackoff(time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(out.Close()).To(Succeed()) }()
+
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+		Expect(out.Send(ctx, []byte(`{}`))).To(Succeed())
+		parent.End()
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(3))
+		first, second := spans[0], spans[1]
+		for i, span := range []sdktrace.ReadOnlySpan{first, second} {
+			Expect(span.Name()).To(Equal(http.MethodPost))
+			Expect(span.SpanKind()).To(Equal(trace.SpanKindClient))
+			Expect(span.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+			Expect(span.Attributes()).To(ContainElements(
+				attribute.String("output.name", testServer.URL),
+				attribute.Int("output.attempt", i+1),
+				attribute.String("url.full", testServer.URL),
+			))
+			Expect(<-traceparents).To(Equal("00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"))
+		}
+
+		Expect(first.Attributes()).To(ContainElement(attribute.Int("http.response.status_code", http.StatusServiceUnavailable)))
+		Expect(first.Status().Code).To(Equal(codes.Error))
+		Expect(second.Attributes()).To(ContainElement(attribute.Int("http.response.status_code", http.StatusOK)))
+		Expect(second.Status().Code).To(Equal(codes.Unset))
+	})
+})
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+)
+
+var (
+	_ Checker        = (*processedOutput)(nil)
+	_ StatusReporter = (*processedOutput)(nil)
+)
+
+// processedOutput processes the events with output specific processors before they are sent to the wrapped output.
+type processedOutput struct {
+	Output
+	processors []processor.Processor
+}
+
+// WithProcessors returns an output which processes the events with the given processors before they are sent to out.
+// The processed data of a request is shared by all outputs using the same processors in the same order.
+// If no processors are given, out is returned.
+func WithProcessors(out Output, processors ...processor.Processor) Output {
+	if len(processors) == 0 {
+		return out
+	}
+	return &processedOutput{Output: out, processors: processors}
+}
+
+// Send processes the data and sends the processed data to the wrapped output.
+// Nothing is sent if the processors dropped all received events, e.g. because they were filtered.
+// The sent payload is recorded, see [WithSentPayload].
+func (o *processedOutput) Send(ctx context.Context, data []byte) error {
+	// The numbers of events are cached by the payloads, so that they are counted once per request and processors.
+	received := encoding.PayloadFromContext(ctx, data)
+	payload := received
+	for _, p := range o.processors {
+		var err error
+		payload, err = payload.Derived(p, func(data []byte) ([]byte, error) {
+			return p.Process(ctx, data)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to process audit events with processor %s: %w", p.Name(), err)
+		}
+	}
+	if events, err := payload.Events(); err == nil && events == 0 {
+		if receivedEvents, err := received.Events(); err == nil && receivedEvents > 0 {
+			RecordSentPayload(ctx, nil)
+			return nil
+		}
+	}
+	if err := o.Output.Send(encoding.WithPayload(ctx, payload), payload.Data()); err != nil {
+		return err
+	}
+	RecordSentPayload(ctx, payload)
+	return nil
+}
+
+// Check actively checks the wrapped output if it supports active checks.
+func (o *processedOutput) Check(ctx context.Context) error {
+	if checker, ok := o.Output.(Checker); ok {
+		return checker.Check(ctx)
+	}
+	return nil
+}
+
+// Status returns the status reported by the wrapped output, if any.
+func (o *processedOutput) Status() Status {
+	if reporter, ok := o.Output.(StatusReporter); ok {
+		return reporter.Status()
+	}
+	return Status{}
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+)
+
+var _ = Describe("WithProcessors", func() {
+	var (
+		data  = []byte(`{"kind":"EventList"}`)
+		upper *fakeProcessor
+	)
+
+	BeforeEach(func() {
+		upper = &fakeProcessor{process: bytes.ToUpper}
+	})
+
+	It("should return the output without processors", func() {
+		out := &fakeOutput{}
+		Expect(WithProcessors(out)).To(BeIdenticalTo(out))
+	})
+
+	It("should send the processed data and share it between outputs", func() {
+		first, second := &fakeOutput{}, &fakeOutput{}
+		ctx := encoding.WithPayload(context.Background(), encoding.NewPayload(data))
+		ctx, sent := WithSentPayload(ctx)
+
+		Expect(WithProcessors(first, upper).Send(ctx, data)).To(Succeed())
+		payload, recorded := sent()
+		Expect(recorded).To(BeTrue())
+		Expect(payload.Data()).To(Equal(bytes.ToUpper(data)))
+		Expect(WithProcessors(second, upper).Send(ctx, data)).To(Succeed())
+
+		Expect(first.sent).To(Equal(bytes.ToUpper(data)))
+		Expect(&second.sent[0]).To(BeIdenticalTo(&first.sent[0]))
+		Expect(upper.calls.Load()).To(Equal(int32(1)))
+		// The outputs receive the derived payload, so that they share its encodings.
+		Expect(encoding.PayloadFromContext(first.ctx, first.sent)).To(BeIdenticalTo(encoding.PayloadFromContext(second.ctx, second.sent)))
+	})
+
+	It("should apply the processors in order", func() {
+		out := &fakeOutput{}
+		prefix := &fakeProcessor{process: func(data []byte) []byte { return append([]byte("processed:"), data...) }}
+
+		Expect(WithProcessors(out, prefix, upper).Send(context.Background(), data)).To(Succeed())
+		Expect(string(out.sent)).To(Equal(`PROCESSED:{"KIND":"EVENTLIST"}`))
+	})
+
+	It("should not send the data if the processors dropped all events", func() {
+		out := &fakeOutput{}
+		dropAll := &fakeProcessor{process: func([]byte) []byte { return []byte(`{"kind":"EventList","items":[]}`) }}
+		ctx, sent := WithSentPayload(context.Background())
+
+		Expect(WithProcessors(out, dropAll).Send(ctx, []byte(`{"kind":"EventList","items":[{}]}`))).To(Succeed())
+		Expect(out.sent).To(BeNil())
+		payload, recorded := sent()
+		Expect(recorded).To(BeTrue())
+		Expect(payload).To(BeNil())
+	})
+
+	It("should not send the data if processing fails", func() {
+		out := &fakeOutput{}
+		failing := &fakeProcessor{err: errors.New("fake")}
+
+		Expect(WithProcessors(out, failing).Send(context.Background(), data)).To(MatchError("failed to process audit events with processor fake: fake"))
+		Expect(out.sent).To(BeNil())
+	})
+
+	It("should forward checks and the status to the wrapped output", func() {
+		expiry := time.Now()
+		out := &fakeCheckedOutput{err: errors.New("unreachable"), status: Status{Circuit: CircuitOpen, CertificateExpiry: &expiry}}
+		processed := WithProcessors(out, upper)
+
+		Expect(processed).To(BeAssignableToTypeOf(&processedOutput{}))
+		Expect(processed.(Checker).Check(context.Background())).To(MatchError("unreachable"))
+		Expect(processed.(StatusReporter).Status()).To(Equal(out.status))
+	})
+
+	It("should neither check nor report a status if the wrapped output does not support it", func() {
+		processed := WithProcessors(&fakeOutput{}, upper)
+
+		Expect(processed.(Checker).Check(context.Background())).To(Succeed())
+		Expect(processed.(StatusReporter).Status()).To(Equal(Status{}))
+	})
+})
+
+type fakeOutput struct {
+	ctx  context.Context
+	sent []byte
+}
+
+func (o *fakeOutput) Send(ctx context.Context, data []byte) error {
+	o.ctx, o.sent = ctx, data
+	return nil
+}
+
+func (o *fakeOutput) Name() string { return "fake" }
+
+func (o *fakeOutput) Close() error { return nil }
+
+type fakeCheckedOutput struct {
+	fakeOutput
+	err    error
+	status Status
+}
+
+func (o *fakeCheckedOutput) Check(context.Context) error { return o.err }
+
+func (o *fakeCheckedOutput) Status() Status { return o.status }
+
+type fakeProcessor struct {
+	process func([]byte) []byte
+	err     error
+	calls   atomic.Int32
+}
+
+func (p *fakeProcessor) Process(_ context.Context, data []byte) ([]byte, error) {
+	p.calls.Add(1)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.process(data), nil
+}
+
+func (p *fakeProcessor) Name() string { return "fake" }
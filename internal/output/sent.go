@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"context"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+)
+
+type sentPayloadKey struct{}
+
+// sentPayload is the payload recorded by an [Output] which sends other data than it received.
+type sentPayload struct {
+	payload  *encoding.Payload
+	recorded bool
+}
+
+// WithSentPayload returns a copy of ctx in which an [Output] processing the data before sending it records the
+// payload it sent, and a function returning the recorded payload. The payload is nil if nothing was sent, and
+// the function reports false if nothing was recorded, i.e. the received data was sent.
+// The recorder is not synchronized, it must only be used for a single Send call.
+func WithSentPayload(ctx context.Context) (context.Context, func() (*encoding.Payload, bool)) {
+	sent := &sentPayload{}
+	return context.WithValue(ctx, sentPayloadKey{}, sent), func() (*encoding.Payload, bool) { return sent.payload, sent.recorded }
+}
+
+// RecordSentPayload records the sent payload in the recorder of ctx, if any. It is nil if nothing was sent.
+func RecordSentPayload(ctx context.Context, payload *encoding.Payload) {
+	if sent, ok := ctx.Value(sentPayloadKey{}).(*sentPayload); ok {
+		sent.payload = payload
+		sent.recorded = true
+	}
+}
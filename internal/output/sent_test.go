@@ -0,0 +1,32 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/auditlog-forwarder/internal/encoding"
+)
+
+var _ = Describe("SentPayload", func() {
+	It("should return the recorded payload", func() {
+		ctx, sent := WithSentPayload(context.Background())
+		_, recorded := sent()
+		Expect(recorded).To(BeFalse())
+
+		payload := encoding.NewPayload([]byte(`{}`))
+		RecordSentPayload(ctx, payload)
+		recordedPayload, recorded := sent()
+		Expect(recorded).To(BeTrue())
+		Expect(recordedPayload).To(BeIdenticalTo(payload))
+	})
+
+	It("should ignore payloads without a recorder", func() {
+		Expect(func() { RecordSentPayload(context.Background(), nil) }).NotTo(Panic())
+	})
+})
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package output
+
+import (
+	"context"
+	"time"
+)
+
+// CircuitState is the state of the circuit of an output, i.e. whether its endpoints receive requests.
+type CircuitState string
+
+const (
+	// CircuitClosed means that all endpoints of the output receive requests.
+	CircuitClosed CircuitState = "Closed"
+	// CircuitPartiallyOpen means that some endpoints of the output are ejected because of consecutive failures.
+	CircuitPartiallyOpen CircuitState = "PartiallyOpen"
+	// CircuitOpen means that all endpoints of the output are ejected because of consecutive failures.
+	CircuitOpen CircuitState = "Open"
+)
+
+// Status is the status reported by an output itself.
+type Status struct {
+	// Circuit is the state of the circuit of the output.
+	Circuit CircuitState
+	// CertificateExpiry is the expiry of the client certificate of the output, nil if it has none.
+	CertificateExpiry *time.Time
+}
+
+// StatusReporter is implemented by outputs which report their status.
+type StatusReporter interface {
+	// Status returns the current status of the output.
+	Status() Status
+}
+
+// Checker is implemented by outputs which can actively check whether they are reachable.
+type Checker interface {
+	// Check returns an error if the output is not reachable.
+	Check(ctx context.Context) error
+}
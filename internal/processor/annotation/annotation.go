@@ -6,7 +6,6 @@ package annotation
 
 import (
 	"context"
-	"maps"
 
 	"github.com/gardener/auditlog-forwarder/internal/helper"
 	"github.com/gardener/auditlog-forwarder/internal/processor"
@@ -17,12 +16,15 @@ var _ processor.Processor = (*Injector)(nil)
 // Injector implements Processor and injects annotations into audit events.
 type Injector struct {
 	annotations map[string]string
+	overwrite   bool
 }
 
 // New creates a new annotation Injector with the given annotations.
-func New(annotations map[string]string) *Injector {
+// Annotations with the same keys which are already set are only overwritten if overwrite is true.
+func New(annotations map[string]string, overwrite bool) *Injector {
 	return &Injector{
 		annotations: annotations,
+		overwrite:   overwrite,
 	}
 }
 
@@ -41,7 +43,12 @@ func (a *Injector) Process(_ context.Context, data []byte) ([]byte, error) {
 		if eventList.Items[i].Annotations == nil {
 			eventList.Items[i].Annotations = make(map[string]string)
 		}
-		maps.Insert(eventList.Items[i].Annotations, maps.All(a.annotations))
+		for key, value := range a.annotations {
+			if _, ok := eventList.Items[i].Annotations[key]; ok && !a.overwrite {
+				continue
+			}
+			eventList.Items[i].Annotations[key] = value
+		}
 	}
 
 	return helper.EncodeEventList(eventList)
@@ -28,7 +28,7 @@ var _ = Describe("Injector", func() {
 			"another-key": "another-value",
 		}
 		ctx = context.Background()
-		injector = New(annotations)
+		injector = New(annotations, false)
 	})
 
 	Describe("Process", func() {
@@ -76,8 +76,48 @@ var _ = Describe("Injector", func() {
 			Expect(processedEventList.Items[1].Annotations).To(HaveKeyWithValue("another-key", "another-value"))
 		})
 
+		Context("with annotations which are already set", func() {
+			var inputData []byte
+
+			BeforeEach(func() {
+				var err error
+				inputData, err = helper.EncodeEventList(&audit.EventList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+					Items: []audit.Event{{
+						Verb:        "create",
+						Annotations: map[string]string{"test-key": "apiserver-value"},
+					}},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should keep the existing annotations by default", func() {
+				processedData, err := injector.Process(ctx, inputData)
+				Expect(err).NotTo(HaveOccurred())
+
+				processedEventList, err := helper.DecodeEventList(processedData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(processedEventList.Items[0].Annotations).To(Equal(map[string]string{
+					"test-key":    "apiserver-value",
+					"another-key": "another-value",
+				}))
+			})
+
+			It("should overwrite the existing annotations if configured", func() {
+				processedData, err := New(annotations, true).Process(ctx, inputData)
+				Expect(err).NotTo(HaveOccurred())
+
+				processedEventList, err := helper.DecodeEventList(processedData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(processedEventList.Items[0].Annotations).To(Equal(map[string]string{
+					"test-key":    "test-value",
+					"another-key": "another-value",
+				}))
+			})
+		})
+
 		It("should handle empty annotations", func() {
-			emptyInjector := New(map[string]string{})
+			emptyInjector := New(map[string]string{}, false)
 
 			eventList := &audit.EventList{
 				TypeMeta: metav1.TypeMeta{
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package annotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+	"github.com/gardener/auditlog-forwarder/pkg/expression"
+)
+
+var _ processor.Processor = (*ComputedInjector)(nil)
+
+// ComputedInjector implements Processor and injects annotations whose values are sourced dynamically or
+// computed per audit event.
+type ComputedInjector struct {
+	logger      logr.Logger
+	annotations []computedAnnotation
+	files       []*fileValue
+}
+
+// computedAnnotation is an annotation with the source of its value.
+type computedAnnotation struct {
+	key       string
+	source    valueSource
+	overwrite bool
+}
+
+// valueSource provides the value of an annotation for an audit event.
+type valueSource interface {
+	value(event *audit.Event) (string, error)
+}
+
+// staticValue is a value which is the same for all audit events, e.g. from an environment variable.
+type staticValue string
+
+func (v staticValue) value(*audit.Event) (string, error) {
+	return string(v), nil
+}
+
+// templateValue is a value computed by a Go template.
+type templateValue struct {
+	template *expression.Template
+}
+
+func (v templateValue) value(event *audit.Event) (string, error) {
+	return v.template.Execute(event)
+}
+
+// expressionValue is a value computed by a CEL expression.
+type expressionValue struct {
+	program *expression.Program
+}
+
+func (v expressionValue) value(event *audit.Event) (string, error) {
+	return v.program.EvalString(event)
+}
+
+// NewComputed creates a new ComputedInjector with the given annotations.
+// Environment variables are read and files are loaded once, the files can be reloaded with Reload or Watch.
+func NewComputed(logger logr.Logger, annotations []configv1alpha1.ComputedAnnotation) (*ComputedInjector, error) {
+	injector := &ComputedInjector{
+		logger:      logger,
+		annotations: make([]computedAnnotation, 0, len(annotations)),
+	}
+	for _, annotation := range annotations {
+		source, err := injector.newSource(&annotation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create value of annotation %s: %w", annotation.Key, err)
+		}
+		injector.annotations = append(injector.annotations, computedAnnotation{
+			key:       annotation.Key,
+			source:    source,
+			overwrite: annotation.Overwrite,
+		})
+	}
+	return injector, nil
+}
+
+// newSource creates the source of the value of the annotation.
+func (c *ComputedInjector) newSource(annotation *configv1alpha1.ComputedAnnotation) (valueSource, error) {
+	switch {
+	case annotation.Env != "":
+		value, ok := os.LookupEnv(annotation.Env)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", annotation.Env)
+		}
+		return staticValue(value), nil
+	case annotation.File != nil:
+		file := &fileValue{path: annotation.File.Path, key: annotation.File.Key}
+		if err := file.reload(); err != nil {
+			return nil, err
+		}
+		c.files = append(c.files, file)
+		return file, nil
+	case annotation.Template != "":
+		tmpl, err := expression.ParseTemplate(annotation.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+		return templateValue{template: tmpl}, nil
+	case annotation.Expression != "":
+		program, err := expression.CompileString(annotation.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile expression: %w", err)
+		}
+		return expressionValue{program: program}, nil
+	default:
+		return staticValue(annotation.Value), nil
+	}
+}
+
+// Reload reloads the values of all files. The values of files which cannot be loaded are kept.
+func (c *ComputedInjector) Reload() error {
+	var errs []error
+	for _, file := range c.files {
+		if err := file.reload(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Process computes the annotation values for each audit event and injects the non-empty ones.
+// Existing annotations are only overwritten if the overwrite policy of the key allows it.
+// Annotations whose value cannot be computed are not injected.
+func (c *ComputedInjector) Process(_ context.Context, data []byte) ([]byte, error) {
+	if len(c.annotations) == 0 {
+		return data, nil
+	}
+
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range eventList.Items {
+		event := &eventList.Items[i]
+		// The values are computed before any of them is injected, so that all of them see the same event.
+		values := make(map[string]string, len(c.annotations))
+		for _, annotation := range c.annotations {
+			if _, exists := event.Annotations[annotation.key]; exists && !annotation.overwrite {
+				continue
+			}
+			value, err := annotation.source.value(event)
+			if err != nil {
+				c.logger.Error(err, "Failed to compute annotation value", "key", annotation.key, "auditID", event.AuditID)
+				continue
+			}
+			if value != "" {
+				values[annotation.key] = value
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if event.Annotations == nil {
+			event.Annotations = make(map[string]string, len(values))
+		}
+		maps.Copy(event.Annotations, values)
+	}
+
+	return helper.EncodeEventList(eventList)
+}
+
+// Name returns the name of the processor.
+func (c *ComputedInjector) Name() string {
+	return "audit-event-computed-annotation-injector"
+}
@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package annotation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authnv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("ComputedInjector", func() {
+	var (
+		injector *ComputedInjector
+		ctx      context.Context
+	)
+
+	process := func(events ...audit.Event) []audit.Event {
+		data, err := helper.EncodeEventList(&audit.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+			Items:    events,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		processed, err := injector.Process(ctx, data)
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		return eventList.Items
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		injector, err = NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{
+			{Key: "example.com/user-type", Expression: `event.user.username.startsWith("system:") ? "system" : "user"`},
+			{Key: "example.com/resource", Expression: `event.objectRef.resource`},
+			{Key: "example.com/team", Expression: `event.annotations["team"]`},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should inject the computed annotations into audit events", func() {
+		events := process(
+			audit.Event{
+				Verb:      "create",
+				User:      authnv1.UserInfo{Username: "system:kube-scheduler"},
+				ObjectRef: &audit.ObjectReference{Resource: "pods"},
+			},
+			audit.Event{
+				Verb:        "get",
+				User:        authnv1.UserInfo{Username: "alice"},
+				Annotations: map[string]string{"team": "a"},
+			},
+		)
+
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Annotations).To(Equal(map[string]string{
+			"example.com/user-type": "system",
+			"example.com/resource":  "pods",
+		}))
+		Expect(events[1].Annotations).To(Equal(map[string]string{
+			"team":                  "a",
+			"example.com/user-type": "user",
+			"example.com/team":      "a",
+		}))
+	})
+
+	It("should inject static values, environment variables and templates", func() {
+		GinkgoT().Setenv("TEST_REGION", "eu-1")
+
+		var err error
+		injector, err = NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{
+			{Key: "example.com/cluster", Value: "prod"},
+			{Key: "example.com/region", Env: "TEST_REGION"},
+			{Key: "example.com/target", Template: `{{ .ObjectRef.Namespace }}/{{ .ObjectRef.Name }}`},
+			{Key: "example.com/team", Template: `{{ index .Annotations "team" }}`},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		events := process(audit.Event{
+			Verb:      "get",
+			ObjectRef: &audit.ObjectReference{Resource: "secrets", Namespace: "default", Name: "token"},
+		})
+		Expect(events[0].Annotations).To(Equal(map[string]string{
+			"example.com/cluster": "prod",
+			"example.com/region":  "eu-1",
+			"example.com/target":  "default/token",
+		}))
+	})
+
+	It("should only overwrite existing annotations if the policy allows it", func() {
+		var err error
+		injector, err = NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{
+			{Key: "authorization.k8s.io/decision", Value: "forged"},
+			{Key: "example.com/verb", Template: `{{ .Verb }}`, Overwrite: true},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		events := process(audit.Event{
+			Verb: "get",
+			Annotations: map[string]string{
+				"authorization.k8s.io/decision": "allow",
+				"example.com/verb":              "unknown",
+			},
+		})
+		Expect(events[0].Annotations).To(Equal(map[string]string{
+			"authorization.k8s.io/decision": "allow",
+			"example.com/verb":              "get",
+		}))
+	})
+
+	Context("files", func() {
+		var (
+			dir        string
+			labelsFile string
+			ownerFile  string
+		)
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			labelsFile = filepath.Join(dir, "labels")
+			ownerFile = filepath.Join(dir, "owner")
+			Expect(os.WriteFile(labelsFile, []byte("cost-center=\"1234\"\nowner=\"team \\\"a\\\"\"\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(ownerFile, []byte("alice\n"), 0600)).To(Succeed())
+
+			var err error
+			injector, err = NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{
+				{Key: "example.com/cost-center", File: &configv1alpha1.AnnotationFile{Path: labelsFile, Key: "cost-center"}},
+				{Key: "example.com/team", File: &configv1alpha1.AnnotationFile{Path: labelsFile, Key: "owner"}},
+				{Key: "example.com/missing", File: &configv1alpha1.AnnotationFile{Path: labelsFile, Key: "missing"}},
+				{Key: "example.com/owner", File: &configv1alpha1.AnnotationFile{Path: ownerFile}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should inject the values of the files", func() {
+			Expect(process(audit.Event{})[0].Annotations).To(Equal(map[string]string{
+				"example.com/cost-center": "1234",
+				"example.com/team":        `team "a"`,
+				"example.com/owner":       "alice",
+			}))
+		})
+
+		It("should keep the values of files which cannot be reloaded", func() {
+			Expect(os.WriteFile(labelsFile, []byte("invalid"), 0600)).To(Succeed())
+			Expect(os.WriteFile(ownerFile, []byte("bob"), 0600)).To(Succeed())
+
+			Expect(injector.Reload()).To(MatchError(ContainSubstring("line 1 is not of the form")))
+			Expect(process(audit.Event{})[0].Annotations).To(Equal(map[string]string{
+				"example.com/cost-center": "1234",
+				"example.com/team":        `team "a"`,
+				"example.com/owner":       "bob",
+			}))
+		})
+
+		It("should reload the values when the files change", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Expect(injector.Watch(ctx, 10*time.Millisecond)).To(Succeed())
+
+			Expect(os.WriteFile(labelsFile, []byte(`cost-center="5678"`), 0600)).To(Succeed())
+
+			Eventually(func() map[string]string { return process(audit.Event{})[0].Annotations }).Should(Equal(map[string]string{
+				"example.com/cost-center": "5678",
+				"example.com/owner":       "alice",
+			}))
+		})
+
+		It("should fail if a file cannot be read", func() {
+			_, err := NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{
+				{Key: "key", File: &configv1alpha1.AnnotationFile{Path: filepath.Join(dir, "missing")}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("failed to read annotation file")))
+		})
+	})
+
+	It("should fail if an environment variable is not set", func() {
+		_, err := NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{{Key: "key", Env: "TEST_UNSET_VARIABLE"}})
+		Expect(err).To(MatchError("failed to create value of annotation key: environment variable TEST_UNSET_VARIABLE is not set"))
+	})
+
+	It("should fail for invalid templates", func() {
+		_, err := NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{{Key: "key", Template: `{{ .Unknown }}`}})
+		Expect(err).To(MatchError(ContainSubstring("failed to parse template")))
+	})
+
+	It("should fail for invalid expressions", func() {
+		_, err := NewComputed(logr.Discard(), []configv1alpha1.ComputedAnnotation{{Key: "key", Expression: `event.verb == "get"`}})
+		Expect(err).To(MatchError(ContainSubstring("failed to compile expression")))
+	})
+
+	It("should return the data unchanged without annotations", func() {
+		var err error
+		injector, err = NewComputed(logr.Discard(), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		data := []byte("invalid")
+		Expect(injector.Process(ctx, data)).To(Equal(data))
+	})
+
+	It("should return the name", func() {
+		Expect(injector.Name()).To(Equal("audit-event-computed-annotation-injector"))
+	})
+})
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package annotation
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+// fileValue is a value loaded from a file.
+type fileValue struct {
+	path string
+	// key is the key of the value in a file of `key="value"` lines, empty if the whole file is the value.
+	key     string
+	current atomic.Pointer[string]
+}
+
+func (v *fileValue) value(*audit.Event) (string, error) {
+	return *v.current.Load(), nil
+}
+
+// reload loads the value from the file. The value is empty if the key is not contained in the file.
+func (v *fileValue) reload() error {
+	data, err := os.ReadFile(filepath.Clean(v.path))
+	if err != nil {
+		return fmt.Errorf("failed to read annotation file %s: %w", v.path, err)
+	}
+
+	var value string
+	if v.key == "" {
+		value = string(bytes.TrimSpace(data))
+	} else if value, err = lookupKey(data, v.key); err != nil {
+		return fmt.Errorf("failed to parse annotation file %s: %w", v.path, err)
+	}
+	v.current.Store(&value)
+	return nil
+}
+
+// lookupKey returns the value of the key in the data, which contains one `key="value"` line per key
+// like the labels or annotations of a downward API volume.
+func lookupKey(data []byte, key string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		k, quoted, ok := strings.Cut(text, "=")
+		if !ok {
+			return "", fmt.Errorf("line %d is not of the form key=\"value\"", line)
+		}
+		if k != key {
+			continue
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return "", fmt.Errorf("value of line %d is not quoted: %w", line, err)
+		}
+		return value, nil
+	}
+	return "", scanner.Err()
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package annotation
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/auditlog-forwarder/internal/filewatch"
+)
+
+// Watch reloads the values of the files when they change until ctx is done.
+// Filesystem events are coalesced for the debounce duration.
+func (c *ComputedInjector) Watch(ctx context.Context, debounce time.Duration) error {
+	paths := make([]string, 0, len(c.files))
+	for _, file := range c.files {
+		paths = append(paths, file.path)
+	}
+	_, err := filewatch.Watch(ctx, c.logger, paths, debounce, func() {
+		if err := c.Reload(); err != nil {
+			c.logger.Error(err, "Failed to reload annotation files, keeping existing values")
+			return
+		}
+		c.logger.Info("Reloaded annotation files")
+	})
+	return err
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dedup implements the dropping of audit events which were already forwarded.
+package dedup
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var (
+	_ processor.Processor = (*Deduplicator)(nil)
+	_ processor.Committer = (*Deduplicator)(nil)
+)
+
+// Deduplicator implements Processor and drops the audit events which were already forwarded.
+// Events are identified by their audit ID and stage; events without audit ID are never dropped.
+type Deduplicator struct {
+	ttl       time.Duration
+	forwarded *cache.LRUExpireCache
+}
+
+// key identifies an audit event.
+type key struct {
+	auditID string
+	stage   audit.Stage
+}
+
+// New creates a new Deduplicator with the given configuration.
+func New(config *configv1alpha1.Deduplication) *Deduplicator {
+	return &Deduplicator{
+		ttl:       config.TTL.Duration,
+		forwarded: cache.NewLRUExpireCache(int(config.CacheSize)),
+	}
+}
+
+// Process drops the audit events which were already forwarded as well as repeated events within the data.
+func (d *Deduplicator) Process(_ context.Context, data []byte) ([]byte, error) {
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := sets.New[key]()
+	kept := eventList.Items[:0]
+	for _, event := range eventList.Items {
+		if event.AuditID == "" {
+			kept = append(kept, event)
+			continue
+		}
+		k := key{auditID: string(event.AuditID), stage: event.Stage}
+		if _, forwarded := d.forwarded.Get(k); forwarded || seen.Has(k) {
+			metrics.DuplicateEvents.Inc()
+			continue
+		}
+		seen.Insert(k)
+		kept = append(kept, event)
+	}
+	if len(kept) == len(eventList.Items) {
+		return data, nil
+	}
+	eventList.Items = kept
+
+	return helper.EncodeEventList(eventList)
+}
+
+// Commit remembers the audit events of the data as forwarded.
+// Data which cannot be decoded is ignored, as it was not produced by Process.
+func (d *Deduplicator) Commit(_ context.Context, data []byte) {
+	var eventList struct {
+		Items []struct {
+			AuditID string      `json:"auditID"`
+			Stage   audit.Stage `json:"stage"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &eventList); err != nil {
+		return
+	}
+	for _, event := range eventList.Items {
+		if event.AuditID != "" {
+			d.forwarded.Add(key{auditID: event.AuditID, stage: event.Stage}, struct{}{}, d.ttl)
+		}
+	}
+}
+
+// Name returns the name of the processor.
+func (d *Deduplicator) Name() string {
+	return "audit-event-deduplicator"
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dedup_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	testclock "k8s.io/utils/clock/testing"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	"github.com/gardener/auditlog-forwarder/internal/processor/dedup"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Deduplicator", func() {
+	var (
+		ctx          = context.Background()
+		clock        *testclock.FakeClock
+		deduplicator *dedup.Deduplicator
+		// duplicatesBefore is the number of dropped duplicates before the test.
+		duplicatesBefore float64
+	)
+
+	event := func(auditID string, stage auditinternal.Stage) auditinternal.Event {
+		return auditinternal.Event{AuditID: types.UID(auditID), Stage: stage, Verb: "get", RequestURI: "/api"}
+	}
+
+	encode := func(events ...auditinternal.Event) []byte {
+		data, err := helper.EncodeEventList(&auditinternal.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+			Items:    events,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	process := func(data []byte) []auditinternal.Event {
+		processed, err := deduplicator.Process(ctx, data)
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		return eventList.Items
+	}
+
+	// duplicates returns the number of duplicates dropped by the test.
+	duplicates := func() float64 {
+		return testutil.ToFloat64(metrics.DuplicateEvents) - duplicatesBefore
+	}
+
+	BeforeEach(func() {
+		duplicatesBefore = testutil.ToFloat64(metrics.DuplicateEvents)
+
+		clock = testclock.NewFakeClock(time.Now())
+		deduplicator = dedup.NewWithClock(&configv1alpha1.Deduplication{
+			CacheSize: 2,
+			TTL:       &metav1.Duration{Duration: time.Minute},
+		}, clock)
+	})
+
+	It("should drop events which were already forwarded", func() {
+		data := encode(event("1", auditinternal.StageRequestReceived), event("2", auditinternal.StageResponseComplete))
+		Expect(process(data)).To(HaveLen(2))
+		deduplicator.Commit(ctx, data)
+
+		events := process(encode(
+			event("1", auditinternal.StageRequestReceived),
+			event("1", auditinternal.StageResponseComplete),
+			event("2", auditinternal.StageResponseComplete),
+		))
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].AuditID).To(BeEquivalentTo("1"))
+		Expect(events[0].Stage).To(Equal(auditinternal.StageResponseComplete))
+		Expect(duplicates()).To(Equal(2.0))
+	})
+
+	It("should not drop events of requests which were not forwarded", func() {
+		data := encode(event("1", auditinternal.StageResponseComplete))
+		Expect(process(data)).To(HaveLen(1))
+
+		processed, err := deduplicator.Process(ctx, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(Equal(data))
+	})
+
+	It("should drop repeated events within the data", func() {
+		events := process(encode(event("1", auditinternal.StageResponseComplete), event("1", auditinternal.StageResponseComplete)))
+		Expect(events).To(HaveLen(1))
+		Expect(duplicates()).To(Equal(1.0))
+	})
+
+	It("should never drop events without audit ID", func() {
+		data := encode(event("", auditinternal.StageResponseComplete), event("", auditinternal.StageResponseComplete))
+		deduplicator.Commit(ctx, data)
+		Expect(process(data)).To(HaveLen(2))
+	})
+
+	It("should forget forwarded events after the TTL", func() {
+		data := encode(event("1", auditinternal.StageResponseComplete))
+		deduplicator.Commit(ctx, data)
+		clock.Step(59 * time.Second)
+		Expect(process(data)).To(BeEmpty())
+
+		clock.Step(2 * time.Second)
+		Expect(process(data)).To(HaveLen(1))
+	})
+
+	It("should forget the least recently forwarded events when the cache is full", func() {
+		deduplicator.Commit(ctx, encode(event("1", auditinternal.StageResponseComplete)))
+		deduplicator.Commit(ctx, encode(event("2", auditinternal.StageResponseComplete), event("3", auditinternal.StageResponseComplete)))
+
+		events := process(encode(
+			event("1", auditinternal.StageResponseComplete),
+			event("2", auditinternal.StageResponseComplete),
+			event("3", auditinternal.StageResponseComplete),
+		))
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].AuditID).To(BeEquivalentTo("1"))
+	})
+
+	It("should ignore data which cannot be decoded on commit", func() {
+		deduplicator.Commit(ctx, []byte("invalid"))
+	})
+
+	It("should return an error for invalid data", func() {
+		_, err := deduplicator.Process(ctx, []byte("invalid"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dedup
+
+import (
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// NewWithClock creates a new Deduplicator whose cache expires with the given clock.
+func NewWithClock(config *configv1alpha1.Deduplication, clock cache.Clock) *Deduplicator {
+	return &Deduplicator{
+		ttl:       config.TTL.Duration,
+		forwarded: cache.NewLRUExpireCacheWithClock(int(config.CacheSize), clock),
+	}
+}
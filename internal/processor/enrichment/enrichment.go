@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package enrichment implements the enrichment of audit events with metadata of Kubernetes objects.
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ processor.Processor = (*Enricher)(nil)
+
+var namespacesResource = corev1.SchemeGroupVersion.WithResource("namespaces")
+
+// Enricher implements Processor and injects labels and annotations of the namespaces of audit events into them.
+// Only the metadata of the namespaces is cached.
+type Enricher struct {
+	logger     logr.Logger
+	namespaces configv1alpha1.MetadataEnrichment
+	factory    metadatainformer.SharedInformerFactory
+	informer   cache.SharedIndexInformer
+	lister     cache.GenericLister
+}
+
+// New creates a new Enricher with the given configuration, which caches the namespaces with the given client.
+func New(logger logr.Logger, config *configv1alpha1.Enrichment, client metadata.Interface) *Enricher {
+	// Only the labels and annotations are needed, the managed fields are dropped to save memory.
+	factory := metadatainformer.NewSharedInformerFactoryWithOptions(client, 0, metadatainformer.WithTransform(stripManagedFields))
+	namespaces := factory.ForResource(namespacesResource)
+	return &Enricher{
+		logger:     logger,
+		namespaces: config.Namespaces,
+		factory:    factory,
+		informer:   namespaces.Informer(),
+		lister:     namespaces.Lister(),
+	}
+}
+
+// Start starts the informers until ctx is done and waits until their caches are synced or waitCtx is done.
+func (e *Enricher) Start(ctx, waitCtx context.Context) error {
+	e.factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(waitCtx.Done(), e.informer.HasSynced) {
+		return errors.New("failed to sync namespace cache")
+	}
+	return nil
+}
+
+// Process injects the labels and annotations of the namespaces of the audit events into them.
+// Existing annotations are not overwritten, events without namespace or whose namespace is not cached are kept unchanged.
+func (e *Enricher) Process(_ context.Context, data []byte) ([]byte, error) {
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	for i := range eventList.Items {
+		event := &eventList.Items[i]
+		name := namespaceOf(event)
+		if name == "" {
+			continue
+		}
+		namespace, err := e.namespace(name)
+		if err != nil {
+			e.logger.Error(err, "Failed to get namespace from cache", "namespace", name, "auditID", event.AuditID)
+			continue
+		}
+		if namespace == nil {
+			continue
+		}
+		if inject(event, e.namespaces.Labels, namespace.Labels) {
+			changed = true
+		}
+		if inject(event, e.namespaces.Annotations, namespace.Annotations) {
+			changed = true
+		}
+	}
+	if !changed {
+		return data, nil
+	}
+
+	return helper.EncodeEventList(eventList)
+}
+
+// Name returns the name of the processor.
+func (e *Enricher) Name() string {
+	return "audit-event-enricher"
+}
+
+// namespace returns the cached metadata of the namespace, nil if it is not cached.
+func (e *Enricher) namespace(name string) (*metav1.PartialObjectMetadata, error) {
+	obj, err := e.lister.Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	namespace, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object of type %T in namespace cache", obj)
+	}
+	return namespace, nil
+}
+
+// namespaceOf returns the namespace of the object of the audit event, or its name for namespaces.
+func namespaceOf(event *audit.Event) string {
+	ref := event.ObjectRef
+	if ref == nil {
+		return ""
+	}
+	if ref.Namespace == "" && ref.APIGroup == "" && ref.Resource == "namespaces" {
+		return ref.Name
+	}
+	return ref.Namespace
+}
+
+// inject injects the values of the mapped keys into the annotations of the audit event, unless they are already set.
+// It returns whether an annotation was injected.
+func inject(event *audit.Event, mapping, values map[string]string) bool {
+	injected := false
+	for key, annotation := range mapping {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		if _, exists := event.Annotations[annotation]; exists {
+			continue
+		}
+		if event.Annotations == nil {
+			event.Annotations = make(map[string]string, len(mapping))
+		}
+		event.Annotations[annotation] = value
+		injected = true
+	}
+	return injected
+}
+
+// stripManagedFields drops the managed fields of cached objects.
+func stripManagedFields(obj any) (any, error) {
+	if accessor, ok := obj.(metav1.ObjectMetaAccessor); ok {
+		accessor.GetObjectMeta().SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+// NewClient creates a client for the metadata of the objects of the cluster of the kubeconfig.
+// The in-cluster configuration is used if kubeconfig is empty.
+func NewClient(kubeconfig string) (metadata.Interface, error) {
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+	if kubeconfig == "" {
+		restConfig, err = rest.InClusterConfig()
+	} else {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client configuration: %w", err)
+	}
+	return metadata.NewForConfig(restConfig)
+}
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package enrichment_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor/enrichment"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Enricher", func() {
+	var (
+		ctx      context.Context
+		client   *metadatafake.FakeMetadataClient
+		enricher *enrichment.Enricher
+	)
+
+	namespace := func(name string, labels, annotations map[string]string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+		}
+	}
+
+	event := func(auditID string, ref *auditinternal.ObjectReference) auditinternal.Event {
+		return auditinternal.Event{
+			AuditID:   types.UID(auditID),
+			Stage:     auditinternal.StageResponseComplete,
+			Verb:      "get",
+			ObjectRef: ref,
+		}
+	}
+
+	encode := func(events ...auditinternal.Event) []byte {
+		data, err := helper.EncodeEventList(&auditinternal.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+			Items:    events,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	process := func(events ...auditinternal.Event) []auditinternal.Event {
+		processed, err := enricher.Process(ctx, encode(events...))
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		return eventList.Items
+	}
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+
+		scheme := metadatafake.NewTestScheme()
+		Expect(metav1.AddMetaToScheme(scheme)).To(Succeed())
+		client = metadatafake.NewSimpleMetadataClient(scheme,
+			namespace("team-a", map[string]string{"example.com/owner": "alice", "example.com/cost-center": "1234"}, map[string]string{"example.com/contact": "alice@example.com"}),
+			namespace("team-b", map[string]string{"example.com/owner": "bob"}, nil),
+		)
+
+		enricher = enrichment.New(logr.Discard(), &configv1alpha1.Enrichment{
+			Namespaces: configv1alpha1.MetadataEnrichment{
+				Labels: map[string]string{
+					"example.com/owner":       "namespace.example.com/owner",
+					"example.com/cost-center": "namespace.example.com/cost-center",
+				},
+				Annotations: map[string]string{
+					"example.com/contact": "namespace.example.com/contact",
+				},
+			},
+		}, client)
+
+		waitCtx, cancelWait := context.WithTimeout(ctx, 10*time.Second)
+		defer cancelWait()
+		Expect(enricher.Start(ctx, waitCtx)).To(Succeed())
+	})
+
+	It("should inject the labels and annotations of the namespaces", func() {
+		events := process(
+			event("1", &auditinternal.ObjectReference{Resource: "pods", Namespace: "team-a", Name: "web"}),
+			event("2", &auditinternal.ObjectReference{Resource: "secrets", Namespace: "team-b", Name: "token"}),
+		)
+
+		Expect(events[0].Annotations).To(Equal(map[string]string{
+			"namespace.example.com/owner":       "alice",
+			"namespace.example.com/cost-center": "1234",
+			"namespace.example.com/contact":     "alice@example.com",
+		}))
+		Expect(events[1].Annotations).To(Equal(map[string]string{
+			"namespace.example.com/owner": "bob",
+		}))
+	})
+
+	It("should enrich events of namespaces with their own metadata", func() {
+		events := process(event("1", &auditinternal.ObjectReference{Resource: "namespaces", Name: "team-b"}))
+
+		Expect(events[0].Annotations).To(HaveKeyWithValue("namespace.example.com/owner", "bob"))
+	})
+
+	It("should not overwrite existing annotations", func() {
+		e := event("1", &auditinternal.ObjectReference{Resource: "pods", Namespace: "team-b"})
+		e.Annotations = map[string]string{"namespace.example.com/owner": "mallory"}
+
+		Expect(process(e)[0].Annotations).To(Equal(map[string]string{"namespace.example.com/owner": "mallory"}))
+	})
+
+	It("should return the data unchanged for events without cached namespace", func() {
+		data := encode(
+			event("1", nil),
+			event("2", &auditinternal.ObjectReference{Resource: "nodes", Name: "node-1"}),
+			event("3", &auditinternal.ObjectReference{Resource: "pods", Namespace: "unknown"}),
+		)
+
+		processed, err := enricher.Process(ctx, data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(Equal(data))
+	})
+
+	It("should pick up changes of the namespaces", func() {
+		namespaces := client.Resource(corev1.SchemeGroupVersion.WithResource("namespaces")).(metadatafake.MetadataClient)
+		_, err := namespaces.UpdateFake(namespace("team-b", map[string]string{"example.com/owner": "carol"}, nil), metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = namespaces.CreateFake(namespace("team-c", map[string]string{"example.com/cost-center": "5678"}, nil), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() []auditinternal.Event {
+			return process(
+				event("1", &auditinternal.ObjectReference{Resource: "pods", Namespace: "team-b"}),
+				event("2", &auditinternal.ObjectReference{Resource: "pods", Namespace: "team-c"}),
+			)
+		}).Should(HaveExactElements(
+			HaveField("Annotations", Equal(map[string]string{"namespace.example.com/owner": "carol"})),
+			HaveField("Annotations", Equal(map[string]string{"namespace.example.com/cost-center": "5678"})),
+		))
+	})
+
+	It("should fail for invalid data", func() {
+		_, err := enricher.Process(ctx, []byte("invalid"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return the name", func() {
+		Expect(enricher.Name()).To(Equal("audit-event-enricher"))
+	})
+})
+
+var _ = Describe("Start", func() {
+	It("should fail if the cache cannot be synced", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		waitCtx, cancelWait := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancelWait()
+
+		scheme := metadatafake.NewTestScheme()
+		Expect(metav1.AddMetaToScheme(scheme)).To(Succeed())
+		client := metadatafake.NewSimpleMetadataClient(scheme)
+		client.PrependReactor("list", "namespaces", func(clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(corev1.Resource("namespaces"), "", errors.New("no access"))
+		})
+		enricher := enrichment.New(logr.Discard(), &configv1alpha1.Enrichment{}, client)
+
+		Expect(enricher.Start(ctx, waitCtx)).To(MatchError("failed to sync namespace cache"))
+	})
+})
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package filter implements the filtering of audit events by CEL expressions.
+package filter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+	"github.com/gardener/auditlog-forwarder/pkg/expression"
+)
+
+var _ processor.Processor = (*Filter)(nil)
+
+// Filter implements Processor and drops the audit events for which the expression evaluates to false.
+type Filter struct {
+	logger  logr.Logger
+	program *expression.Program
+}
+
+// New creates a new Filter with the given configuration.
+func New(logger logr.Logger, config *configv1alpha1.Filter) (*Filter, error) {
+	program, err := expression.CompileBool(config.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile filter expression: %w", err)
+	}
+	return &Filter{
+		logger:  logger,
+		program: program,
+	}, nil
+}
+
+// Process drops the audit events for which the expression evaluates to false.
+// Events for which the expression cannot be evaluated are kept.
+func (f *Filter) Process(_ context.Context, data []byte) ([]byte, error) {
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := eventList.Items[:0]
+	for _, event := range eventList.Items {
+		keep, err := f.program.EvalBool(&event)
+		if err != nil {
+			f.logger.Error(err, "Failed to evaluate filter expression, keeping audit event", "auditID", event.AuditID)
+			keep = true
+		}
+		if keep {
+			kept = append(kept, event)
+		}
+	}
+	if len(kept) == len(eventList.Items) {
+		return data, nil
+	}
+	eventList.Items = kept
+
+	return helper.EncodeEventList(eventList)
+}
+
+// Name returns the name of the processor.
+func (f *Filter) Name() string {
+	return "audit-event-filter"
+}
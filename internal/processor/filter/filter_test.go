@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package filter_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor/filter"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Filter", func() {
+	event := func(auditID, verb, resource string) auditinternal.Event {
+		return auditinternal.Event{
+			AuditID:   types.UID(auditID),
+			Stage:     auditinternal.StageResponseComplete,
+			Verb:      verb,
+			ObjectRef: &auditinternal.ObjectReference{Resource: resource},
+		}
+	}
+
+	encode := func(events ...auditinternal.Event) []byte {
+		data, err := helper.EncodeEventList(&auditinternal.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+			Items:    events,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	process := func(f *filter.Filter, data []byte) []auditinternal.Event {
+		processed, err := f.Process(context.Background(), data)
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		return eventList.Items
+	}
+
+	It("should keep the events for which the expression is true", func() {
+		f, err := filter.New(logr.Discard(), &configv1alpha1.Filter{Expression: `event.verb != "get" || event.objectRef.resource != "secrets"`})
+		Expect(err).NotTo(HaveOccurred())
+
+		kept := process(f, encode(event("1", "get", "secrets"), event("2", "get", "pods"), event("3", "delete", "secrets")))
+		Expect(kept).To(HaveLen(2))
+		Expect(kept[0].AuditID).To(BeEquivalentTo("2"))
+		Expect(kept[1].AuditID).To(BeEquivalentTo("3"))
+	})
+
+	It("should drop all events", func() {
+		f, err := filter.New(logr.Discard(), &configv1alpha1.Filter{Expression: `event.verb == "create"`})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(process(f, encode(event("1", "get", "pods"), event("2", "list", "pods")))).To(BeEmpty())
+	})
+
+	It("should return the data unchanged if all events are kept", func() {
+		f, err := filter.New(logr.Discard(), &configv1alpha1.Filter{Expression: `true`})
+		Expect(err).NotTo(HaveOccurred())
+
+		data := encode(event("1", "get", "pods"))
+		processed, err := f.Process(context.Background(), data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(processed).To(Equal(data))
+	})
+
+	It("should keep the events for which the expression cannot be evaluated", func() {
+		f, err := filter.New(logr.Discard(), &configv1alpha1.Filter{Expression: `event.annotations["team"] == "a"`})
+		Expect(err).NotTo(HaveOccurred())
+
+		withAnnotation := event("1", "get", "pods")
+		withAnnotation.Annotations = map[string]string{"team": "b"}
+		kept := process(f, encode(withAnnotation, event("2", "get", "pods")))
+		Expect(kept).To(HaveLen(1))
+		Expect(kept[0].AuditID).To(BeEquivalentTo("2"))
+	})
+
+	It("should fail for invalid expressions", func() {
+		_, err := filter.New(logr.Discard(), &configv1alpha1.Filter{Expression: `event.verb`})
+		Expect(err).To(MatchError(ContainSubstring("failed to compile filter expression")))
+	})
+
+	It("should fail for invalid data", func() {
+		f, err := filter.New(logr.Discard(), &configv1alpha1.Filter{Expression: `true`})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = f.Process(context.Background(), []byte("invalid"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return the name", func() {
+		f, err := filter.New(logr.Discard(), &configv1alpha1.Filter{Expression: `true`})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Name()).To(Equal("audit-event-filter"))
+	})
+})
@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hashchain
+
+import "time"
+
+// SetNow sets the clock used to schedule checkpoints.
+func (c *Chain) SetNow(now func() time.Time) {
+	c.now = now
+}
+
+// SigningKeyID returns the ID of the current signing key.
+func (c *Chain) SigningKeyID() string {
+	return c.key.Load().id
+}
@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hashchain implements a tamper-evident hash chain over the forwarded audit events.
+package hashchain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+const (
+	annotationPrefix = "hashchain.auditlog-forwarder.gardener.cloud/"
+
+	// AnnotationChain is the annotation holding the ID of the hash chain. Each forwarder process starts a new chain.
+	AnnotationChain = annotationPrefix + "chain"
+	// AnnotationSequence is the annotation holding the sequence number of the event in its chain, starting at 1.
+	AnnotationSequence = annotationPrefix + "sequence"
+	// AnnotationPreviousHash is the annotation holding the hash of the preceding event in the chain.
+	AnnotationPreviousHash = annotationPrefix + "previous-hash"
+	// AnnotationHash is the annotation holding the hash of the event.
+	AnnotationHash = annotationPrefix + "hash"
+	// AnnotationCheckpointSignature is the annotation holding the base64 encoded Ed25519 signature of a checkpoint.
+	AnnotationCheckpointSignature = annotationPrefix + "checkpoint-signature"
+	// AnnotationCheckpointKeyID is the annotation holding the ID of the key which signed a checkpoint.
+	AnnotationCheckpointKeyID = annotationPrefix + "checkpoint-key-id"
+
+	// GenesisHash is the previous hash of the first event of a chain.
+	GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	// checkpointContext separates checkpoint signatures from signatures of other messages made with the same key.
+	checkpointContext = "auditlog-forwarder hash chain checkpoint v1\n"
+
+	// reservationTTL is how long the links of undelivered events are kept for retries of their request.
+	// It covers the default retry backoff of the API server's webhook backend.
+	reservationTTL = 5 * time.Minute
+	// maxReservations is the maximum number of requests whose links are kept for retries.
+	maxReservations = 100
+)
+
+var (
+	_ processor.Processor = (*Chain)(nil)
+	_ processor.Committer = (*Chain)(nil)
+)
+
+// Chain implements Processor and links the audit events in a hash chain.
+// Each event is annotated with the chain ID, its sequence number, the hash of the preceding event and its own hash.
+// The hash covers the complete event including the chain annotations, except for the hash and checkpoint annotations.
+// Periodically, the hash of an event is signed as checkpoint, which authenticates the chain up to this event.
+// The links of events are reserved until they are committed as forwarded. If a request is retried with the same
+// events, it gets the reserved links again, so that failed deliveries do not leave gaps in the chain.
+type Chain struct {
+	logger             logr.Logger
+	keyFile            string
+	checkpointInterval time.Duration
+	id                 string
+	now                func() time.Time
+
+	key atomic.Pointer[signingKey]
+
+	mu             sync.Mutex
+	sequence       uint64
+	previousHash   string
+	lastCheckpoint time.Time
+	checkpointKey  string
+	reservations   []*reservation
+}
+
+// reservation is the processed data of a request whose events were not committed as forwarded yet.
+type reservation struct {
+	// input and output are the hashes of the received and the processed data.
+	input, output string
+	processed     []byte
+	reservedAt    time.Time
+}
+
+// signingKey is the key used to sign checkpoints together with its ID.
+type signingKey struct {
+	private ed25519.PrivateKey
+	id      string
+}
+
+// New creates a new hash chain with a random ID and loads the signing key.
+func New(logger logr.Logger, config *configv1alpha1.HashChain) (*Chain, error) {
+	c := &Chain{
+		logger:       logger,
+		keyFile:      config.SigningKeyFile,
+		id:           uuid.NewString(),
+		now:          time.Now,
+		previousHash: GenesisHash,
+	}
+	if config.CheckpointInterval != nil {
+		c.checkpointInterval = config.CheckpointInterval.Duration
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ID returns the ID of the chain.
+func (c *Chain) ID() string {
+	return c.id
+}
+
+// Reload loads the signing key from the key file. The next event is signed as checkpoint with the new key.
+func (c *Chain) Reload() error {
+	key, err := LoadSigningKey(c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.key.Store(&signingKey{private: key, id: KeyID(key.Public().(ed25519.PublicKey))})
+	return nil
+}
+
+// Process annotates the audit events with their hash chain links and checkpoints.
+// Chain annotations already present on the events are overwritten.
+// Data which was processed before, but not committed yet, is returned with the same links as before.
+func (c *Chain) Process(_ context.Context, data []byte) ([]byte, error) {
+	input := hashBytes(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pruneReservations()
+	for _, r := range c.reservations {
+		if r.input == input {
+			return r.processed, nil
+		}
+	}
+
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The state is only committed once all events were encoded, so that failed requests do not advance the chain.
+	sequence, previousHash, lastCheckpoint, checkpointKey := c.sequence, c.previousHash, c.lastCheckpoint, c.checkpointKey
+	key := c.key.Load()
+	for i := range eventList.Items {
+		event := &eventList.Items[i]
+		sequence++
+
+		if event.Annotations == nil {
+			event.Annotations = make(map[string]string, 6)
+		}
+		maps.DeleteFunc(event.Annotations, func(k, _ string) bool { return strings.HasPrefix(k, annotationPrefix) })
+		event.Annotations[AnnotationChain] = c.id
+		event.Annotations[AnnotationSequence] = strconv.FormatUint(sequence, 10)
+		event.Annotations[AnnotationPreviousHash] = previousHash
+
+		hash, err := hashEvent(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash audit event %q: %w", event.AuditID, err)
+		}
+		event.Annotations[AnnotationHash] = hash
+
+		now := c.now()
+		if sequence == 1 || key.id != checkpointKey || now.Sub(lastCheckpoint) >= c.checkpointInterval {
+			event.Annotations[AnnotationCheckpointSignature] = base64.StdEncoding.EncodeToString(ed25519.Sign(key.private, checkpointMessage(hash)))
+			event.Annotations[AnnotationCheckpointKeyID] = key.id
+			lastCheckpoint, checkpointKey = now, key.id
+		}
+		previousHash = hash
+	}
+
+	processed, err := helper.EncodeEventList(eventList)
+	if err != nil {
+		return nil, err
+	}
+	c.sequence, c.previousHash, c.lastCheckpoint, c.checkpointKey = sequence, previousHash, lastCheckpoint, checkpointKey
+	if len(eventList.Items) > 0 {
+		c.reservations = append(c.reservations, &reservation{input: input, output: hashBytes(processed), processed: processed, reservedAt: c.now()})
+	}
+	return processed, nil
+}
+
+// Commit releases the links of the forwarded events, so that they are not reused anymore.
+func (c *Chain) Commit(_ context.Context, data []byte) {
+	output := hashBytes(data)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reservations = slices.DeleteFunc(c.reservations, func(r *reservation) bool { return r.output == output })
+}
+
+// pruneReservations drops the reservations of requests which are not retried anymore.
+// Their events stay missing in the chain. The caller must hold the lock.
+func (c *Chain) pruneReservations() {
+	now := c.now()
+	c.reservations = slices.DeleteFunc(c.reservations, func(r *reservation) bool { return now.Sub(r.reservedAt) > reservationTTL })
+	if excess := len(c.reservations) - maxReservations + 1; excess > 0 {
+		c.reservations = slices.Delete(c.reservations, 0, excess)
+	}
+}
+
+// Name returns the name of the processor.
+func (c *Chain) Name() string {
+	return "audit-event-hash-chain"
+}
+
+// hashEvent returns the hash of the event without its hash and checkpoint annotations.
+func hashEvent(event *audit.Event) (string, error) {
+	unsigned := *event
+	unsigned.Annotations = withoutSignedAnnotations(event.Annotations)
+	encoded, err := helper.EncodeEvent(&unsigned)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(encoded), nil
+}
+
+// withoutSignedAnnotations returns a copy of the annotations without the hash and checkpoint annotations,
+// which are not covered by the hash of an event.
+func withoutSignedAnnotations(annotations map[string]string) map[string]string {
+	out := maps.Clone(annotations)
+	delete(out, AnnotationHash)
+	delete(out, AnnotationCheckpointSignature)
+	delete(out, AnnotationCheckpointKeyID)
+	return out
+}
+
+// hashBytes returns the hex encoded SHA-256 hash of data.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkpointMessage returns the message which is signed for a checkpoint at the event with the given hash.
+func checkpointMessage(hash string) []byte {
+	return []byte(checkpointContext + hash)
+}
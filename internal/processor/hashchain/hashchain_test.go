@@ -0,0 +1,279 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hashchain_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor/hashchain"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Chain", func() {
+	var (
+		keyFile   string
+		publicKey ed25519.PublicKey
+		now       time.Time
+		chain     *hashchain.Chain
+	)
+
+	// writeKey writes a new signing key to the key file and returns its public key.
+	writeKey := func() ed25519.PublicKey {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		der, err := x509.MarshalPKCS8PrivateKey(private)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)).To(Succeed())
+		return public
+	}
+
+	eventList := func(auditIDs ...string) []byte {
+		var items []string
+		for _, id := range auditIDs {
+			items = append(items, fmt.Sprintf(`{"level":"Metadata","auditID":%q,"stage":"ResponseComplete","verb":"get","requestURI":"/api","user":{"username":"alice"},`+
+				`"stageTimestamp":"2025-01-15T10:30:00.123456Z","annotations":{"foo":"bar"}}`, id))
+		}
+		return []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[` + strings.Join(items, ",") + `]}`)
+	}
+
+	process := func(auditIDs ...string) []byte {
+		processed, err := chain.Process(context.Background(), eventList(auditIDs...))
+		Expect(err).NotTo(HaveOccurred())
+		return processed
+	}
+
+	annotations := func(data []byte) []map[string]string {
+		list, err := helper.DecodeEventList(data)
+		Expect(err).NotTo(HaveOccurred())
+		var out []map[string]string
+		for _, event := range list.Items {
+			out = append(out, event.Annotations)
+		}
+		return out
+	}
+
+	verify := func(stream []byte, keys ...ed25519.PublicKey) *hashchain.Report {
+		report, err := hashchain.Verify(bytes.NewReader(stream), keys)
+		Expect(err).NotTo(HaveOccurred())
+		return report
+	}
+
+	BeforeEach(func() {
+		keyFile = filepath.Join(GinkgoT().TempDir(), "key.pem")
+		publicKey = writeKey()
+		now = time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+		var err error
+		chain, err = hashchain.New(logr.Discard(), &configv1alpha1.HashChain{
+			SigningKeyFile:     keyFile,
+			CheckpointInterval: &metav1.Duration{Duration: time.Minute},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		chain.SetNow(func() time.Time { return now })
+	})
+
+	It("should fail to create a chain without valid signing key", func() {
+		Expect(os.WriteFile(keyFile, []byte("invalid"), 0600)).To(Succeed())
+		_, err := hashchain.New(logr.Discard(), &configv1alpha1.HashChain{SigningKeyFile: keyFile})
+		Expect(err).To(MatchError(ContainSubstring(`no PEM block of type "PRIVATE KEY"`)))
+	})
+
+	It("should link the events and sign checkpoints periodically", func() {
+		first := annotations(process("a", "b"))
+		now = now.Add(30 * time.Second)
+		second := annotations(process("c"))
+		now = now.Add(31 * time.Second)
+		third := annotations(process("d"))
+
+		events := append(append(first, second...), third...)
+		Expect(events).To(HaveLen(4))
+		for i, event := range events {
+			Expect(event).To(HaveKeyWithValue(hashchain.AnnotationChain, chain.ID()))
+			Expect(event).To(HaveKeyWithValue(hashchain.AnnotationSequence, fmt.Sprint(i+1)))
+			Expect(event).To(HaveKeyWithValue("foo", "bar"))
+			if i == 0 {
+				Expect(event).To(HaveKeyWithValue(hashchain.AnnotationPreviousHash, hashchain.GenesisHash))
+			} else {
+				Expect(event).To(HaveKeyWithValue(hashchain.AnnotationPreviousHash, events[i-1][hashchain.AnnotationHash]))
+			}
+		}
+
+		Expect(events[0]).To(HaveKeyWithValue(hashchain.AnnotationCheckpointKeyID, hashchain.KeyID(publicKey)))
+		Expect(events[1]).NotTo(HaveKey(hashchain.AnnotationCheckpointSignature))
+		Expect(events[2]).NotTo(HaveKey(hashchain.AnnotationCheckpointSignature))
+		Expect(events[3]).To(HaveKey(hashchain.AnnotationCheckpointSignature))
+	})
+
+	It("should overwrite forged chain annotations", func() {
+		data := []byte(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[{"level":"Metadata","auditID":"a","stage":"ResponseComplete","verb":"get","requestURI":"/api","user":{},` +
+			`"annotations":{"` + hashchain.AnnotationSequence + `":"42","` + hashchain.AnnotationCheckpointSignature + `":"forged"}}]}`)
+		processed, err := chain.Process(context.Background(), data)
+		Expect(err).NotTo(HaveOccurred())
+
+		event := annotations(processed)[0]
+		Expect(event).To(HaveKeyWithValue(hashchain.AnnotationSequence, "1"))
+		Expect(event[hashchain.AnnotationCheckpointSignature]).NotTo(Equal("forged"))
+	})
+
+	It("should not advance the chain for requests which cannot be processed", func() {
+		_, err := chain.Process(context.Background(), []byte("invalid"))
+		Expect(err).To(HaveOccurred())
+
+		Expect(annotations(process("a"))[0]).To(HaveKeyWithValue(hashchain.AnnotationSequence, "1"))
+	})
+
+	Context("with failed deliveries", func() {
+		It("should reuse the links of events whose delivery failed when the request is retried", func() {
+			// The delivery of the first request fails, so it is not committed.
+			failed := process("a", "b")
+			second := process("c")
+			chain.Commit(context.Background(), second)
+
+			retried := process("a", "b")
+			Expect(retried).To(Equal(failed))
+			chain.Commit(context.Background(), retried)
+
+			report := verify(append(second, retried...), publicKey)
+			Expect(report.Valid()).To(BeTrue())
+			Expect(report.Chains[0].Events).To(Equal(3))
+		})
+
+		It("should link the events again once they were committed", func() {
+			first := process("a")
+			chain.Commit(context.Background(), first)
+
+			Expect(annotations(process("a"))[0]).To(HaveKeyWithValue(hashchain.AnnotationSequence, "2"))
+		})
+
+		It("should link the events again once the reservation expired", func() {
+			process("a")
+			now = now.Add(6 * time.Minute)
+
+			Expect(annotations(process("a"))[0]).To(HaveKeyWithValue(hashchain.AnnotationSequence, "2"))
+		})
+	})
+
+	Describe("#Verify", func() {
+		It("should verify a complete stream in any order and ignore duplicates", func() {
+			first := process("a", "b")
+			second := process("c")
+			now = now.Add(time.Minute)
+			third := process("d")
+
+			report := verify(bytes.Join([][]byte{third, first, second, first}, []byte("\n")), publicKey)
+			Expect(report.Valid()).To(BeTrue())
+			Expect(report.Chains).To(ConsistOf(hashchain.ChainReport{
+				ID:             chain.ID(),
+				Events:         4,
+				FirstSequence:  1,
+				LastSequence:   4,
+				Checkpoints:    2,
+				LastCheckpoint: 4,
+			}))
+		})
+
+		It("should verify streams of single events and JSON arrays", func() {
+			items, err := helper.EventListItems(process("a", "b", "c"))
+			Expect(err).NotTo(HaveOccurred())
+
+			ndjson := append(bytes.Join(items, []byte("\n")), '\n')
+			Expect(verify(ndjson, publicKey).Valid()).To(BeTrue())
+
+			array := append(append([]byte("["), bytes.Join(items, []byte(","))...), ']')
+			Expect(verify(array, publicKey).Valid()).To(BeTrue())
+		})
+
+		It("should detect modified events", func() {
+			processed := process("a", "b")
+			modified := bytes.Replace(processed, []byte(`"username":"alice"`), []byte(`"username":"mallory"`), 1)
+			Expect(modified).NotTo(Equal(processed))
+
+			report := verify(modified, publicKey)
+			Expect(report.Valid()).To(BeFalse())
+			Expect(report.Chains[0].Problems).To(ContainElement("sequence 1 (audit ID a): hash mismatch, the event was modified"))
+		})
+
+		It("should detect missing events", func() {
+			first := process("a", "b")
+			process("c", "d")
+			third := process("e")
+
+			report := verify(append(first, third...), publicKey)
+			Expect(report.Valid()).To(BeFalse())
+			Expect(report.Chains[0].Problems).To(ConsistOf("missing events with sequences 3-4"))
+			Expect(report.Chains[0].LastCheckpoint).To(Equal(uint64(1)))
+		})
+
+		It("should detect removed chain annotations", func() {
+			report := verify(eventList("a"), publicKey)
+			Expect(report.Valid()).To(BeFalse())
+			Expect(report.Unchained).To(Equal(1))
+		})
+
+		It("should reject checkpoints signed by unknown keys", func() {
+			other, _, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+
+			report := verify(process("a"), other)
+			Expect(report.Chains[0].Problems).To(ConsistOf(fmt.Sprintf("sequence 1 (audit ID a): checkpoint signed by unknown key %q", hashchain.KeyID(publicKey))))
+		})
+
+		It("should reject invalid checkpoint signatures", func() {
+			list, err := helper.DecodeEventList(process("a"))
+			Expect(err).NotTo(HaveOccurred())
+			other, _, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			list.Items[0].Annotations[hashchain.AnnotationCheckpointKeyID] = hashchain.KeyID(other)
+			data, err := helper.EncodeEventList(list)
+			Expect(err).NotTo(HaveOccurred())
+
+			report := verify(data, publicKey, other)
+			Expect(report.Chains[0].Problems).To(ConsistOf("sequence 1 (audit ID a): invalid checkpoint signature"))
+		})
+
+		It("should fail on streams which are no JSON", func() {
+			_, err := hashchain.Verify(bytes.NewReader([]byte("not json")), nil)
+			Expect(err).To(MatchError(ContainSubstring("failed to decode audit events")))
+		})
+	})
+
+	Describe("#Watch", func() {
+		It("should sign the next checkpoint with the reloaded key", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Expect(chain.Watch(ctx, 10*time.Millisecond)).To(Succeed())
+
+			first := process("a")
+			newPublicKey := writeKey()
+			Eventually(chain.SigningKeyID).Should(Equal(hashchain.KeyID(newPublicKey)))
+			second := process("b")
+
+			Expect(annotations(second)[0]).To(HaveKeyWithValue(hashchain.AnnotationCheckpointKeyID, hashchain.KeyID(newPublicKey)))
+			Expect(verify(append(first, second...), publicKey, newPublicKey).Valid()).To(BeTrue())
+		})
+
+		It("should keep the key if the changed file is invalid", func() {
+			Expect(os.WriteFile(keyFile, []byte("invalid"), 0600)).To(Succeed())
+			Expect(chain.Reload()).NotTo(Succeed())
+			Expect(chain.SigningKeyID()).To(Equal(hashchain.KeyID(publicKey)))
+		})
+	})
+})
@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hashchain
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+// Report is the result of the verification of a stored stream of audit events.
+type Report struct {
+	// Chains are the reports of the hash chains found in the stream, ordered by chain ID.
+	Chains []ChainReport
+	// Unchained is the number of events without hash chain annotations.
+	Unchained int
+}
+
+// Valid reports whether all chains are free of problems and all events are chained.
+func (r *Report) Valid() bool {
+	if r.Unchained > 0 {
+		return false
+	}
+	for _, chain := range r.Chains {
+		if len(chain.Problems) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ChainReport is the result of the verification of a single hash chain.
+type ChainReport struct {
+	// ID is the ID of the chain.
+	ID string
+	// Events is the number of distinct events of the chain.
+	Events int
+	// FirstSequence and LastSequence are the lowest and highest sequence numbers of the chain.
+	FirstSequence, LastSequence uint64
+	// Checkpoints is the number of checkpoints with a valid signature.
+	Checkpoints int
+	// LastCheckpoint is the sequence number of the last checkpoint with a valid signature, 0 if there is none.
+	// Events after the last checkpoint are linked, but could have been truncated without detection.
+	LastCheckpoint uint64
+	// Problems describe the gaps, modifications and invalid checkpoints found in the chain.
+	Problems []string
+}
+
+// link is a verified event of a chain.
+type link struct {
+	auditID      string
+	hash         string
+	previousHash string
+	signature    string
+	keyID        string
+}
+
+// Verify checks the audit events read from r for gaps and modifications of their hash chains and verifies
+// the checkpoints with the given public keys. The stream may contain "audit.k8s.io/v1" EventLists, JSON arrays
+// of events and single events, separated by whitespace or newlines. Events may appear in any order;
+// identical duplicates, e.g. from retried deliveries, are ignored.
+// An error is only returned if the stream cannot be decoded.
+func Verify(r io.Reader, keys []ed25519.PublicKey) (*Report, error) {
+	keysByID := make(map[string]ed25519.PublicKey, len(keys))
+	for _, key := range keys {
+		keysByID[KeyID(key)] = key
+	}
+
+	report := &Report{}
+	chains := map[string]map[uint64]link{}
+	problems := map[string][]string{}
+
+	err := decodeEvents(r, func(event *auditv1.Event) error {
+		chainID, ok := event.Annotations[AnnotationChain]
+		if !ok {
+			report.Unchained++
+			return nil
+		}
+		if chains[chainID] == nil {
+			chains[chainID] = map[uint64]link{}
+		}
+
+		sequence, err := strconv.ParseUint(event.Annotations[AnnotationSequence], 10, 64)
+		if err != nil || sequence == 0 {
+			problems[chainID] = append(problems[chainID], fmt.Sprintf("event %s has an invalid sequence number %q", event.AuditID, event.Annotations[AnnotationSequence]))
+			return nil
+		}
+
+		hash, err := hashV1Event(event)
+		if err != nil {
+			return err
+		}
+		l := link{
+			auditID:      string(event.AuditID),
+			hash:         event.Annotations[AnnotationHash],
+			previousHash: event.Annotations[AnnotationPreviousHash],
+			signature:    event.Annotations[AnnotationCheckpointSignature],
+			keyID:        event.Annotations[AnnotationCheckpointKeyID],
+		}
+		if hash != l.hash {
+			problems[chainID] = append(problems[chainID], fmt.Sprintf("sequence %d (audit ID %s): hash mismatch, the event was modified", sequence, l.auditID))
+			return nil
+		}
+
+		if existing, ok := chains[chainID][sequence]; ok {
+			if existing.hash != l.hash {
+				problems[chainID] = append(problems[chainID], fmt.Sprintf("sequence %d: conflicting events %s and %s", sequence, existing.auditID, l.auditID))
+			}
+			return nil
+		}
+		chains[chainID][sequence] = l
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for chainID, links := range chains {
+		chain := verifyChain(chainID, links, keysByID)
+		chain.Problems = append(problems[chainID], chain.Problems...)
+		report.Chains = append(report.Chains, chain)
+	}
+	slices.SortFunc(report.Chains, func(a, b ChainReport) int { return strings.Compare(a.ID, b.ID) })
+	return report, nil
+}
+
+// verifyChain checks the links and checkpoints of the events of a chain which passed the hash verification.
+func verifyChain(chainID string, links map[uint64]link, keys map[string]ed25519.PublicKey) ChainReport {
+	chain := ChainReport{ID: chainID, Events: len(links)}
+	if len(links) == 0 {
+		return chain
+	}
+
+	sequences := slices.Sorted(maps.Keys(links))
+	chain.FirstSequence, chain.LastSequence = sequences[0], sequences[len(sequences)-1]
+
+	expected := uint64(1)
+	for _, sequence := range sequences {
+		l := links[sequence]
+		if sequence > expected {
+			chain.Problems = append(chain.Problems, gapProblem(expected, sequence-1))
+		}
+		expected = sequence + 1
+
+		switch previous, ok := links[sequence-1]; {
+		case sequence == 1 && l.previousHash != GenesisHash:
+			chain.Problems = append(chain.Problems, fmt.Sprintf("sequence 1 (audit ID %s): previous hash is not the genesis hash", l.auditID))
+		case ok && l.previousHash != previous.hash:
+			chain.Problems = append(chain.Problems, fmt.Sprintf("sequence %d (audit ID %s): previous hash does not match the hash of sequence %d", sequence, l.auditID, sequence-1))
+		}
+
+		if l.signature == "" && l.keyID == "" {
+			continue
+		}
+		if err := verifyCheckpoint(l, keys); err != nil {
+			chain.Problems = append(chain.Problems, fmt.Sprintf("sequence %d (audit ID %s): %v", sequence, l.auditID, err))
+			continue
+		}
+		chain.Checkpoints++
+		chain.LastCheckpoint = sequence
+	}
+	return chain
+}
+
+// verifyCheckpoint verifies the checkpoint signature of the event.
+func verifyCheckpoint(l link, keys map[string]ed25519.PublicKey) error {
+	key, ok := keys[l.keyID]
+	if !ok {
+		return fmt.Errorf("checkpoint signed by unknown key %q", l.keyID)
+	}
+	signature, err := base64.StdEncoding.DecodeString(l.signature)
+	if err != nil {
+		return fmt.Errorf("checkpoint signature is not base64 encoded: %w", err)
+	}
+	if !ed25519.Verify(key, checkpointMessage(l.hash), signature) {
+		return errors.New("invalid checkpoint signature")
+	}
+	return nil
+}
+
+// gapProblem describes missing events with the sequence numbers from first to last.
+func gapProblem(first, last uint64) string {
+	if first == last {
+		return fmt.Sprintf("missing event with sequence %d", first)
+	}
+	return fmt.Sprintf("missing events with sequences %d-%d", first, last)
+}
+
+// hashV1Event returns the hash of the decoded event without its hash and checkpoint annotations.
+func hashV1Event(event *auditv1.Event) (string, error) {
+	unsigned := *event
+	// Events are hashed without type information, which is only present if they were stored individually.
+	unsigned.TypeMeta = metav1.TypeMeta{}
+	unsigned.Annotations = withoutSignedAnnotations(event.Annotations)
+	encoded, err := json.Marshal(&unsigned)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(encoded), nil
+}
+
+// decodeEvents decodes the stream of JSON values and calls fn for each contained event.
+func decodeEvents(r io.Reader, fn func(*auditv1.Event) error) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode audit events: %w", err)
+		}
+
+		items := []json.RawMessage{value}
+		switch {
+		case bytes.HasPrefix(value, []byte("[")):
+			if err := json.Unmarshal(value, &items); err != nil {
+				return fmt.Errorf("failed to decode audit events: %w", err)
+			}
+		default:
+			var list struct {
+				Kind  string            `json:"kind"`
+				Items []json.RawMessage `json:"items"`
+			}
+			if err := json.Unmarshal(value, &list); err != nil {
+				return fmt.Errorf("failed to decode audit events: %w", err)
+			}
+			if list.Kind == "EventList" {
+				items = list.Items
+			}
+		}
+
+		for _, item := range items {
+			event := &auditv1.Event{}
+			if err := json.Unmarshal(item, event); err != nil {
+				return fmt.Errorf("failed to decode audit event: %w", err)
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+}
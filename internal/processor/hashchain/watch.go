@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hashchain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/auditlog-forwarder/internal/filewatch"
+)
+
+// Watch reloads the signing key when the key file changes until ctx is done.
+// Filesystem events are coalesced for the debounce duration.
+func (c *Chain) Watch(ctx context.Context, debounce time.Duration) error {
+	_, err := filewatch.Watch(ctx, c.logger, []string{c.keyFile}, debounce, func() {
+		if err := c.Reload(); err != nil {
+			c.logger.Error(err, "Failed to reload signing key, keeping existing key")
+			return
+		}
+		c.logger.Info("Reloaded signing key", "keyID", c.key.Load().id)
+	})
+	return err
+}
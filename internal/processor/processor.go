@@ -15,3 +15,20 @@ type Processor interface {
 	// Name returns the name of the processor.
 	Name() string
 }
+
+// Committer is implemented by processors which keep track of the forwarded audit events.
+type Committer interface {
+	// Commit is called with the processed data of a request once it was forwarded to the required outputs.
+	Commit(ctx context.Context, data []byte)
+}
+
+// ReleaseFunc forwards audit events released by a processor through the following processors to the outputs.
+type ReleaseFunc func(ctx context.Context, data []byte) error
+
+// Holder is implemented by processors which hold back audit events and release them independently of requests.
+type Holder interface {
+	// Start starts releasing held audit events with the given function. It is called before the first request.
+	Start(release ReleaseFunc)
+	// Flush stops holding audit events and releases all held events. It is called when the handler shuts down.
+	Flush(ctx context.Context) error
+}
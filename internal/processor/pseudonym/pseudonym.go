@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pseudonym implements the replacement of user identities and source IPs with keyed pseudonyms.
+package pseudonym
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	authnv1 "k8s.io/api/authentication/v1"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+const (
+	// Prefix is the prefix of all pseudonyms except for the ones of source IPs.
+	Prefix = "pseudonym:"
+
+	// minKeySize is the minimum size of the key in bytes.
+	minKeySize = 32
+
+	// The domains separate the pseudonyms of different kinds of values, so that e.g. a username and a UID with the
+	// same value get different pseudonyms. Usernames of users and impersonated users share their domain.
+	domainUsername = "username"
+	domainUID      = "uid"
+	domainExtra    = "extra"
+	domainIP       = "ip"
+
+	// annotationAuthorizationReason is the annotation of the kube-apiserver explaining the authorization decision,
+	// which names the authorized user, e.g. `RBAC: allowed by ClusterRoleBinding "admins" ... to User "alice"`.
+	annotationAuthorizationReason = "authorization.k8s.io/reason"
+)
+
+var _ processor.Processor = (*Pseudonymizer)(nil)
+
+// Pseudonymizer implements Processor and replaces the user identities and source IPs of audit events with pseudonyms.
+type Pseudonymizer struct {
+	logger       logr.Logger
+	keyFile      string
+	allowedUsers []string
+	// eventAnnotations are the keys of the annotations computed from the audit events, which may contain identities.
+	eventAnnotations []string
+
+	key atomic.Pointer[[]byte]
+}
+
+// New creates a new pseudonymizer and loads its key. The eventAnnotations are the keys of the annotations computed
+// from the audit events, which are removed from the events of users which are not allowed.
+func New(logger logr.Logger, config *configv1alpha1.Pseudonymization, eventAnnotations []string) (*Pseudonymizer, error) {
+	p := &Pseudonymizer{
+		logger:           logger,
+		keyFile:          config.KeyFile,
+		allowedUsers:     config.AllowedUsers,
+		eventAnnotations: eventAnnotations,
+	}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload loads the key from the key file. Leading and trailing whitespace of the file content is ignored.
+func (p *Pseudonymizer) Reload() error {
+	data, err := os.ReadFile(filepath.Clean(p.keyFile))
+	if err != nil {
+		return fmt.Errorf("failed to read pseudonymization key file %s: %w", p.keyFile, err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) < minKeySize {
+		return fmt.Errorf("pseudonymization key in %s has %d bytes, at least %d bytes are required", p.keyFile, len(key), minKeySize)
+	}
+	p.key.Store(&key)
+	return nil
+}
+
+// Process replaces the usernames, UIDs and extra values of the users and impersonated users and the source IPs
+// of the audit events with pseudonyms. Users whose username is allowed are kept, including the source IPs of their requests.
+// The annotations naming the pseudonymized users, i.e. the authorization reason and the annotations computed from the
+// events, are removed. The request and response objects are removed from all events, as they may contain the
+// identities of any user, e.g. in TokenReviews, SubjectAccessReviews and CertificateSigningRequests.
+func (p *Pseudonymizer) Process(_ context.Context, data []byte) ([]byte, error) {
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key := *p.key.Load()
+	for i := range eventList.Items {
+		event := &eventList.Items[i]
+		pseudonymized := false
+		if !p.allowed(event.User.Username) {
+			pseudonymizeUser(key, &event.User)
+			for j, ip := range event.SourceIPs {
+				event.SourceIPs[j] = pseudonymizeIP(key, ip)
+			}
+			pseudonymized = true
+		}
+		if event.ImpersonatedUser != nil && !p.allowed(event.ImpersonatedUser.Username) {
+			pseudonymizeUser(key, event.ImpersonatedUser)
+			pseudonymized = true
+		}
+		if pseudonymized {
+			delete(event.Annotations, annotationAuthorizationReason)
+			for _, annotation := range p.eventAnnotations {
+				delete(event.Annotations, annotation)
+			}
+		}
+		event.RequestObject = nil
+		event.ResponseObject = nil
+	}
+
+	return helper.EncodeEventList(eventList)
+}
+
+// Name returns the name of the processor.
+func (p *Pseudonymizer) Name() string {
+	return "audit-event-pseudonymizer"
+}
+
+// allowed reports whether the username matches one of the allowed patterns.
+func (p *Pseudonymizer) allowed(username string) bool {
+	return slices.ContainsFunc(p.allowedUsers, func(pattern string) bool {
+		matched, _ := path.Match(pattern, username)
+		return matched
+	})
+}
+
+// pseudonymizeUser replaces the username, UID and extra values of the user with pseudonyms.
+// The groups are kept, as they describe the permissions rather than the identity of the user.
+func pseudonymizeUser(key []byte, user *authnv1.UserInfo) {
+	user.Username = pseudonymize(key, domainUsername, user.Username)
+	user.UID = pseudonymize(key, domainUID, user.UID)
+	for name, values := range user.Extra {
+		pseudonyms := make(authnv1.ExtraValue, len(values))
+		for i, value := range values {
+			pseudonyms[i] = pseudonymize(key, domainExtra+"/"+name, value)
+		}
+		user.Extra[name] = pseudonyms
+	}
+}
+
+// pseudonymize returns the pseudonym of the value in the domain. Empty values are kept.
+func pseudonymize(key []byte, domain, value string) string {
+	if value == "" {
+		return ""
+	}
+	return Prefix + hex.EncodeToString(mac(key, domain, value)[:16])
+}
+
+// pseudonymizeIP returns the pseudonym of the IP as IPv6 unique local address in fd00::/8,
+// so that it remains a valid IP address for schemas with typed IP fields.
+func pseudonymizeIP(key []byte, ip string) string {
+	if ip == "" {
+		return ""
+	}
+	var address [16]byte
+	address[0] = 0xfd
+	copy(address[1:], mac(key, domainIP, ip))
+	return netip.AddrFrom16(address).String()
+}
+
+// mac returns the HMAC-SHA256 of the value in the domain.
+func mac(key []byte, domain, value string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(domain))
+	h.Write([]byte{0})
+	h.Write([]byte(value))
+	return h.Sum(nil)
+}
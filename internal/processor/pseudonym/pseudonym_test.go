@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pseudonym_test
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/processor/pseudonym"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Pseudonymizer", func() {
+	const data = `{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[` +
+		`{"level":"Metadata","auditID":"1","stage":"ResponseComplete","verb":"get","requestURI":"/api",` +
+		`"user":{"username":"alice","uid":"42","groups":["admins"],"extra":{"sso":["alice@example.com"]}},` +
+		`"impersonatedUser":{"username":"bob","uid":"43"},"sourceIPs":["10.0.0.1","2001:db8::1"]},` +
+		`{"level":"Metadata","auditID":"2","stage":"ResponseComplete","verb":"get","requestURI":"/api",` +
+		`"user":{"username":"system:serviceaccount:kube-system:default","uid":"44"},` +
+		`"impersonatedUser":{"username":"alice"},"sourceIPs":["10.0.0.2"]}]}`
+
+	var (
+		keyFile       string
+		pseudonymizer *pseudonym.Pseudonymizer
+	)
+
+	process := func() []auditinternal.Event {
+		processed, err := pseudonymizer.Process(context.Background(), []byte(data))
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		return eventList.Items
+	}
+
+	BeforeEach(func() {
+		keyFile = filepath.Join(GinkgoT().TempDir(), "key")
+		Expect(os.WriteFile(keyFile, []byte(strings.Repeat("k", 32)+"\n"), 0600)).To(Succeed())
+
+		var err error
+		pseudonymizer, err = pseudonym.New(logr.Discard(), &configv1alpha1.Pseudonymization{
+			KeyFile:      keyFile,
+			AllowedUsers: []string{"system:serviceaccount:*"},
+		}, []string{"example.com/user"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should replace the identities of users which are not allowed", func() {
+		events := process()
+
+		user := events[0].User
+		Expect(user.Username).To(HavePrefix(pseudonym.Prefix))
+		Expect(user.UID).To(HavePrefix(pseudonym.Prefix))
+		Expect(user.Groups).To(Equal([]string{"admins"}))
+		Expect(user.Extra).To(HaveKey("sso"))
+		Expect(user.Extra["sso"]).To(ConsistOf(HavePrefix(pseudonym.Prefix)))
+		Expect(events[0].ImpersonatedUser.Username).To(HavePrefix(pseudonym.Prefix))
+
+		for _, ip := range events[0].SourceIPs {
+			addr, err := netip.ParseAddr(ip)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(netip.MustParsePrefix("fd00::/8").Contains(addr)).To(BeTrue())
+		}
+		Expect(events[0].SourceIPs[0]).NotTo(Equal(events[0].SourceIPs[1]))
+	})
+
+	It("should keep allowed users and the source IPs of their requests", func() {
+		events := process()
+
+		Expect(events[1].User.Username).To(Equal("system:serviceaccount:kube-system:default"))
+		Expect(events[1].User.UID).To(Equal("44"))
+		Expect(events[1].SourceIPs).To(Equal([]string{"10.0.0.2"}))
+		Expect(events[1].ImpersonatedUser.Username).To(HavePrefix(pseudonym.Prefix))
+	})
+
+	It("should remove the annotations and objects which may contain identities", func() {
+		const data = `{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[` +
+			`{"level":"RequestResponse","auditID":"1","stage":"ResponseComplete","verb":"create","requestURI":"/apis",` +
+			`"user":{"username":"alice"},` +
+			`"requestObject":{"kind":"SubjectAccessReview","spec":{"user":"alice"}},"responseObject":{"kind":"SubjectAccessReview"},` +
+			`"annotations":{"authorization.k8s.io/decision":"allow","authorization.k8s.io/reason":"RBAC: allowed to User \"alice\"","example.com/user":"alice"}},` +
+			`{"level":"RequestResponse","auditID":"2","stage":"ResponseComplete","verb":"create","requestURI":"/apis",` +
+			`"user":{"username":"system:serviceaccount:kube-system:default"},` +
+			`"requestObject":{"kind":"TokenReview","status":{"user":{"username":"alice"}}},` +
+			`"annotations":{"authorization.k8s.io/reason":"RBAC: allowed","example.com/user":"system:serviceaccount:kube-system:default"}}]}`
+		processed, err := pseudonymizer.Process(context.Background(), []byte(data))
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		events := eventList.Items
+
+		Expect(events[0].Annotations).To(Equal(map[string]string{"authorization.k8s.io/decision": "allow"}))
+		Expect(events[1].Annotations).To(Equal(map[string]string{
+			"authorization.k8s.io/reason": "RBAC: allowed",
+			"example.com/user":            "system:serviceaccount:kube-system:default",
+		}))
+		for _, event := range events {
+			Expect(event.RequestObject).To(BeNil())
+			Expect(event.ResponseObject).To(BeNil())
+		}
+		Expect(string(processed)).NotTo(ContainSubstring("alice"))
+	})
+
+	It("should return equal pseudonyms for equal values of the same kind", func() {
+		events := process()
+
+		Expect(events[1].ImpersonatedUser.Username).To(Equal(events[0].User.Username))
+		Expect(events[0].ImpersonatedUser.Username).NotTo(Equal(events[0].User.Username))
+		Expect(process()).To(Equal(events))
+	})
+
+	It("should change the pseudonyms when the key is reloaded", func() {
+		before := process()
+
+		Expect(os.WriteFile(keyFile, []byte(strings.Repeat("x", 32)), 0600)).To(Succeed())
+		Expect(pseudonymizer.Reload()).To(Succeed())
+
+		Expect(process()[0].User.Username).NotTo(Equal(before[0].User.Username))
+	})
+
+	It("should keep the key if the changed file is invalid", func() {
+		before := process()
+
+		Expect(os.WriteFile(keyFile, []byte("short"), 0600)).To(Succeed())
+		Expect(pseudonymizer.Reload()).To(MatchError(ContainSubstring("has 5 bytes, at least 32 bytes are required")))
+
+		Expect(process()).To(Equal(before))
+	})
+
+	It("should fail to create a pseudonymizer without key", func() {
+		_, err := pseudonym.New(logr.Discard(), &configv1alpha1.Pseudonymization{KeyFile: filepath.Join(GinkgoT().TempDir(), "missing")}, nil)
+		Expect(err).To(MatchError(ContainSubstring("failed to read pseudonymization key file")))
+	})
+
+	It("should fail for data which is no event list", func() {
+		_, err := pseudonymizer.Process(context.Background(), []byte("invalid"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("#Watch", func() {
+		It("should reload the key when the file changes", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Expect(pseudonymizer.Watch(ctx, 10*time.Millisecond)).To(Succeed())
+			before := process()
+
+			Expect(os.WriteFile(keyFile, []byte(strings.Repeat("x", 32)), 0600)).To(Succeed())
+
+			Eventually(func() string { return process()[0].User.Username }).ShouldNot(Equal(before[0].User.Username))
+		})
+	})
+})
@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pseudonym
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/auditlog-forwarder/internal/filewatch"
+)
+
+// Watch reloads the key when the key file changes until ctx is done.
+// Filesystem events are coalesced for the debounce duration.
+func (p *Pseudonymizer) Watch(ctx context.Context, debounce time.Duration) error {
+	_, err := filewatch.Watch(ctx, p.logger, []string{p.keyFile}, debounce, func() {
+		if err := p.Reload(); err != nil {
+			p.logger.Error(err, "Failed to reload pseudonymization key, keeping existing key")
+			return
+		}
+		p.logger.Info("Reloaded pseudonymization key")
+	})
+	return err
+}
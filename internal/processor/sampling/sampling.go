@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sampling implements the deterministic sampling of audit events by rules.
+package sampling
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+const (
+	annotationPrefix = "sampling.auditlog-forwarder.gardener.cloud/"
+
+	// AnnotationRule is the annotation holding the name of the rule by which a forwarded event was sampled.
+	AnnotationRule = annotationPrefix + "rule"
+	// AnnotationRate is the annotation holding the fraction of the events matched by the rule which are forwarded,
+	// e.g. "0.01". Consumers can estimate the original number of events by dividing by the rate.
+	AnnotationRate = annotationPrefix + "rate"
+)
+
+var _ processor.Processor = (*Sampler)(nil)
+
+// Sampler implements Processor and drops audit events according to the sampling rules.
+type Sampler struct {
+	rules []configv1alpha1.SamplingRule
+}
+
+// New creates a new Sampler with the given configuration.
+func New(config *configv1alpha1.Sampling) *Sampler {
+	return &Sampler{
+		rules: config.Rules,
+	}
+}
+
+// Process drops the audit events which are sampled out by the first matching rule and annotates the
+// forwarded events matched by a rule with the rule name and its rate.
+// Events of failed requests and events matching no rule are forwarded unchanged.
+func (s *Sampler) Process(_ context.Context, data []byte) ([]byte, error) {
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := eventList.Items[:0]
+	for _, event := range eventList.Items {
+		rule := s.match(&event)
+		if rule == nil {
+			kept = append(kept, event)
+			continue
+		}
+		if !sampled(event.AuditID, rule.Percentage) {
+			metrics.SampledOutEvents.WithLabelValues(rule.Name).Inc()
+			continue
+		}
+		if event.Annotations == nil {
+			event.Annotations = make(map[string]string, 2)
+		}
+		event.Annotations[AnnotationRule] = rule.Name
+		event.Annotations[AnnotationRate] = strconv.FormatFloat(float64(rule.Percentage)/100, 'f', -1, 64)
+		kept = append(kept, event)
+	}
+	eventList.Items = kept
+
+	return helper.EncodeEventList(eventList)
+}
+
+// Name returns the name of the processor.
+func (s *Sampler) Name() string {
+	return "audit-event-sampler"
+}
+
+// match returns the first rule matching the event or nil if the event is not subject to sampling.
+func (s *Sampler) match(event *audit.Event) *configv1alpha1.SamplingRule {
+	if event.ResponseStatus != nil && event.ResponseStatus.Code >= http.StatusBadRequest {
+		return nil
+	}
+	for i := range s.rules {
+		if rule := &s.rules[i]; matches(rule, event) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// matches reports whether the event matches all criteria of the rule.
+func matches(rule *configv1alpha1.SamplingRule, event *audit.Event) bool {
+	if len(rule.Verbs) > 0 && !slices.Contains(rule.Verbs, event.Verb) {
+		return false
+	}
+	if len(rule.Users) > 0 && !slices.ContainsFunc(rule.Users, func(pattern string) bool {
+		matched, _ := path.Match(pattern, event.User.Username)
+		return matched
+	}) {
+		return false
+	}
+	if len(rule.Resources) > 0 && (event.ObjectRef == nil || !slices.Contains(rule.Resources, resource(event.ObjectRef))) {
+		return false
+	}
+	return true
+}
+
+// resource returns the resource of the object reference including its subresource, e.g. "pods/log".
+func resource(ref *audit.ObjectReference) string {
+	if ref.Subresource != "" {
+		return ref.Resource + "/" + ref.Subresource
+	}
+	return ref.Resource
+}
+
+// sampled reports whether the event with the audit ID is forwarded at the given percentage.
+// The decision only depends on the audit ID, so that all stages of a request and replays of it are sampled alike.
+func sampled(auditID types.UID, percentage int32) bool {
+	sum := sha256.Sum256([]byte(auditID))
+	return binary.BigEndian.Uint64(sum[:8])%100 < uint64(percentage)
+}
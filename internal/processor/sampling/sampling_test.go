@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sampling_test
+
+import (
+	"context"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	authnv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	"github.com/gardener/auditlog-forwarder/internal/processor/sampling"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Sampler", func() {
+	const serviceAccount = "system:serviceaccount:kube-system:controller"
+
+	var sampler *sampling.Sampler
+
+	event := func(auditID, verb, username string, code int32) auditinternal.Event {
+		e := auditinternal.Event{
+			AuditID:   types.UID(auditID),
+			Stage:     auditinternal.StageResponseComplete,
+			Verb:      verb,
+			User:      authnv1.UserInfo{Username: username},
+			ObjectRef: &auditinternal.ObjectReference{Resource: "pods", Namespace: "default"},
+		}
+		if code != 0 {
+			e.ResponseStatus = &metav1.Status{Code: code}
+		}
+		return e
+	}
+
+	process := func(events ...auditinternal.Event) []auditinternal.Event {
+		data, err := helper.EncodeEventList(&auditinternal.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+			Items:    events,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		processed, err := sampler.Process(context.Background(), data)
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		return eventList.Items
+	}
+
+	sampledOut := func(rule string) float64 {
+		return testutil.ToFloat64(metrics.SampledOutEvents.WithLabelValues(rule))
+	}
+
+	BeforeEach(func() {
+		sampler = sampling.New(&configv1alpha1.Sampling{Rules: []configv1alpha1.SamplingRule{
+			{Name: "keep-secrets", Resources: []string{"secrets"}, Percentage: 100},
+			{Name: "service-account-reads", Verbs: []string{"list", "watch"}, Users: []string{"system:serviceaccount:*"}, Percentage: 10},
+			{Name: "drop-logs", Resources: []string{"pods/log"}, Percentage: 0},
+		}})
+	})
+
+	It("should sample matching events by the percentage of the rule and annotate the kept events", func() {
+		var events []auditinternal.Event
+		for i := range 1000 {
+			events = append(events, event(strconv.Itoa(i), "list", serviceAccount, 200))
+		}
+
+		sampledOutBefore := sampledOut("service-account-reads")
+		kept := process(events...)
+		Expect(len(kept)).To(BeNumerically("~", 100, 40))
+		for _, e := range kept {
+			Expect(e.Annotations).To(HaveKeyWithValue(sampling.AnnotationRule, "service-account-reads"))
+			Expect(e.Annotations).To(HaveKeyWithValue(sampling.AnnotationRate, "0.1"))
+		}
+		Expect(sampledOut("service-account-reads") - sampledOutBefore).To(BeEquivalentTo(1000 - len(kept)))
+	})
+
+	It("should make the same decisions for all stages and replays of a request", func() {
+		var events []auditinternal.Event
+		for i := range 100 {
+			received := event(strconv.Itoa(i), "watch", serviceAccount, 0)
+			received.Stage = auditinternal.StageRequestReceived
+			events = append(events, received, event(strconv.Itoa(i), "watch", serviceAccount, 200))
+		}
+
+		kept := process(events...)
+		Expect(process(events...)).To(Equal(kept))
+
+		stages := map[types.UID]int{}
+		for _, e := range kept {
+			stages[e.AuditID]++
+		}
+		for auditID, count := range stages {
+			Expect(count).To(Equal(2), "audit ID %s", auditID)
+		}
+	})
+
+	It("should forward events matching no rule unchanged", func() {
+		kept := process(
+			event("1", "create", serviceAccount, 201),
+			event("2", "list", "alice", 200),
+		)
+
+		Expect(kept).To(HaveLen(2))
+		Expect(kept[0].Annotations).To(BeEmpty())
+		Expect(kept[1].Annotations).To(BeEmpty())
+	})
+
+	It("should always forward events of failed requests", func() {
+		denied := event("1", "list", serviceAccount, 403)
+		logs := event("2", "get", "alice", 500)
+		logs.ObjectRef.Subresource = "log"
+
+		Expect(process(denied, logs)).To(HaveLen(2))
+	})
+
+	It("should be decided by the first matching rule", func() {
+		secrets := event("1", "list", serviceAccount, 200)
+		secrets.ObjectRef.Resource = "secrets"
+		logs := event("2", "get", "alice", 200)
+		logs.ObjectRef.Subresource = "log"
+
+		sampledOutBefore := sampledOut("drop-logs")
+		kept := process(secrets, logs)
+		Expect(kept).To(HaveLen(1))
+		Expect(kept[0].AuditID).To(BeEquivalentTo("1"))
+		Expect(kept[0].Annotations).To(HaveKeyWithValue(sampling.AnnotationRule, "keep-secrets"))
+		Expect(kept[0].Annotations).To(HaveKeyWithValue(sampling.AnnotationRate, "1"))
+		Expect(sampledOut("drop-logs") - sampledOutBefore).To(Equal(1.0))
+	})
+
+	It("should not match non-resource requests by rules with resources", func() {
+		healthz := event("1", "get", "alice", 200)
+		healthz.ObjectRef = nil
+		healthz.RequestURI = "/healthz"
+
+		Expect(process(healthz)).To(HaveLen(1))
+	})
+
+	It("should return an error for invalid data", func() {
+		_, err := sampler.Process(context.Background(), []byte("invalid"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,12 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package stagemerge
+
+import "k8s.io/utils/clock"
+
+// SetClock sets the clock by which the held events expire.
+func (m *Merger) SetClock(c clock.WithTicker) {
+	m.clock = c
+}
@@ -0,0 +1,248 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package stagemerge implements the merging of the stages of a request into a single audit event.
+package stagemerge
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/utils/clock"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	"github.com/gardener/auditlog-forwarder/internal/processor"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var (
+	_ processor.Processor = (*Merger)(nil)
+	_ processor.Holder    = (*Merger)(nil)
+)
+
+// Merger implements Processor and holds back the "RequestReceived" events of requests for a window.
+// If the "ResponseComplete" or "Panic" event of the request arrives within the window, the held event is dropped,
+// otherwise it is released within a tenth of the window after the window expired.
+// Events whose release fails are held again and released with the next tick or on flush.
+type Merger struct {
+	logger        logr.Logger
+	window        time.Duration
+	maxHeldEvents int
+	clock         clock.WithTicker
+	stop          chan struct{}
+
+	mu sync.Mutex
+	// held maps the audit IDs of the held events to their elements in order.
+	held map[types.UID]*list.Element
+	// order contains the held events ordered by arrival, i.e. by expiry.
+	order   *list.List
+	release processor.ReleaseFunc
+	flushed bool
+}
+
+// heldEvent is an event held until it expires.
+type heldEvent struct {
+	event   audit.Event
+	expires time.Time
+}
+
+// New creates a new Merger with the given configuration.
+func New(logger logr.Logger, config *configv1alpha1.StageMerging) *Merger {
+	return &Merger{
+		logger:        logger,
+		window:        config.Window.Duration,
+		maxHeldEvents: int(config.MaxHeldEvents),
+		clock:         clock.RealClock{},
+		stop:          make(chan struct{}),
+		held:          map[types.UID]*list.Element{},
+		order:         list.New(),
+	}
+}
+
+// Start starts releasing expired events with the given function until the merger is flushed.
+func (m *Merger) Start(release processor.ReleaseFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.release = release
+
+	ticker := m.clock.NewTicker(m.window / 10)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C():
+				m.expire()
+			}
+		}
+	}()
+}
+
+// Process holds the "RequestReceived" events and drops the held events of the completed requests.
+// "RequestReceived" events of requests completed within the data are dropped right away.
+// If more than the maximum number of events are held, the events held the longest are added to the data.
+func (m *Merger) Process(_ context.Context, data []byte) ([]byte, error) {
+	eventList, err := helper.DecodeEventList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := sets.New[types.UID]()
+	for _, event := range eventList.Items {
+		if completes(event.Stage) {
+			completed.Insert(event.AuditID)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := make([]audit.Event, 0, len(eventList.Items))
+	for _, event := range eventList.Items {
+		switch {
+		case event.Stage == audit.StageRequestReceived && event.AuditID != "" && !m.flushed:
+			if completed.Has(event.AuditID) {
+				metrics.MergedEvents.Inc()
+				continue
+			}
+			m.hold(event)
+		case completes(event.Stage):
+			if element, ok := m.held[event.AuditID]; ok {
+				m.order.Remove(element)
+				delete(m.held, event.AuditID)
+				metrics.MergedEvents.Inc()
+			}
+			kept = append(kept, event)
+		default:
+			kept = append(kept, event)
+		}
+	}
+
+	// The events held the longest are forwarded with this request to make room for the new ones.
+	for m.order.Len() > m.maxHeldEvents {
+		kept = append(kept, m.remove(m.order.Front()).event)
+	}
+	metrics.HeldEvents.Set(float64(m.order.Len()))
+
+	eventList.Items = kept
+	return helper.EncodeEventList(eventList)
+}
+
+// Flush releases all held events. Events of later requests are no longer held.
+// If the release fails, the events are held again, so that another flush can retry it.
+func (m *Merger) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.flushed {
+		m.flushed = true
+		close(m.stop)
+	}
+	held := m.take(func(*heldEvent) bool { return true })
+	release := m.release
+	m.mu.Unlock()
+
+	return m.releaseHeld(ctx, release, held)
+}
+
+// Name returns the name of the processor.
+func (m *Merger) Name() string {
+	return "audit-event-stage-merger"
+}
+
+// hold holds the event until the window expired. Repeated events of a request which is already held are dropped.
+// The caller must hold the lock.
+func (m *Merger) hold(event audit.Event) {
+	if _, ok := m.held[event.AuditID]; ok {
+		return
+	}
+	m.held[event.AuditID] = m.order.PushBack(&heldEvent{event: event, expires: m.clock.Now().Add(m.window)})
+}
+
+// expire releases the expired events.
+func (m *Merger) expire() {
+	m.mu.Lock()
+	now := m.clock.Now()
+	held := m.take(func(h *heldEvent) bool { return !h.expires.After(now) })
+	release := m.release
+	m.mu.Unlock()
+
+	if err := m.releaseHeld(context.Background(), release, held); err != nil {
+		m.logger.Error(err, "Failed to release expired audit events, holding them again", "events", len(held))
+	}
+}
+
+// take removes the held events from the front as long as they satisfy the condition and returns them.
+// The caller must hold the lock.
+func (m *Merger) take(condition func(*heldEvent) bool) []*heldEvent {
+	var held []*heldEvent
+	for element := m.order.Front(); element != nil && condition(element.Value.(*heldEvent)); element = m.order.Front() {
+		held = append(held, m.remove(element))
+	}
+	metrics.HeldEvents.Set(float64(m.order.Len()))
+	return held
+}
+
+// rehold holds the taken events again in front of the other held events, keeping their expiry.
+// Events of requests which were held again in the meantime are skipped. The caller must hold the lock.
+func (m *Merger) rehold(held []*heldEvent) {
+	for _, h := range slices.Backward(held) {
+		if _, ok := m.held[h.event.AuditID]; ok {
+			continue
+		}
+		m.held[h.event.AuditID] = m.order.PushFront(h)
+	}
+	metrics.HeldEvents.Set(float64(m.order.Len()))
+}
+
+// releaseHeld releases the taken events and holds them again if the release fails.
+func (m *Merger) releaseHeld(ctx context.Context, release processor.ReleaseFunc, held []*heldEvent) error {
+	events := make([]audit.Event, 0, len(held))
+	for _, h := range held {
+		events = append(events, h.event)
+	}
+	if err := m.releaseEvents(ctx, release, events); err != nil {
+		metrics.HeldEventsReleaseFailures.Inc()
+		m.mu.Lock()
+		m.rehold(held)
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// remove removes the held event. The caller must hold the lock.
+func (m *Merger) remove(element *list.Element) *heldEvent {
+	h := m.order.Remove(element).(*heldEvent)
+	delete(m.held, h.event.AuditID)
+	return h
+}
+
+// releaseEvents releases the events with the release function.
+func (m *Merger) releaseEvents(ctx context.Context, release processor.ReleaseFunc, events []audit.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if release == nil {
+		return errors.New("held audit events cannot be released before the release function is set")
+	}
+	data, err := helper.EncodeEventList(&audit.EventList{Items: events})
+	if err != nil {
+		return err
+	}
+	return release(ctx, data)
+}
+
+// completes reports whether the stage is the last stage of a request.
+func completes(stage audit.Stage) bool {
+	return stage == audit.StageResponseComplete || stage == audit.StagePanic
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package stagemerge_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	testclock "k8s.io/utils/clock/testing"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	"github.com/gardener/auditlog-forwarder/internal/processor/stagemerge"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Merger", func() {
+	var (
+		ctx    = context.Background()
+		clock  *testclock.FakeClock
+		merger *stagemerge.Merger
+
+		mu       sync.Mutex
+		released []auditinternal.Event
+		failing  atomic.Bool
+	)
+
+	event := func(auditID string, stage auditinternal.Stage) auditinternal.Event {
+		return auditinternal.Event{AuditID: types.UID(auditID), Stage: stage, Verb: "get", RequestURI: "/api"}
+	}
+
+	encode := func(events ...auditinternal.Event) []byte {
+		data, err := helper.EncodeEventList(&auditinternal.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "audit.k8s.io/v1", Kind: "EventList"},
+			Items:    events,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	process := func(events ...auditinternal.Event) []auditinternal.Event {
+		processed, err := merger.Process(ctx, encode(events...))
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err := helper.DecodeEventList(processed)
+		Expect(err).NotTo(HaveOccurred())
+		return eventList.Items
+	}
+
+	releasedAuditIDs := func() []types.UID {
+		mu.Lock()
+		defer mu.Unlock()
+		var auditIDs []types.UID
+		for _, e := range released {
+			auditIDs = append(auditIDs, e.AuditID)
+		}
+		return auditIDs
+	}
+
+	BeforeEach(func() {
+		released = nil
+		failing.Store(false)
+		clock = testclock.NewFakeClock(time.Now())
+		merger = stagemerge.New(logr.Discard(), &configv1alpha1.StageMerging{
+			Window:        &metav1.Duration{Duration: time.Minute},
+			MaxHeldEvents: 3,
+		})
+		merger.SetClock(clock)
+		merger.Start(func(_ context.Context, data []byte) error {
+			if failing.Load() {
+				return errors.New("fake")
+			}
+			eventList, err := helper.DecodeEventList(data)
+			Expect(err).NotTo(HaveOccurred())
+			mu.Lock()
+			defer mu.Unlock()
+			released = append(released, eventList.Items...)
+			return nil
+		})
+	})
+
+	AfterEach(func() {
+		Expect(merger.Flush(ctx)).To(Succeed())
+	})
+
+	It("should drop held events when the request completes", func() {
+		mergedBefore := testutil.ToFloat64(metrics.MergedEvents)
+		Expect(process(event("1", auditinternal.StageRequestReceived), event("2", auditinternal.StageRequestReceived))).To(BeEmpty())
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(2.0))
+
+		events := process(event("1", auditinternal.StageResponseComplete), event("2", auditinternal.StagePanic))
+		Expect(events).To(HaveLen(2))
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(0.0))
+		Expect(testutil.ToFloat64(metrics.MergedEvents) - mergedBefore).To(Equal(2.0))
+
+		clock.Step(2 * time.Minute)
+		Consistently(releasedAuditIDs).Should(BeEmpty())
+	})
+
+	It("should drop events of requests completed within the data right away", func() {
+		events := process(event("1", auditinternal.StageRequestReceived), event("1", auditinternal.StageResponseComplete))
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Stage).To(Equal(auditinternal.StageResponseComplete))
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(0.0))
+	})
+
+	It("should keep holding events when the response started", func() {
+		Expect(process(event("1", auditinternal.StageRequestReceived))).To(BeEmpty())
+		Expect(process(event("1", auditinternal.StageResponseStarted))).To(HaveLen(1))
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(1.0))
+	})
+
+	It("should forward events of other stages and events without audit ID", func() {
+		events := process(event("", auditinternal.StageRequestReceived), event("1", auditinternal.StageResponseComplete))
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("should release held events once the window expired", func() {
+		process(event("1", auditinternal.StageRequestReceived))
+		clock.Step(30 * time.Second)
+		process(event("2", auditinternal.StageRequestReceived))
+
+		clock.Step(30 * time.Second)
+		Eventually(releasedAuditIDs).Should(Equal([]types.UID{"1"}))
+
+		clock.Step(30 * time.Second)
+		Eventually(releasedAuditIDs).Should(Equal([]types.UID{"1", "2"}))
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(0.0))
+	})
+
+	It("should forward the events held the longest when too many events are held", func() {
+		process(event("1", auditinternal.StageRequestReceived), event("2", auditinternal.StageRequestReceived), event("3", auditinternal.StageRequestReceived))
+
+		events := process(event("4", auditinternal.StageRequestReceived), event("5", auditinternal.StageRequestReceived))
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].AuditID).To(BeEquivalentTo("1"))
+		Expect(events[1].AuditID).To(BeEquivalentTo("2"))
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(3.0))
+	})
+
+	It("should release all held events on flush and no longer hold events", func() {
+		process(event("1", auditinternal.StageRequestReceived), event("2", auditinternal.StageRequestReceived))
+
+		Expect(merger.Flush(ctx)).To(Succeed())
+		Expect(releasedAuditIDs()).To(Equal([]types.UID{"1", "2"}))
+
+		Expect(process(event("3", auditinternal.StageRequestReceived))).To(HaveLen(1))
+	})
+
+	It("should hold the events again if their release fails and retry it with the next tick", func() {
+		failuresBefore := testutil.ToFloat64(metrics.HeldEventsReleaseFailures)
+		process(event("1", auditinternal.StageRequestReceived))
+		clock.Step(30 * time.Second)
+		process(event("2", auditinternal.StageRequestReceived))
+
+		failing.Store(true)
+		clock.Step(time.Minute)
+		Eventually(func() float64 { return testutil.ToFloat64(metrics.HeldEventsReleaseFailures) }).Should(BeNumerically(">", failuresBefore))
+		Expect(releasedAuditIDs()).To(BeEmpty())
+
+		failing.Store(false)
+		clock.Step(6 * time.Second)
+		Eventually(releasedAuditIDs).Should(Equal([]types.UID{"1", "2"}))
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(0.0))
+	})
+
+	It("should return the error of the release on flush and hold the events again", func() {
+		process(event("1", auditinternal.StageRequestReceived), event("2", auditinternal.StageRequestReceived))
+
+		failing.Store(true)
+		Expect(merger.Flush(ctx)).To(MatchError("fake"))
+		Expect(testutil.ToFloat64(metrics.HeldEvents)).To(Equal(2.0))
+
+		failing.Store(false)
+		Expect(merger.Flush(ctx)).To(Succeed())
+		Expect(releasedAuditIDs()).To(Equal([]types.UID{"1", "2"}))
+	})
+
+	It("should return an error for invalid data", func() {
+		_, err := merger.Process(ctx, []byte("invalid"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	mimeOCSPRequest = "application/ocsp-request"
+	// maxOCSPResponseSize bounds the size of OCSP responses read from responders.
+	maxOCSPResponseSize = 1 << 20
+	// defaultOCSPCacheDuration is used for responses without next update.
+	defaultOCSPCacheDuration = time.Hour
+)
+
+type ocspResult struct {
+	status  Status
+	expires time.Time
+}
+
+// checkOCSP queries the OCSP responders of the certificate. Definite results are cached until their next update.
+func (c *Checker) checkOCSP(cert, issuer *x509.Certificate) (Status, error) {
+	if len(cert.OCSPServer) == 0 {
+		return StatusUnknown, errors.New("certificate has no OCSP responder")
+	}
+
+	key := string(issuer.RawSubject) + "/" + cert.SerialNumber.String()
+	now := c.now()
+	c.mu.Lock()
+	cached, ok := c.ocspCache[key]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.status, nil
+	}
+
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return StatusUnknown, fmt.Errorf("failed to create OCSP request: %w", err)
+	}
+
+	var errs []error
+	for _, server := range cert.OCSPServer {
+		resp, err := c.queryOCSP(server, request, cert, issuer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var status Status
+		switch resp.Status {
+		case ocsp.Good:
+			status = StatusGood
+		case ocsp.Revoked:
+			status = StatusRevoked
+		default:
+			errs = append(errs, fmt.Errorf("OCSP responder %s does not know the certificate", server))
+			continue
+		}
+
+		expires := resp.NextUpdate
+		if expires.IsZero() {
+			expires = now.Add(defaultOCSPCacheDuration)
+		}
+		c.mu.Lock()
+		c.ocspCache[key] = ocspResult{status: status, expires: expires}
+		c.mu.Unlock()
+		return status, nil
+	}
+	return StatusUnknown, errors.Join(errs...)
+}
+
+func (c *Checker) queryOCSP(server string, request []byte, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	httpResp, err := c.ocspClient.Post(server, mimeOCSPRequest, bytes.NewReader(request)) //#nosec G107 -- The URL is the OCSP responder of a verified certificate.
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OCSP responder %s: %w", server, err)
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder %s returned status %d", server, httpResp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxOCSPResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of OCSP responder %s: %w", server, err)
+	}
+
+	resp, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response of OCSP responder %s: %w", server, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// Status is the revocation status of a certificate.
+type Status string
+
+const (
+	// StatusGood means that the certificate is not revoked.
+	StatusGood Status = "good"
+	// StatusRevoked means that the certificate is revoked.
+	StatusRevoked Status = "revoked"
+	// StatusUnknown means that the revocation status could not be determined,
+	// e.g. because no valid CRL of the issuer is loaded or the OCSP responder is unreachable.
+	StatusUnknown Status = "unknown"
+)
+
+// ErrRevoked is returned by [Checker.VerifyConnection] for revoked certificates.
+var ErrRevoked = errors.New("certificate is revoked")
+
+// Checker checks the revocation status of the certificates of TLS connections via CRLs and OCSP.
+type Checker struct {
+	logger     logr.Logger
+	crlFiles   []string
+	crls       atomic.Pointer[[]*x509.RevocationList]
+	ocsp       bool
+	ocspClient *http.Client
+	failClosed bool
+	// certificate and output label the revocation check metric.
+	certificate string
+	output      string
+	now         func() time.Time
+
+	mu sync.Mutex
+	// ocspCache caches OCSP results per issuer and serial number until their next update.
+	ocspCache map[string]ocspResult
+}
+
+// New creates a new [Checker] and loads the configured CRLs.
+// The certificate and output are used to label the revocation check metric.
+func New(logger logr.Logger, config *configv1alpha1.Revocation, certificate, output string) (*Checker, error) {
+	c := &Checker{
+		logger:      logger,
+		crlFiles:    config.CRLFiles,
+		ocsp:        config.OCSP,
+		ocspClient:  &http.Client{Timeout: 5 * time.Second},
+		failClosed:  config.FailurePolicy != configv1alpha1.RevocationFailurePolicyFailOpen,
+		certificate: certificate,
+		output:      output,
+		now:         time.Now,
+		ocspCache:   make(map[string]ocspResult),
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Files returns the configured CRL files.
+func (c *Checker) Files() []string {
+	return c.crlFiles
+}
+
+// Reload re-reads the CRL files. On failure, the previously loaded CRLs are kept.
+func (c *Checker) Reload() error {
+	var crls []*x509.RevocationList
+	for _, file := range c.crlFiles {
+		loaded, err := loadCRLs(file)
+		if err != nil {
+			return err
+		}
+		crls = append(crls, loaded...)
+	}
+	c.crls.Store(&crls)
+	return nil
+}
+
+// loadCRLs reads PEM or DER encoded CRLs from the given file.
+func loadCRLs(file string) ([]*x509.RevocationList, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CRL file: %w", err)
+	}
+
+	if block, _ := pem.Decode(data); block == nil {
+		crl, err := x509.ParseRevocationList(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CRL from %s: %w", file, err)
+		}
+		return []*x509.RevocationList{crl}, nil
+	}
+
+	var crls []*x509.RevocationList
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CRL from %s: %w", file, err)
+		}
+		crls = append(crls, crl)
+	}
+	if len(crls) == 0 {
+		return nil, fmt.Errorf("no CRL found in %s", file)
+	}
+	return crls, nil
+}
+
+// VerifyConnection checks the revocation status of all certificates of the verified chains except the roots.
+// It can be used as [tls.Config.VerifyConnection] on both, server and client side.
+// Revoked certificates are always rejected, certificates with unknown status only if failing closed.
+func (c *Checker) VerifyConnection(state tls.ConnectionState) error {
+	for _, chain := range state.VerifiedChains {
+		for i := 0; i+1 < len(chain); i++ {
+			if err := c.verify(chain[i], chain[i+1]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Checker) verify(cert, issuer *x509.Certificate) error {
+	status, err := c.Check(cert, issuer)
+	metrics.RevocationChecks.WithLabelValues(c.certificate, c.output, string(status)).Inc()
+
+	switch status {
+	case StatusRevoked:
+		return fmt.Errorf("%w: serial number %s, subject %q", ErrRevoked, cert.SerialNumber, cert.Subject)
+	case StatusUnknown:
+		if c.failClosed {
+			return fmt.Errorf("revocation status of certificate with serial number %s, subject %q is unknown: %w", cert.SerialNumber, cert.Subject, err)
+		}
+		c.logger.Info("Accepting certificate with unknown revocation status", "serialNumber", cert.SerialNumber.String(), "subject", cert.Subject.String(), "reason", err.Error())
+	}
+	return nil
+}
+
+// Check returns the revocation status of the certificate issued by issuer.
+// The CRLs are checked first, OCSP is only used if they do not determine the status.
+// For an unknown status, the returned error describes why.
+func (c *Checker) Check(cert, issuer *x509.Certificate) (Status, error) {
+	status, err := c.checkCRLs(cert, issuer)
+	if status != StatusUnknown || !c.ocsp {
+		return status, err
+	}
+
+	ocspStatus, ocspErr := c.checkOCSP(cert, issuer)
+	if ocspStatus != StatusUnknown {
+		return ocspStatus, nil
+	}
+	return StatusUnknown, errors.Join(err, ocspErr)
+}
+
+// checkCRLs checks the certificate against all loaded CRLs of its issuer. It is only considered good if
+// none of them revokes it and at least one of them is valid.
+func (c *Checker) checkCRLs(cert, issuer *x509.Certificate) (Status, error) {
+	if len(c.crlFiles) == 0 {
+		return StatusUnknown, errors.New("no CRL configured")
+	}
+
+	now := c.now()
+	var (
+		errs  []error
+		valid bool
+	)
+	for _, crl := range *c.crls.Load() {
+		if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) {
+			continue
+		}
+		if err := crl.CheckSignatureFrom(issuer); err != nil {
+			errs = append(errs, fmt.Errorf("invalid CRL signature: %w", err))
+			continue
+		}
+		if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+			errs = append(errs, fmt.Errorf("CRL of issuer %q expired at %s", issuer.Subject, crl.NextUpdate.Format(time.RFC3339)))
+			continue
+		}
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return StatusRevoked, nil
+			}
+		}
+		valid = true
+	}
+
+	if valid {
+		return StatusGood, nil
+	}
+	if len(errs) == 0 {
+		return StatusUnknown, fmt.Errorf("no CRL of issuer %q loaded", issuer.Subject)
+	}
+	return StatusUnknown, errors.Join(errs...)
+}
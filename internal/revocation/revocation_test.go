@@ -0,0 +1,316 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/gardener/auditlog-forwarder/internal/metrics"
+	. "github.com/gardener/auditlog-forwarder/internal/revocation"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+type testCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestCA(cn string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return &testCA{key: key, cert: cert}
+}
+
+func (ca *testCA) issue(serial int64, ocspServers ...string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		OCSPServer:   ocspServers,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return cert
+}
+
+// crl creates a PEM encoded CRL revoking the given serial numbers.
+func (ca *testCA) crl(nextUpdate time.Time, revoked ...int64) []byte {
+	var entries []x509.RevocationListEntry
+	for _, serial := range revoked {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(time.Now().UnixNano()),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                nextUpdate,
+		RevokedCertificateEntries: entries,
+	}, ca.cert, ca.key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+var _ = Describe("Checker", func() {
+	var (
+		ca      *testCA
+		crlFile string
+		config  *configv1alpha1.Revocation
+		output  string
+	)
+
+	BeforeEach(func() {
+		ca = newTestCA("Test CA")
+		crlFile = filepath.Join(GinkgoT().TempDir(), "ca.crl")
+		Expect(os.WriteFile(crlFile, ca.crl(time.Now().Add(time.Hour), 2), 0600)).To(Succeed())
+		config = &configv1alpha1.Revocation{
+			CRLFiles:      []string{crlFile},
+			FailurePolicy: configv1alpha1.RevocationFailurePolicyFailClosed,
+		}
+		// The output label separates the metrics of the tests.
+		output = CurrentSpecReport().LeafNodeText
+	})
+
+	newChecker := func() *Checker {
+		checker, err := New(logr.Discard(), config, metrics.CertificateClient, output)
+		Expect(err).NotTo(HaveOccurred())
+		return checker
+	}
+
+	connection := func(cert *x509.Certificate) tls.ConnectionState {
+		return tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca.cert}}}
+	}
+
+	checks := func(result Status) float64 {
+		return testutil.ToFloat64(metrics.RevocationChecks.WithLabelValues(metrics.CertificateClient, output, string(result)))
+	}
+
+	Describe("CRL", func() {
+		It("should accept certificates which are not revoked", func() {
+			checker := newChecker()
+
+			Expect(checker.VerifyConnection(connection(ca.issue(1)))).To(Succeed())
+			Expect(checks(StatusGood)).To(Equal(1.0))
+		})
+
+		It("should reject revoked certificates", func() {
+			checker := newChecker()
+
+			Expect(checker.VerifyConnection(connection(ca.issue(2)))).To(MatchError(ErrRevoked))
+			Expect(checks(StatusRevoked)).To(Equal(1.0))
+		})
+
+		It("should reject revoked certificates when failing open", func() {
+			config.FailurePolicy = configv1alpha1.RevocationFailurePolicyFailOpen
+			checker := newChecker()
+
+			Expect(checker.VerifyConnection(connection(ca.issue(2)))).To(MatchError(ErrRevoked))
+		})
+
+		It("should reject certificates revoked by any of the CRLs of the issuer", func() {
+			deltaFile := filepath.Join(GinkgoT().TempDir(), "delta.crl")
+			Expect(os.WriteFile(deltaFile, ca.crl(time.Now().Add(time.Hour), 3), 0600)).To(Succeed())
+			config.CRLFiles = append(config.CRLFiles, deltaFile)
+			checker := newChecker()
+
+			Expect(checker.Check(ca.issue(2), ca.cert)).To(Equal(StatusRevoked))
+			Expect(checker.Check(ca.issue(3), ca.cert)).To(Equal(StatusRevoked))
+			Expect(checker.Check(ca.issue(1), ca.cert)).To(Equal(StatusGood))
+		})
+
+		It("should load DER encoded CRLs", func() {
+			block, _ := pem.Decode(ca.crl(time.Now().Add(time.Hour), 3))
+			Expect(os.WriteFile(crlFile, block.Bytes, 0600)).To(Succeed())
+			checker := newChecker()
+
+			Expect(checker.Check(ca.issue(3), ca.cert)).To(Equal(StatusRevoked))
+		})
+
+		It("should fail for invalid CRL files", func() {
+			Expect(os.WriteFile(crlFile, []byte("invalid"), 0600)).To(Succeed())
+
+			_, err := New(logr.Discard(), config, metrics.CertificateClient, output)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse CRL")))
+		})
+
+		Context("with unknown revocation status", func() {
+			It("should reject certificates of issuers without CRL when failing closed", func() {
+				other := newTestCA("Other CA")
+				checker := newChecker()
+
+				err := checker.VerifyConnection(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{other.issue(1), other.cert}}})
+				Expect(err).To(MatchError(ContainSubstring(`no CRL of issuer "CN=Other CA" loaded`)))
+				Expect(checks(StatusUnknown)).To(Equal(1.0))
+			})
+
+			It("should reject certificates if the CRL is expired when failing closed", func() {
+				Expect(os.WriteFile(crlFile, ca.crl(time.Now().Add(-time.Second)), 0600)).To(Succeed())
+				checker := newChecker()
+
+				Expect(checker.VerifyConnection(connection(ca.issue(1)))).To(MatchError(ContainSubstring("expired")))
+			})
+
+			It("should reject certificates if the CRL is not signed by the issuer when failing closed", func() {
+				impostor := newTestCA("Test CA")
+				Expect(os.WriteFile(crlFile, impostor.crl(time.Now().Add(time.Hour)), 0600)).To(Succeed())
+				checker := newChecker()
+
+				Expect(checker.VerifyConnection(connection(ca.issue(1)))).To(MatchError(ContainSubstring("invalid CRL signature")))
+			})
+
+			It("should accept certificates when failing open", func() {
+				config.FailurePolicy = configv1alpha1.RevocationFailurePolicyFailOpen
+				Expect(os.WriteFile(crlFile, ca.crl(time.Now().Add(-time.Second)), 0600)).To(Succeed())
+				checker := newChecker()
+
+				Expect(checker.VerifyConnection(connection(ca.issue(1)))).To(Succeed())
+				Expect(checks(StatusUnknown)).To(Equal(1.0))
+			})
+		})
+
+		It("should reload the CRLs when the files change", func() {
+			checker := newChecker()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Expect(checker.Watch(ctx, 10*time.Millisecond)).To(Succeed())
+
+			Expect(checker.Check(ca.issue(1), ca.cert)).To(Equal(StatusGood))
+
+			Expect(os.WriteFile(crlFile, ca.crl(time.Now().Add(time.Hour), 1, 2), 0600)).To(Succeed())
+			Eventually(func() Status {
+				status, _ := checker.Check(ca.issue(1), ca.cert)
+				return status
+			}).Should(Equal(StatusRevoked))
+		})
+
+		It("should keep the CRLs if the changed files are invalid", func() {
+			checker := newChecker()
+
+			Expect(os.WriteFile(crlFile, []byte("invalid"), 0600)).To(Succeed())
+			Expect(checker.Reload()).NotTo(Succeed())
+			Expect(checker.Check(ca.issue(2), ca.cert)).To(Equal(StatusRevoked))
+		})
+	})
+
+	Describe("OCSP", func() {
+		var (
+			responder *httptest.Server
+			status    atomic.Int32
+			requests  atomic.Int32
+		)
+
+		BeforeEach(func() {
+			config = &configv1alpha1.Revocation{OCSP: true, FailurePolicy: configv1alpha1.RevocationFailurePolicyFailClosed}
+			status.Store(int32(ocsp.Good))
+			requests.Store(0)
+
+			responder = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				body, err := io.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				req, err := ocsp.ParseRequest(body)
+				Expect(err).NotTo(HaveOccurred())
+
+				resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+					Status:       int(status.Load()),
+					SerialNumber: req.SerialNumber,
+					ThisUpdate:   time.Now().Add(-time.Minute),
+					NextUpdate:   time.Now().Add(time.Hour),
+					RevokedAt:    time.Now().Add(-time.Minute),
+				}, crypto.Signer(ca.key))
+				Expect(err).NotTo(HaveOccurred())
+				_, _ = w.Write(resp)
+			}))
+			DeferCleanup(responder.Close)
+		})
+
+		It("should accept certificates which are good and cache the result", func() {
+			checker := newChecker()
+			cert := ca.issue(1, responder.URL)
+
+			Expect(checker.VerifyConnection(connection(cert))).To(Succeed())
+			Expect(checker.VerifyConnection(connection(cert))).To(Succeed())
+			Expect(requests.Load()).To(Equal(int32(1)))
+			Expect(checks(StatusGood)).To(Equal(2.0))
+		})
+
+		It("should reject revoked certificates", func() {
+			status.Store(int32(ocsp.Revoked))
+			checker := newChecker()
+
+			Expect(checker.VerifyConnection(connection(ca.issue(1, responder.URL)))).To(MatchError(ErrRevoked))
+		})
+
+		It("should only query the responder if the CRLs do not determine the status", func() {
+			config.CRLFiles = []string{crlFile}
+			checker := newChecker()
+
+			Expect(checker.Check(ca.issue(2, responder.URL), ca.cert)).To(Equal(StatusRevoked))
+			Expect(requests.Load()).To(BeZero())
+
+			other := newTestCA("Other CA")
+			_, err := checker.Check(other.issue(1, responder.URL), other.cert)
+			Expect(err).To(MatchError(ContainSubstring(`no CRL of issuer "CN=Other CA" loaded`)))
+			Expect(requests.Load()).To(Equal(int32(1)))
+		})
+
+		It("should handle unknown status, unreachable responders and certificates without responder according to the failure policy", func() {
+			status.Store(int32(ocsp.Unknown))
+			checker := newChecker()
+			Expect(checker.VerifyConnection(connection(ca.issue(1, responder.URL)))).To(MatchError(ContainSubstring("does not know the certificate")))
+
+			responder.Close()
+			Expect(checker.VerifyConnection(connection(ca.issue(2, responder.URL)))).To(MatchError(ContainSubstring("failed to query OCSP responder")))
+			Expect(checker.VerifyConnection(connection(ca.issue(3)))).To(MatchError(ContainSubstring("certificate has no OCSP responder")))
+
+			config.FailurePolicy = configv1alpha1.RevocationFailurePolicyFailOpen
+			checker = newChecker()
+			Expect(checker.VerifyConnection(connection(ca.issue(2, responder.URL)))).To(Succeed())
+			Expect(checks(StatusUnknown)).To(Equal(4.0))
+		})
+	})
+})
@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package revocation
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/auditlog-forwarder/internal/filewatch"
+)
+
+// Watch reloads the CRLs when the CRL files change until ctx is done.
+// Filesystem events are coalesced for the debounce duration.
+func (c *Checker) Watch(ctx context.Context, debounce time.Duration) error {
+	_, err := filewatch.Watch(ctx, c.logger, c.crlFiles, debounce, func() {
+		if err := c.Reload(); err != nil {
+			c.logger.Error(err, "Failed to reload CRLs, keeping existing CRLs")
+			return
+		}
+		c.logger.Info("Reloaded CRLs")
+	})
+	return err
+}
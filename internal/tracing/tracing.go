@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
+	"k8s.io/component-base/version"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+const (
+	// TracerName is the name of the tracer used to instrument the auditlog forwarder.
+	TracerName = "github.com/gardener/auditlog-forwarder"
+
+	serviceName = "auditlog-forwarder"
+)
+
+// Attribute keys used by the spans of the auditlog forwarder.
+const (
+	AttributeRequestID    = attribute.Key("req_id")
+	AttributeEventCount   = attribute.Key("audit.event_count")
+	AttributeProcessor    = attribute.Key("processor.name")
+	AttributeOutput       = attribute.Key("output.name")
+	AttributeDeliveryMode = attribute.Key("output.delivery_mode")
+	AttributeAttempt      = attribute.Key("output.attempt")
+)
+
+// Tracer returns the tracer of the auditlog forwarder from the global tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Setup configures the W3C trace context propagator and, if tracing is configured, a global tracer provider
+// exporting spans to the OTLP collector. Without configuration spans are not recorded, but the trace context
+// of incoming requests is still propagated to the outputs.
+// The returned function flushes the remaining spans and stops the export.
+func Setup(ctx context.Context, config *configv1alpha1.Tracing) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if config == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	provider, err := NewTracerProvider(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider exporting spans to the OTLP collector of the given configuration.
+func NewTracerProvider(ctx context.Context, config *configv1alpha1.Tracing) (*sdktrace.TracerProvider, error) {
+	exporter, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version.Get().GitVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	ratio := 1.0
+	if config.SamplingPercentage != nil {
+		ratio = float64(*config.SamplingPercentage) / 100
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	), nil
+}
+
+func newExporter(ctx context.Context, config *configv1alpha1.Tracing) (sdktrace.SpanExporter, error) {
+	var tlsConfig *tls.Config
+	if !config.Insecure {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if config.CAFile != "" {
+			caCert, err := os.ReadFile(filepath.Clean(config.CAFile))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caCert) {
+				return nil, fmt.Errorf("failed to parse CA certificate from %s", config.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	switch config.Protocol {
+	case configv1alpha1.TracingProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+		if tlsConfig == nil {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case configv1alpha1.TracingProtocolGRPC, "":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+		if tlsConfig == nil {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing protocol %q", config.Protocol)
+	}
+}
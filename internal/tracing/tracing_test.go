@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing_test
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/auditlog-forwarder/internal/tracing"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Tracing", func() {
+	Describe("#Setup", func() {
+		It("should configure the W3C trace context propagator without a tracer provider", func() {
+			shutdown, err := tracing.Setup(context.Background(), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otel.GetTextMapPropagator().Fields()).To(ContainElements("traceparent", "tracestate", "baggage"))
+			Expect(shutdown(context.Background())).To(Succeed())
+		})
+	})
+
+	Describe("#NewTracerProvider", func() {
+		DescribeTable("should create a tracer provider for each protocol",
+			func(protocol configv1alpha1.TracingProtocol) {
+				provider, err := tracing.NewTracerProvider(context.Background(), &configv1alpha1.Tracing{
+					Endpoint:           "localhost:4317",
+					Protocol:           protocol,
+					Insecure:           true,
+					SamplingPercentage: ptr.To[int32](50),
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(provider.Shutdown(context.Background())).To(Succeed())
+			},
+			Entry("gRPC", configv1alpha1.TracingProtocolGRPC),
+			Entry("HTTP", configv1alpha1.TracingProtocolHTTP),
+		)
+
+		It("should fail when the CA file cannot be read", func() {
+			_, err := tracing.NewTracerProvider(context.Background(), &configv1alpha1.Tracing{
+				Endpoint: "localhost:4317",
+				Protocol: configv1alpha1.TracingProtocolGRPC,
+				CAFile:   filepath.Join(GinkgoT().TempDir(), "missing"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("failed to read CA file")))
+		})
+
+		It("should fail for an unsupported protocol", func() {
+			_, err := tracing.NewTracerProvider(context.Background(), &configv1alpha1.Tracing{
+				Endpoint: "localhost:4317",
+				Protocol: "thrift",
+			})
+			Expect(err).To(MatchError(ContainSubstring(`unsupported tracing protocol "thrift"`)))
+		})
+	})
+})
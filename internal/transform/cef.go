@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transform
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+const (
+	cefVendor  = "Kubernetes"
+	cefProduct = "kube-apiserver"
+	cefVersion = "v1"
+
+	cefSeverityLow    = 3
+	cefSeverityMedium = 5
+	cefSeverityHigh   = 7
+)
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+// CEF transforms the audit event into an ArcSight Common Event Format line without trailing newline.
+func CEF(event *audit.Event) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("CEF:0")
+	for _, field := range []string{cefVendor, cefProduct, cefVersion, event.Verb, cefName(event), strconv.Itoa(cefSeverity(event))} {
+		b.WriteByte('|')
+		b.WriteString(cefHeaderEscaper.Replace(field))
+	}
+	b.WriteByte('|')
+
+	ext := cefExtension{builder: &b}
+	if t := eventTime(event); !t.IsZero() {
+		ext.add("rt", strconv.FormatInt(t.UnixMilli(), 10))
+	}
+	if !event.RequestReceivedTimestamp.IsZero() {
+		ext.add("start", strconv.FormatInt(event.RequestReceivedTimestamp.UnixMilli(), 10))
+	}
+	ext.add("externalId", string(event.AuditID))
+	ext.add("act", event.Verb)
+	ext.add("outcome", string(eventOutcome(event)))
+	ext.add("suser", event.User.Username)
+	ext.add("suid", event.User.UID)
+	if event.ImpersonatedUser != nil {
+		ext.add("duser", event.ImpersonatedUser.Username)
+		ext.add("duid", event.ImpersonatedUser.UID)
+	}
+	if len(event.SourceIPs) > 0 {
+		ext.add("src", event.SourceIPs[0])
+	}
+	ext.add("requestClientApplication", event.UserAgent)
+	ext.add("request", event.RequestURI)
+	if ref := event.ObjectRef; ref != nil {
+		ext.addCustom("cs1", "namespace", ref.Namespace)
+		ext.addCustom("cs2", "resource", resourceType(ref))
+		ext.addCustom("cs3", "name", ref.Name)
+		ext.addCustom("cs4", "apiVersion", groupVersion(ref))
+	}
+	ext.addCustom("cs5", "stage", string(event.Stage))
+	ext.addCustom("cs6", "level", string(event.Level))
+	if code := responseCode(event); code != 0 {
+		ext.addCustom("cn1", "responseCode", strconv.Itoa(code))
+	}
+	if event.ResponseStatus != nil {
+		ext.add("reason", string(event.ResponseStatus.Reason))
+		ext.add("msg", event.ResponseStatus.Message)
+	}
+
+	return []byte(b.String()), nil
+}
+
+// cefName returns the human-readable name of the event, e.g. "create pods" or "get /healthz".
+func cefName(event *audit.Event) string {
+	if event.ObjectRef != nil && event.ObjectRef.Resource != "" {
+		return event.Verb + " " + resourceType(event.ObjectRef)
+	}
+	path, _ := splitRequestURI(event.RequestURI)
+	return strings.TrimSpace(event.Verb + " " + path)
+}
+
+// cefSeverity returns the severity of the event. Denied requests are rated high, other failures medium.
+func cefSeverity(event *audit.Event) int {
+	switch code := responseCode(event); {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return cefSeverityHigh
+	case code >= http.StatusBadRequest:
+		return cefSeverityMedium
+	default:
+		return cefSeverityLow
+	}
+}
+
+// cefExtension writes space-separated key-value pairs of the CEF extension, skipping empty values.
+type cefExtension struct {
+	builder *strings.Builder
+	started bool
+}
+
+// add adds an extension field.
+func (e *cefExtension) add(key, value string) {
+	if value == "" {
+		return
+	}
+	if e.started {
+		e.builder.WriteByte(' ')
+	}
+	e.started = true
+	e.builder.WriteString(key)
+	e.builder.WriteByte('=')
+	e.builder.WriteString(cefExtensionEscaper.Replace(value))
+}
+
+// addCustom adds a custom extension field together with its label.
+func (e *cefExtension) addCustom(key, label, value string) {
+	if value == "" {
+		return
+	}
+	e.add(key+"Label", label)
+	e.add(key, value)
+}
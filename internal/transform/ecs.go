@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transform
+
+import (
+	"encoding/json"
+	"time"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/utils/ptr"
+)
+
+// ECSVersion is the version of the Elastic Common Schema the documents conform to.
+const ECSVersion = "8.11.0"
+
+// ecsDocument is an Elastic Common Schema document of an audit event.
+type ecsDocument struct {
+	Timestamp    time.Time       `json:"@timestamp"`
+	ECS          ecsVersion      `json:"ecs"`
+	Event        ecsEvent        `json:"event"`
+	User         *ecsUser        `json:"user,omitempty"`
+	Source       *ecsSource      `json:"source,omitempty"`
+	Related      *ecsRelated     `json:"related,omitempty"`
+	UserAgent    *ecsUserAgent   `json:"user_agent,omitempty"`
+	URL          *ecsURL         `json:"url,omitempty"`
+	HTTP         *ecsHTTP        `json:"http,omitempty"`
+	Orchestrator ecsOrchestrator `json:"orchestrator"`
+	Kubernetes   ecsKubernetes   `json:"kubernetes"`
+}
+
+type ecsVersion struct {
+	Version string `json:"version"`
+}
+
+type ecsEvent struct {
+	Kind     string     `json:"kind"`
+	Category []string   `json:"category"`
+	Type     []string   `json:"type"`
+	Action   string     `json:"action"`
+	Outcome  outcome    `json:"outcome"`
+	ID       string     `json:"id"`
+	Dataset  string     `json:"dataset"`
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+}
+
+type ecsUser struct {
+	Name      string   `json:"name,omitempty"`
+	ID        string   `json:"id,omitempty"`
+	Effective *ecsUser `json:"effective,omitempty"`
+}
+
+type ecsSource struct {
+	IP string `json:"ip"`
+}
+
+type ecsRelated struct {
+	IP   []string `json:"ip,omitempty"`
+	User []string `json:"user,omitempty"`
+}
+
+type ecsUserAgent struct {
+	Original string `json:"original"`
+}
+
+type ecsURL struct {
+	Original string `json:"original"`
+	Path     string `json:"path,omitempty"`
+	Query    string `json:"query,omitempty"`
+}
+
+type ecsHTTP struct {
+	Response ecsHTTPResponse `json:"response"`
+}
+
+type ecsHTTPResponse struct {
+	StatusCode int `json:"status_code"`
+}
+
+type ecsOrchestrator struct {
+	Type       string                   `json:"type"`
+	Namespace  string                   `json:"namespace,omitempty"`
+	APIVersion string                   `json:"api_version,omitempty"`
+	Resource   *ecsOrchestratorResource `json:"resource,omitempty"`
+}
+
+type ecsOrchestratorResource struct {
+	Type string `json:"type,omitempty"`
+	Name string `json:"name,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+type ecsKubernetes struct {
+	Audit ecsKubernetesAudit `json:"audit"`
+}
+
+// ecsKubernetesAudit holds the fields of the audit event which have no equivalent in the Elastic Common Schema.
+type ecsKubernetesAudit struct {
+	Level            audit.Level       `json:"level"`
+	Stage            audit.Stage       `json:"stage"`
+	User             *ecsAuditUser     `json:"user,omitempty"`
+	ImpersonatedUser *ecsAuditUser     `json:"impersonatedUser,omitempty"`
+	ObjectRef        *ecsAuditObject   `json:"objectRef,omitempty"`
+	ResponseStatus   *ecsAuditStatus   `json:"responseStatus,omitempty"`
+	RequestObject    json.RawMessage   `json:"requestObject,omitempty"`
+	ResponseObject   json.RawMessage   `json:"responseObject,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+}
+
+type ecsAuditUser struct {
+	Groups []string `json:"groups,omitempty"`
+}
+
+type ecsAuditObject struct {
+	APIGroup        string `json:"apiGroup,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Subresource     string `json:"subresource,omitempty"`
+}
+
+type ecsAuditStatus struct {
+	Status  string `json:"status,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ecsEventTypes are the values of "event.type" per activity.
+var ecsEventTypes = map[activity][]string{
+	activityCreate: {"creation"},
+	activityRead:   {"access"},
+	activityUpdate: {"change"},
+	activityDelete: {"deletion"},
+	activityOther:  {"info"},
+}
+
+// ECS transforms the audit event into an Elastic Common Schema document.
+func ECS(event *audit.Event) ([]byte, error) {
+	doc := ecsDocument{
+		Timestamp: eventTime(event),
+		ECS:       ecsVersion{Version: ECSVersion},
+		Event: ecsEvent{
+			Kind:     "event",
+			Category: []string{"api"},
+			Type:     ecsEventTypes[verbActivity(event.Verb)],
+			Action:   event.Verb,
+			Outcome:  eventOutcome(event),
+			ID:       string(event.AuditID),
+			Dataset:  "kubernetes.audit",
+		},
+		Orchestrator: ecsOrchestrator{Type: "kubernetes"},
+		Kubernetes: ecsKubernetes{Audit: ecsKubernetesAudit{
+			Level:       event.Level,
+			Stage:       event.Stage,
+			Annotations: event.Annotations,
+		}},
+	}
+	if !event.RequestReceivedTimestamp.IsZero() {
+		doc.Event.Start = ptr.To(event.RequestReceivedTimestamp.UTC())
+	}
+	if event.Stage == audit.StageResponseComplete && !event.StageTimestamp.IsZero() {
+		doc.Event.End = ptr.To(event.StageTimestamp.UTC())
+	}
+
+	if event.User.Username != "" || event.User.UID != "" {
+		doc.User = &ecsUser{Name: event.User.Username, ID: event.User.UID}
+	}
+	if len(event.User.Groups) > 0 {
+		doc.Kubernetes.Audit.User = &ecsAuditUser{Groups: event.User.Groups}
+	}
+	if impersonated := event.ImpersonatedUser; impersonated != nil {
+		if doc.User == nil {
+			doc.User = &ecsUser{}
+		}
+		doc.User.Effective = &ecsUser{Name: impersonated.Username, ID: impersonated.UID}
+		if len(impersonated.Groups) > 0 {
+			doc.Kubernetes.Audit.ImpersonatedUser = &ecsAuditUser{Groups: impersonated.Groups}
+		}
+	}
+
+	related := &ecsRelated{IP: event.SourceIPs}
+	if doc.User != nil && doc.User.Name != "" {
+		related.User = append(related.User, doc.User.Name)
+	}
+	if doc.User != nil && doc.User.Effective != nil && doc.User.Effective.Name != "" {
+		related.User = append(related.User, doc.User.Effective.Name)
+	}
+	if len(related.IP) > 0 || len(related.User) > 0 {
+		doc.Related = related
+	}
+	if len(event.SourceIPs) > 0 {
+		doc.Source = &ecsSource{IP: event.SourceIPs[0]}
+	}
+
+	if event.UserAgent != "" {
+		doc.UserAgent = &ecsUserAgent{Original: event.UserAgent}
+	}
+	if event.RequestURI != "" {
+		path, query := splitRequestURI(event.RequestURI)
+		doc.URL = &ecsURL{Original: event.RequestURI, Path: path, Query: query}
+	}
+
+	if status := event.ResponseStatus; status != nil {
+		if status.Code != 0 {
+			doc.HTTP = &ecsHTTP{Response: ecsHTTPResponse{StatusCode: int(status.Code)}}
+		}
+		if status.Status != "" || status.Reason != "" || status.Message != "" {
+			doc.Kubernetes.Audit.ResponseStatus = &ecsAuditStatus{
+				Status:  status.Status,
+				Reason:  string(status.Reason),
+				Message: status.Message,
+			}
+		}
+	}
+
+	if ref := event.ObjectRef; ref != nil {
+		doc.Orchestrator.Namespace = ref.Namespace
+		doc.Orchestrator.APIVersion = groupVersion(ref)
+		doc.Orchestrator.Resource = &ecsOrchestratorResource{
+			Type: resourceType(ref),
+			Name: ref.Name,
+			ID:   string(ref.UID),
+		}
+		if ref.APIGroup != "" || ref.ResourceVersion != "" || ref.Subresource != "" {
+			doc.Kubernetes.Audit.ObjectRef = &ecsAuditObject{
+				APIGroup:        ref.APIGroup,
+				ResourceVersion: ref.ResourceVersion,
+				Subresource:     ref.Subresource,
+			}
+		}
+	}
+
+	if event.RequestObject != nil {
+		doc.Kubernetes.Audit.RequestObject = event.RequestObject.Raw
+	}
+	if event.ResponseObject != nil {
+		doc.Kubernetes.Audit.ResponseObject = event.ResponseObject.Raw
+	}
+
+	return json.Marshal(doc)
+}
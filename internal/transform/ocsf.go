@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transform
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// OCSFVersion is the version of the Open Cybersecurity Schema Framework the events conform to.
+	OCSFVersion = "1.3.0"
+
+	ocsfCategoryUID  = 6
+	ocsfCategoryName = "Application Activity"
+	ocsfClassUID     = 6003
+	ocsfClassName    = "API Activity"
+)
+
+// ocsfAPIActivity is an OCSF API Activity event of an audit event.
+type ocsfAPIActivity struct {
+	ActivityID   int              `json:"activity_id"`
+	ActivityName string           `json:"activity_name"`
+	CategoryUID  int              `json:"category_uid"`
+	CategoryName string           `json:"category_name"`
+	ClassUID     int              `json:"class_uid"`
+	ClassName    string           `json:"class_name"`
+	TypeUID      int              `json:"type_uid"`
+	TypeName     string           `json:"type_name"`
+	SeverityID   int              `json:"severity_id"`
+	Severity     string           `json:"severity"`
+	Time         int64            `json:"time"`
+	StartTime    int64            `json:"start_time,omitempty"`
+	StatusID     int              `json:"status_id"`
+	Status       string           `json:"status"`
+	StatusCode   string           `json:"status_code,omitempty"`
+	StatusDetail string           `json:"status_detail,omitempty"`
+	Metadata     ocsfMetadata     `json:"metadata"`
+	Actor        ocsfActor        `json:"actor"`
+	API          ocsfAPI          `json:"api"`
+	SrcEndpoint  ocsfEndpoint     `json:"src_endpoint"`
+	HTTPRequest  *ocsfHTTPRequest `json:"http_request,omitempty"`
+	Resources    []ocsfResource   `json:"resources,omitempty"`
+	Unmapped     ocsfUnmapped     `json:"unmapped"`
+}
+
+type ocsfMetadata struct {
+	Version string      `json:"version"`
+	UID     string      `json:"uid"`
+	Product ocsfProduct `json:"product"`
+}
+
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+}
+
+type ocsfActor struct {
+	User ocsfUser `json:"user"`
+}
+
+type ocsfUser struct {
+	Name   string      `json:"name,omitempty"`
+	UID    string      `json:"uid,omitempty"`
+	Groups []ocsfGroup `json:"groups,omitempty"`
+}
+
+type ocsfGroup struct {
+	Name string `json:"name"`
+}
+
+type ocsfAPI struct {
+	Operation string           `json:"operation"`
+	Version   string           `json:"version,omitempty"`
+	Group     *ocsfGroup       `json:"group,omitempty"`
+	Request   ocsfAPIRequest   `json:"request"`
+	Response  *ocsfAPIResponse `json:"response,omitempty"`
+}
+
+type ocsfAPIRequest struct {
+	UID string `json:"uid"`
+}
+
+type ocsfAPIResponse struct {
+	Code    int    `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ocsfEndpoint struct {
+	IP string `json:"ip,omitempty"`
+}
+
+type ocsfHTTPRequest struct {
+	UserAgent string   `json:"user_agent,omitempty"`
+	URL       *ocsfURL `json:"url,omitempty"`
+}
+
+type ocsfURL struct {
+	Path        string `json:"path"`
+	QueryString string `json:"query_string,omitempty"`
+}
+
+type ocsfResource struct {
+	Name      string `json:"name,omitempty"`
+	Type      string `json:"type,omitempty"`
+	UID       string `json:"uid,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+// ocsfUnmapped holds the fields of the audit event which have no equivalent in the OCSF API Activity class.
+type ocsfUnmapped struct {
+	Level            audit.Level       `json:"level"`
+	Stage            audit.Stage       `json:"stage"`
+	SourceIPs        []string          `json:"sourceIPs,omitempty"`
+	ImpersonatedUser *ocsfUser         `json:"impersonatedUser,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+}
+
+// ocsfActivities are the activity IDs and names of the API Activity class per activity.
+var ocsfActivities = map[activity]struct {
+	id   int
+	name string
+}{
+	activityCreate: {1, "Create"},
+	activityRead:   {2, "Read"},
+	activityUpdate: {3, "Update"},
+	activityDelete: {4, "Delete"},
+	activityOther:  {99, "Other"},
+}
+
+// ocsfStatuses are the status IDs and names per outcome.
+var ocsfStatuses = map[outcome]struct {
+	id   int
+	name string
+}{
+	outcomeUnknown: {0, "Unknown"},
+	outcomeSuccess: {1, "Success"},
+	outcomeFailure: {2, "Failure"},
+}
+
+// OCSF transforms the audit event into an Open Cybersecurity Schema Framework API Activity event.
+func OCSF(event *audit.Event) ([]byte, error) {
+	act := ocsfActivities[verbActivity(event.Verb)]
+	status := ocsfStatuses[eventOutcome(event)]
+
+	ev := ocsfAPIActivity{
+		ActivityID:   act.id,
+		ActivityName: act.name,
+		CategoryUID:  ocsfCategoryUID,
+		CategoryName: ocsfCategoryName,
+		ClassUID:     ocsfClassUID,
+		ClassName:    ocsfClassName,
+		TypeUID:      ocsfClassUID*100 + act.id,
+		TypeName:     ocsfClassName + ": " + act.name,
+		SeverityID:   1,
+		Severity:     "Informational",
+		Time:         eventTime(event).UnixMilli(),
+		StatusID:     status.id,
+		Status:       status.name,
+		Metadata: ocsfMetadata{
+			Version: OCSFVersion,
+			UID:     string(event.AuditID),
+			Product: ocsfProduct{Name: "kube-apiserver", VendorName: "Kubernetes"},
+		},
+		Actor: ocsfActor{User: ocsfUserInfo(event.User.Username, event.User.UID, event.User.Groups)},
+		API: ocsfAPI{
+			Operation: event.Verb,
+			Request:   ocsfAPIRequest{UID: string(event.AuditID)},
+		},
+		Unmapped: ocsfUnmapped{
+			Level:       event.Level,
+			Stage:       event.Stage,
+			Annotations: event.Annotations,
+		},
+	}
+	if !event.RequestReceivedTimestamp.IsZero() {
+		ev.StartTime = event.RequestReceivedTimestamp.UnixMilli()
+	}
+
+	if len(event.SourceIPs) > 0 {
+		ev.SrcEndpoint.IP = event.SourceIPs[0]
+	}
+	if len(event.SourceIPs) > 1 {
+		ev.Unmapped.SourceIPs = event.SourceIPs
+	}
+	if impersonated := event.ImpersonatedUser; impersonated != nil {
+		ev.Unmapped.ImpersonatedUser = ptr.To(ocsfUserInfo(impersonated.Username, impersonated.UID, impersonated.Groups))
+	}
+
+	if s := event.ResponseStatus; s != nil {
+		if s.Code != 0 {
+			ev.StatusCode = strconv.Itoa(int(s.Code))
+		}
+		ev.StatusDetail = s.Message
+		ev.API.Response = &ocsfAPIResponse{
+			Code:    int(s.Code),
+			Error:   string(s.Reason),
+			Message: s.Message,
+		}
+	}
+
+	if event.UserAgent != "" || event.RequestURI != "" {
+		ev.HTTPRequest = &ocsfHTTPRequest{UserAgent: event.UserAgent}
+		if event.RequestURI != "" {
+			path, query := splitRequestURI(event.RequestURI)
+			ev.HTTPRequest.URL = &ocsfURL{Path: path, QueryString: query}
+		}
+	}
+
+	if ref := event.ObjectRef; ref != nil {
+		ev.API.Version = ref.APIVersion
+		if ref.APIGroup != "" {
+			ev.API.Group = &ocsfGroup{Name: ref.APIGroup}
+		}
+		ev.Resources = []ocsfResource{{
+			Name:      ref.Name,
+			Type:      resourceType(ref),
+			UID:       string(ref.UID),
+			Namespace: ref.Namespace,
+			Version:   ref.ResourceVersion,
+		}}
+	}
+
+	return json.Marshal(ev)
+}
+
+// ocsfUserInfo returns the OCSF user of the given Kubernetes user.
+func ocsfUserInfo(name, uid string, groups []string) ocsfUser {
+	user := ocsfUser{Name: name, UID: uid}
+	for _, group := range groups {
+		user.Groups = append(user.Groups, ocsfGroup{Name: group})
+	}
+	return user
+}
@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package transform converts Kubernetes audit events into the schemas of security information and event
+// management systems. The field mapping of each schema is documented in docs/schemas.md.
+package transform
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+// Func transforms a single audit event into a record of a schema.
+type Func func(event *audit.Event) ([]byte, error)
+
+// ForSchema returns the transformation into the given schema.
+// "KubernetesAudit" has no per-event transformation, as the events are forwarded unchanged.
+func ForSchema(schema configv1alpha1.Schema) (Func, error) {
+	switch schema {
+	case configv1alpha1.SchemaECS:
+		return ECS, nil
+	case configv1alpha1.SchemaOCSF:
+		return OCSF, nil
+	case configv1alpha1.SchemaCEF:
+		return CEF, nil
+	default:
+		return nil, fmt.Errorf("unsupported schema %q", schema)
+	}
+}
+
+// EventList transforms each event of the list into a record of the given schema.
+func EventList(schema configv1alpha1.Schema, eventList *audit.EventList) ([][]byte, error) {
+	transform, err := ForSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([][]byte, 0, len(eventList.Items))
+	for i := range eventList.Items {
+		record, err := transform(&eventList.Items[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform audit event %q into %s: %w", eventList.Items[i].AuditID, schema, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// activity classifies the verb of an audit event.
+type activity int
+
+const (
+	activityOther activity = iota
+	activityCreate
+	activityRead
+	activityUpdate
+	activityDelete
+)
+
+// verbActivity returns the activity performed by the given verb.
+func verbActivity(verb string) activity {
+	switch verb {
+	case "create":
+		return activityCreate
+	case "get", "list", "watch":
+		return activityRead
+	case "update", "patch":
+		return activityUpdate
+	case "delete", "deletecollection":
+		return activityDelete
+	default:
+		return activityOther
+	}
+}
+
+// outcome is the result of the request of an audit event.
+type outcome string
+
+const (
+	outcomeSuccess outcome = "success"
+	outcomeFailure outcome = "failure"
+	outcomeUnknown outcome = "unknown"
+)
+
+// eventOutcome returns the outcome of the request based on the response status code.
+// Events of the "RequestReceived" stage have no response status and therefore an unknown outcome.
+func eventOutcome(event *audit.Event) outcome {
+	code := responseCode(event)
+	switch {
+	case code == 0:
+		return outcomeUnknown
+	case code < http.StatusBadRequest:
+		return outcomeSuccess
+	default:
+		return outcomeFailure
+	}
+}
+
+// responseCode returns the response status code of the event or 0 if it has none.
+func responseCode(event *audit.Event) int {
+	if event.ResponseStatus == nil {
+		return 0
+	}
+	return int(event.ResponseStatus.Code)
+}
+
+// eventTime returns the stage timestamp of the event, or if unset the request received timestamp.
+func eventTime(event *audit.Event) time.Time {
+	if !event.StageTimestamp.IsZero() {
+		return event.StageTimestamp.UTC()
+	}
+	return event.RequestReceivedTimestamp.UTC()
+}
+
+// resourceType returns the resource of the event including its subresource, e.g. "pods/log".
+func resourceType(ref *audit.ObjectReference) string {
+	if ref.Subresource == "" {
+		return ref.Resource
+	}
+	return ref.Resource + "/" + ref.Subresource
+}
+
+// groupVersion returns the API group and version of the referenced object, e.g. "apps/v1" or "v1" for the core group.
+func groupVersion(ref *audit.ObjectReference) string {
+	if ref.APIGroup == "" {
+		return ref.APIVersion
+	}
+	if ref.APIVersion == "" {
+		return ref.APIGroup
+	}
+	return ref.APIGroup + "/" + ref.APIVersion
+}
+
+// splitRequestURI splits the request URI into its path and query.
+func splitRequestURI(requestURI string) (string, string) {
+	path, query, _ := strings.Cut(requestURI, "?")
+	return path, query
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transform_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/internal/helper"
+	"github.com/gardener/auditlog-forwarder/internal/transform"
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var _ = Describe("Transform", func() {
+	var eventList *audit.EventList
+
+	BeforeEach(func() {
+		data, err := os.ReadFile(filepath.Join("testdata", "events.json"))
+		Expect(err).NotTo(HaveOccurred())
+		eventList, err = helper.DecodeEventList(data)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("should match the golden file",
+		func(schema configv1alpha1.Schema, golden string) {
+			records, err := transform.EventList(schema, eventList)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(HaveLen(len(eventList.Items)))
+
+			actual := append(bytes.Join(records, []byte("\n")), '\n')
+			path := filepath.Join("testdata", golden)
+			if *update {
+				Expect(os.WriteFile(path, actual, 0o600)).To(Succeed())
+			}
+			expected, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(actual)).To(Equal(string(expected)))
+		},
+		Entry("ECS", configv1alpha1.SchemaECS, "ecs.golden"),
+		Entry("OCSF", configv1alpha1.SchemaOCSF, "ocsf.golden"),
+		Entry("CEF", configv1alpha1.SchemaCEF, "cef.golden"),
+	)
+
+	DescribeTable("should produce one JSON document per event",
+		func(schema configv1alpha1.Schema) {
+			records, err := transform.EventList(schema, eventList)
+			Expect(err).NotTo(HaveOccurred())
+			for _, record := range records {
+				Expect(json.Valid(record)).To(BeTrue())
+				Expect(record).NotTo(ContainSubstring("\n"))
+			}
+		},
+		Entry("ECS", configv1alpha1.SchemaECS),
+		Entry("OCSF", configv1alpha1.SchemaOCSF),
+	)
+
+	It("should escape CEF header and extension values", func() {
+		record, err := transform.CEF(&audit.Event{
+			Verb:       "get",
+			RequestURI: "/api|x",
+			UserAgent:  "agent=1\\2\nnext",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(record)).To(Equal(`CEF:0|Kubernetes|kube-apiserver|v1|get|get /api\|x|3|act=get outcome=unknown requestClientApplication=agent\=1\\2\nnext request=/api|x`))
+	})
+
+	It("should classify the verbs of events", func() {
+		for verb, activity := range map[string]string{
+			"create":           "Create",
+			"get":              "Read",
+			"watch":            "Read",
+			"update":           "Update",
+			"delete":           "Delete",
+			"impersonate":      "Other",
+			"deletecollection": "Delete",
+		} {
+			record, err := transform.OCSF(&audit.Event{Verb: verb})
+			Expect(err).NotTo(HaveOccurred())
+			var event map[string]any
+			Expect(json.Unmarshal(record, &event)).To(Succeed())
+			Expect(event).To(HaveKeyWithValue("activity_name", activity), verb)
+		}
+	})
+
+	It("should reject unsupported schemas", func() {
+		_, err := transform.EventList(configv1alpha1.SchemaKubernetesAudit, eventList)
+		Expect(err).To(MatchError(ContainSubstring(`unsupported schema "KubernetesAudit"`)))
+	})
+})
@@ -4,11 +4,50 @@
 
 package v1alpha1
 
+import (
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
 // SetDefaults_AuditlogForwarder sets defaults for the configuration of the audit log forwarder.
 func SetDefaults_AuditlogForwarder(obj *AuditlogForwarder) {
 	SetDefaults_Log(&obj.Log)
 	SetDefaults_Server(&obj.Server)
 	SetDefaults_Outputs(obj.Outputs)
+
+	isQuorum := func(o Output) bool { return o.DeliveryMode == DeliveryModeQuorum }
+	if obj.Quorum == nil && slices.ContainsFunc(obj.Outputs, isQuorum) {
+		obj.Quorum = &Quorum{}
+	}
+	if obj.Quorum != nil {
+		SetDefaults_Quorum(obj.Quorum)
+	}
+
+	isBestEffort := func(o Output) bool { return o.DeliveryMode == DeliveryModeBestEffort }
+	if obj.BestEffortQueue == nil && slices.ContainsFunc(obj.Outputs, isBestEffort) {
+		obj.BestEffortQueue = &BestEffortQueue{}
+	}
+	if obj.BestEffortQueue != nil {
+		SetDefaults_BestEffortQueue(obj.BestEffortQueue)
+	}
+	if obj.Tracing != nil {
+		SetDefaults_Tracing(obj.Tracing)
+	}
+	if obj.Deduplication != nil {
+		SetDefaults_Deduplication(obj.Deduplication)
+	}
+	if obj.StageMerging != nil {
+		SetDefaults_StageMerging(obj.StageMerging)
+	}
+	if obj.Pseudonymization != nil {
+		SetDefaults_Pseudonymization(obj.Pseudonymization)
+	}
+	if obj.HashChain != nil {
+		SetDefaults_HashChain(obj.HashChain)
+	}
 }
 
 // SetDefaults_Log sets defaults for the logging configuration.
@@ -29,6 +68,27 @@ func SetDefaults_Server(obj *Server) {
 	if obj.MetricsPort == 0 {
 		obj.MetricsPort = 8080
 	}
+	if obj.MaxInFlightRequests == nil {
+		obj.MaxInFlightRequests = ptr.To[int32](100)
+	}
+	if obj.RetryAfter == nil {
+		obj.RetryAfter = &metav1.Duration{Duration: time.Second}
+	}
+	SetDefaults_Health(&obj.Health)
+}
+
+// SetDefaults_Revocation sets defaults for the revocation checking of certificates.
+func SetDefaults_Revocation(obj *Revocation) {
+	if obj.FailurePolicy == "" {
+		obj.FailurePolicy = RevocationFailurePolicyFailClosed
+	}
+}
+
+// SetDefaults_Health sets defaults for the configuration of the readiness endpoint.
+func SetDefaults_Health(obj *Health) {
+	if obj.CheckInterval == nil {
+		obj.CheckInterval = &metav1.Duration{Duration: 30 * time.Second}
+	}
 }
 
 // SetDefaults_Outputs sets defaults for the outputs configuration.
@@ -45,4 +105,109 @@ func SetDefaults_Outputs(outputs []Output) {
 			}
 		}
 	}
+
+	for i := range outputs {
+		if outputs[i].Schema == "" {
+			outputs[i].Schema = SchemaKubernetesAudit
+		}
+		if outputs[i].Format == "" {
+			outputs[i].Format = FormatNDJSON
+			if outputs[i].Schema == SchemaKubernetesAudit {
+				outputs[i].Format = FormatEventList
+			}
+		}
+		if outputs[i].HTTP != nil && outputs[i].HTTP.LoadBalancing != nil {
+			SetDefaults_LoadBalancing(outputs[i].HTTP.LoadBalancing)
+		}
+	}
+}
+
+// SetDefaults_LoadBalancing sets defaults for the load balancing configuration of an HTTP output.
+func SetDefaults_LoadBalancing(obj *LoadBalancing) {
+	if obj.Policy == "" {
+		obj.Policy = LoadBalancingPolicyRoundRobin
+	}
+	if obj.FailureThreshold == 0 {
+		obj.FailureThreshold = 3
+	}
+	if obj.EjectionCooldown == nil {
+		obj.EjectionCooldown = &metav1.Duration{Duration: 30 * time.Second}
+	}
+	if obj.DNS != nil {
+		SetDefaults_DNSDiscovery(obj.DNS)
+	}
+}
+
+// SetDefaults_DNSDiscovery sets defaults for the DNS discovery configuration.
+func SetDefaults_DNSDiscovery(obj *DNSDiscovery) {
+	if obj.RecordType == "" {
+		obj.RecordType = DNSRecordTypeA
+	}
+	if obj.RefreshInterval == nil {
+		obj.RefreshInterval = &metav1.Duration{Duration: 30 * time.Second}
+	}
+}
+
+// SetDefaults_Quorum sets defaults for the quorum configuration.
+func SetDefaults_Quorum(obj *Quorum) {
+	if obj.MinSuccessful == 0 {
+		obj.MinSuccessful = 1
+	}
+}
+
+// SetDefaults_BestEffortQueue sets defaults for the queue of deliveries to BestEffort outputs.
+func SetDefaults_BestEffortQueue(obj *BestEffortQueue) {
+	if obj.Size == 0 {
+		obj.Size = 1000
+	}
+	if obj.Workers == 0 {
+		obj.Workers = 10
+	}
+	if obj.OverflowPolicy == "" {
+		obj.OverflowPolicy = OverflowPolicyDropOldest
+	}
+}
+
+// SetDefaults_Tracing sets defaults for the export of traces.
+func SetDefaults_Tracing(obj *Tracing) {
+	if obj.Protocol == "" {
+		obj.Protocol = TracingProtocolGRPC
+	}
+	if obj.SamplingPercentage == nil {
+		obj.SamplingPercentage = ptr.To[int32](100)
+	}
+}
+
+// SetDefaults_Deduplication sets defaults for the deduplication of audit events.
+func SetDefaults_Deduplication(obj *Deduplication) {
+	if obj.CacheSize == 0 {
+		obj.CacheSize = 100000
+	}
+	if obj.TTL == nil {
+		obj.TTL = &metav1.Duration{Duration: 10 * time.Minute}
+	}
+}
+
+// SetDefaults_StageMerging sets defaults for the merging of the stages of a request.
+func SetDefaults_StageMerging(obj *StageMerging) {
+	if obj.Window == nil {
+		obj.Window = &metav1.Duration{Duration: time.Minute}
+	}
+	if obj.MaxHeldEvents == 0 {
+		obj.MaxHeldEvents = 10000
+	}
+}
+
+// SetDefaults_Pseudonymization sets defaults for the pseudonymization of user identities.
+func SetDefaults_Pseudonymization(obj *Pseudonymization) {
+	if obj.AllowedUsers == nil {
+		obj.AllowedUsers = []string{"system:*"}
+	}
+}
+
+// SetDefaults_HashChain sets defaults for the hash chain over the forwarded audit events.
+func SetDefaults_HashChain(obj *HashChain) {
+	if obj.CheckpointInterval == nil {
+		obj.CheckpointInterval = &metav1.Duration{Duration: time.Minute}
+	}
 }
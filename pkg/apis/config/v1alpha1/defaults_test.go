@@ -5,8 +5,13 @@
 package v1alpha1_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	. "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
 )
@@ -53,6 +58,214 @@ var _ = Describe("Defaults", func() {
 			Expect(obj.Server.Port).To(Equal(int32(8080)))
 			Expect(obj.Server.MetricsPort).To(Equal(int32(9090)))
 		})
+		It("should default the quorum when outputs with Quorum delivery mode are configured", func() {
+			obj.Outputs = []Output{
+				{DeliveryMode: DeliveryModeQuorum, HTTP: &OutputHTTP{URL: "http://example1.com"}},
+				{DeliveryMode: DeliveryModeQuorum, HTTP: &OutputHTTP{URL: "http://example2.com"}},
+			}
+
+			SetDefaults_AuditlogForwarder(obj)
+
+			Expect(obj.Quorum).To(Equal(&Quorum{MinSuccessful: 1}))
+		})
+
+		It("should not default the quorum when no outputs with Quorum delivery mode are configured", func() {
+			obj.Outputs = []Output{
+				{HTTP: &OutputHTTP{URL: "http://example1.com"}},
+			}
+
+			SetDefaults_AuditlogForwarder(obj)
+
+			Expect(obj.Quorum).To(BeNil())
+		})
+
+		It("should default the BestEffort queue when outputs with BestEffort delivery mode are configured", func() {
+			obj.Outputs = []Output{
+				{DeliveryMode: DeliveryModeGuaranteed, HTTP: &OutputHTTP{URL: "http://example1.com"}},
+				{DeliveryMode: DeliveryModeBestEffort, HTTP: &OutputHTTP{URL: "http://example2.com"}},
+			}
+
+			SetDefaults_AuditlogForwarder(obj)
+
+			Expect(obj.BestEffortQueue).To(Equal(&BestEffortQueue{Size: 1000, Workers: 10, OverflowPolicy: OverflowPolicyDropOldest}))
+		})
+
+		It("should not default the BestEffort queue when no outputs with BestEffort delivery mode are configured", func() {
+			obj.Outputs = []Output{
+				{HTTP: &OutputHTTP{URL: "http://example1.com"}},
+			}
+
+			SetDefaults_AuditlogForwarder(obj)
+
+			Expect(obj.BestEffortQueue).To(BeNil())
+		})
+	})
+
+	Describe("#SetDefaults_LoadBalancing", func() {
+		It("should default the load balancing configuration", func() {
+			lb := &LoadBalancing{DNS: &DNSDiscovery{}}
+
+			SetDefaults_LoadBalancing(lb)
+
+			Expect(lb).To(Equal(&LoadBalancing{
+				Policy:           LoadBalancingPolicyRoundRobin,
+				FailureThreshold: 3,
+				EjectionCooldown: &metav1.Duration{Duration: 30 * time.Second},
+				DNS: &DNSDiscovery{
+					RecordType:      DNSRecordTypeA,
+					RefreshInterval: &metav1.Duration{Duration: 30 * time.Second},
+				},
+			}))
+		})
+
+		It("should not override existing values", func() {
+			lb := &LoadBalancing{
+				Policy:           LoadBalancingPolicyLeastOutstandingRequests,
+				FailureThreshold: 1,
+				EjectionCooldown: &metav1.Duration{Duration: time.Minute},
+				DNS: &DNSDiscovery{
+					RecordType:      DNSRecordTypeSRV,
+					RefreshInterval: &metav1.Duration{Duration: time.Minute},
+				},
+			}
+			expected := lb.DeepCopy()
+
+			SetDefaults_LoadBalancing(lb)
+
+			Expect(lb).To(Equal(expected))
+		})
+
+		It("should be applied to HTTP outputs", func() {
+			outputs := []Output{{HTTP: &OutputHTTP{URL: "http://example.com", LoadBalancing: &LoadBalancing{}}}}
+
+			SetDefaults_Outputs(outputs)
+
+			Expect(outputs[0].HTTP.LoadBalancing.Policy).To(Equal(LoadBalancingPolicyRoundRobin))
+		})
+	})
+
+	Describe("#SetDefaults_Quorum", func() {
+		It("should default the minimum number of successful outputs to 1", func() {
+			quorum := &Quorum{}
+
+			SetDefaults_Quorum(quorum)
+
+			Expect(quorum.MinSuccessful).To(Equal(int32(1)))
+		})
+
+		It("should not override existing values", func() {
+			quorum := &Quorum{MinSuccessful: 2}
+
+			SetDefaults_Quorum(quorum)
+
+			Expect(quorum.MinSuccessful).To(Equal(int32(2)))
+		})
+	})
+
+	Describe("#SetDefaults_BestEffortQueue", func() {
+		It("should not override existing values", func() {
+			queue := &BestEffortQueue{Size: 10, Workers: 1, OverflowPolicy: OverflowPolicyDropNewest}
+
+			SetDefaults_BestEffortQueue(queue)
+
+			Expect(queue).To(Equal(&BestEffortQueue{Size: 10, Workers: 1, OverflowPolicy: OverflowPolicyDropNewest}))
+		})
+	})
+
+	Describe("#SetDefaults_Tracing", func() {
+		It("should default the protocol and sampling percentage", func() {
+			tracing := &Tracing{Endpoint: "otel-collector:4317"}
+
+			SetDefaults_Tracing(tracing)
+
+			Expect(tracing.Protocol).To(Equal(TracingProtocolGRPC))
+			Expect(tracing.SamplingPercentage).To(PointTo(Equal(int32(100))))
+		})
+
+		It("should not override existing values", func() {
+			tracing := &Tracing{Protocol: TracingProtocolHTTP, SamplingPercentage: ptr.To[int32](0)}
+
+			SetDefaults_Tracing(tracing)
+
+			Expect(tracing.Protocol).To(Equal(TracingProtocolHTTP))
+			Expect(tracing.SamplingPercentage).To(PointTo(Equal(int32(0))))
+		})
+	})
+
+	Describe("#SetDefaults_Deduplication", func() {
+		It("should default the cache size and TTL", func() {
+			deduplication := &Deduplication{}
+
+			SetDefaults_Deduplication(deduplication)
+
+			Expect(deduplication.CacheSize).To(Equal(int32(100000)))
+			Expect(deduplication.TTL).To(Equal(&metav1.Duration{Duration: 10 * time.Minute}))
+		})
+
+		It("should not override existing values", func() {
+			deduplication := &Deduplication{CacheSize: 10, TTL: &metav1.Duration{Duration: time.Hour}}
+
+			SetDefaults_Deduplication(deduplication)
+
+			Expect(deduplication.CacheSize).To(Equal(int32(10)))
+			Expect(deduplication.TTL).To(Equal(&metav1.Duration{Duration: time.Hour}))
+		})
+	})
+
+	Describe("#SetDefaults_StageMerging", func() {
+		It("should default the window and the maximum number of held events", func() {
+			stageMerging := &StageMerging{}
+
+			SetDefaults_StageMerging(stageMerging)
+
+			Expect(stageMerging.Window).To(Equal(&metav1.Duration{Duration: time.Minute}))
+			Expect(stageMerging.MaxHeldEvents).To(Equal(int32(10000)))
+		})
+
+		It("should not override existing values", func() {
+			stageMerging := &StageMerging{Window: &metav1.Duration{Duration: 10 * time.Second}, MaxHeldEvents: 10}
+
+			SetDefaults_StageMerging(stageMerging)
+
+			Expect(stageMerging.Window).To(Equal(&metav1.Duration{Duration: 10 * time.Second}))
+			Expect(stageMerging.MaxHeldEvents).To(Equal(int32(10)))
+		})
+	})
+
+	Describe("#SetDefaults_Pseudonymization", func() {
+		It("should default the allowed users to the system users", func() {
+			pseudonymization := &Pseudonymization{KeyFile: "/etc/pseudonymization/key"}
+
+			SetDefaults_Pseudonymization(pseudonymization)
+
+			Expect(pseudonymization.AllowedUsers).To(Equal([]string{"system:*"}))
+		})
+
+		It("should not override existing values", func() {
+			pseudonymization := &Pseudonymization{AllowedUsers: []string{}}
+
+			SetDefaults_Pseudonymization(pseudonymization)
+
+			Expect(pseudonymization.AllowedUsers).To(BeEmpty())
+		})
+	})
+
+	Describe("#SetDefaults_HashChain", func() {
+		It("should default the checkpoint interval", func() {
+			hashChain := &HashChain{SigningKeyFile: "/etc/hashchain/key.pem"}
+
+			SetDefaults_HashChain(hashChain)
+
+			Expect(hashChain.CheckpointInterval).To(Equal(&metav1.Duration{Duration: time.Minute}))
+		})
+
+		It("should not override existing values", func() {
+			hashChain := &HashChain{CheckpointInterval: &metav1.Duration{Duration: time.Hour}}
+
+			SetDefaults_HashChain(hashChain)
+
+			Expect(hashChain.CheckpointInterval).To(Equal(&metav1.Duration{Duration: time.Hour}))
+		})
 	})
 
 	Describe("#SetDefaults_Log", func() {
@@ -136,6 +349,62 @@ var _ = Describe("Defaults", func() {
 			Expect(serverConfig.TLS.CertFile).To(BeEmpty())
 			Expect(serverConfig.TLS.KeyFile).To(BeEmpty())
 		})
+
+		It("should default the concurrency limits", func() {
+			SetDefaults_Server(serverConfig)
+
+			Expect(serverConfig.MaxInFlightRequests).To(PointTo(Equal(int32(100))))
+			Expect(serverConfig.RetryAfter).To(Equal(&metav1.Duration{Duration: time.Second}))
+		})
+
+		It("should keep a disabled in-flight request limit", func() {
+			serverConfig.MaxInFlightRequests = ptr.To[int32](0)
+
+			SetDefaults_Server(serverConfig)
+
+			Expect(serverConfig.MaxInFlightRequests).To(PointTo(Equal(int32(0))))
+		})
+
+		It("should not limit the request bodies", func() {
+			SetDefaults_Server(serverConfig)
+
+			Expect(serverConfig.RequestLimits).To(BeZero())
+		})
+
+		It("should default the health configuration", func() {
+			SetDefaults_Server(serverConfig)
+
+			Expect(serverConfig.Health.CheckGuaranteedOutputs).To(BeFalse())
+			Expect(serverConfig.Health.CheckInterval).To(Equal(&metav1.Duration{Duration: 30 * time.Second}))
+		})
+	})
+
+	Describe("#SetDefaults_Revocation", func() {
+		It("should default the failure policy to FailClosed", func() {
+			revocation := &Revocation{}
+
+			SetDefaults_Revocation(revocation)
+
+			Expect(revocation.FailurePolicy).To(Equal(RevocationFailurePolicyFailClosed))
+		})
+
+		It("should not override an existing failure policy", func() {
+			revocation := &Revocation{FailurePolicy: RevocationFailurePolicyFailOpen}
+
+			SetDefaults_Revocation(revocation)
+
+			Expect(revocation.FailurePolicy).To(Equal(RevocationFailurePolicyFailOpen))
+		})
+	})
+
+	Describe("#SetDefaults_Health", func() {
+		It("should not override an existing check interval", func() {
+			health := &Health{CheckInterval: &metav1.Duration{Duration: time.Minute}}
+
+			SetDefaults_Health(health)
+
+			Expect(health.CheckInterval).To(Equal(&metav1.Duration{Duration: time.Minute}))
+		})
 	})
 
 	Describe("#SetDefaults_Outputs", func() {
@@ -218,5 +487,31 @@ var _ = Describe("Defaults", func() {
 			Expect(outputs[1].DeliveryMode).To(Equal(DeliveryModeBestEffort))
 			Expect(outputs[2].DeliveryMode).To(Equal(DeliveryModeGuaranteed))
 		})
+
+		It("should default the schema to KubernetesAudit", func() {
+			outputs := []Output{
+				{HTTP: &OutputHTTP{URL: "http://example1.com"}},
+				{HTTP: &OutputHTTP{URL: "http://example2.com"}, Schema: SchemaECS},
+			}
+
+			SetDefaults_Outputs(outputs)
+
+			Expect(outputs[0].Schema).To(Equal(SchemaKubernetesAudit))
+			Expect(outputs[1].Schema).To(Equal(SchemaECS))
+		})
+
+		It("should default the format depending on the schema", func() {
+			outputs := []Output{
+				{HTTP: &OutputHTTP{URL: "http://example1.com"}},
+				{HTTP: &OutputHTTP{URL: "http://example2.com"}, Schema: SchemaCEF},
+				{HTTP: &OutputHTTP{URL: "http://example3.com"}, Schema: SchemaOCSF, Format: FormatSingleEvent},
+			}
+
+			SetDefaults_Outputs(outputs)
+
+			Expect(outputs[0].Format).To(Equal(FormatEventList))
+			Expect(outputs[1].Format).To(Equal(FormatNDJSON))
+			Expect(outputs[2].Format).To(Equal(FormatSingleEvent))
+		})
 	})
 })
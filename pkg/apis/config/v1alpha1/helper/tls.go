@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package helper
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"slices"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+)
+
+var (
+	tlsVersions = map[string]uint16{
+		configv1alpha1.TLSVersion12: tls.VersionTLS12,
+		configv1alpha1.TLSVersion13: tls.VersionTLS13,
+	}
+
+	curves = []tls.CurveID{
+		tls.X25519MLKEM768,
+		tls.X25519,
+		tls.CurveP256,
+		tls.CurveP384,
+		tls.CurveP521,
+	}
+)
+
+// SupportedTLSVersions returns the names of the supported TLS versions.
+func SupportedTLSVersions() []string {
+	return []string{configv1alpha1.TLSVersion12, configv1alpha1.TLSVersion13}
+}
+
+// SupportedCurves returns the names of the supported elliptic curves.
+func SupportedCurves() []string {
+	names := make([]string, 0, len(curves))
+	for _, curve := range curves {
+		names = append(names, curve.String())
+	}
+	return names
+}
+
+// TLSVersion returns the TLS version with the given name.
+func TLSVersion(name string) (uint16, error) {
+	version, ok := tlsVersions[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q", name)
+	}
+	return version, nil
+}
+
+// CipherSuite returns the cipher suite with the given name, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256.
+// The returned bool reports whether the cipher suite is insecure.
+func CipherSuite(name string) (*tls.CipherSuite, bool, error) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite, false, nil
+		}
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.Name == name {
+			return suite, true, nil
+		}
+	}
+	return nil, false, fmt.Errorf("unknown cipher suite %q", name)
+}
+
+// Curve returns the elliptic curve with the given name, e.g. X25519 or CurveP256.
+func Curve(name string) (tls.CurveID, error) {
+	for _, curve := range curves {
+		if curve.String() == name {
+			return curve, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported curve %q", name)
+}
+
+// ApplyTLSParameters applies the TLS parameters to the given TLS configuration.
+// The minimum version is TLS 1.2 unless configured otherwise.
+// The parameters are expected to be validated, insecure cipher suites are rejected nevertheless.
+func ApplyTLSParameters(config *tls.Config, params configv1alpha1.TLSParameters) error {
+	config.MinVersion = tls.VersionTLS12
+	if params.MinVersion != "" {
+		version, err := TLSVersion(params.MinVersion)
+		if err != nil {
+			return err
+		}
+		config.MinVersion = version
+	}
+
+	if params.MaxVersion != "" {
+		version, err := TLSVersion(params.MaxVersion)
+		if err != nil {
+			return err
+		}
+		config.MaxVersion = version
+	}
+
+	for _, name := range params.CipherSuites {
+		suite, insecure, err := CipherSuite(name)
+		if err != nil {
+			return err
+		}
+		if insecure {
+			return fmt.Errorf("insecure cipher suite %q", name)
+		}
+		config.CipherSuites = append(config.CipherSuites, suite.ID)
+	}
+
+	for _, name := range params.CurvePreferences {
+		curve, err := Curve(name)
+		if err != nil {
+			return err
+		}
+		config.CurvePreferences = append(config.CurvePreferences, curve)
+	}
+
+	config.NextProtos = slices.Clone(params.ALPNProtocols)
+	return nil
+}
+
+// HTTPProtocols returns the HTTP protocols which an HTTP server offers via ALPN for the given ALPN protocols.
+// The server offers HTTP/2 and HTTP/1.1 in addition to the configured protocols unless they are disabled explicitly,
+// hence only the listed ones of "h2" and "http/1.1" are enabled. If neither of them is listed, nil is returned,
+// so that the default protocols of the server are enabled.
+func HTTPProtocols(alpnProtocols []string) *http.Protocols {
+	http1, http2 := slices.Contains(alpnProtocols, "http/1.1"), slices.Contains(alpnProtocols, "h2")
+	if !http1 && !http2 {
+		return nil
+	}
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(http1)
+	protocols.SetHTTP2(http2)
+	return protocols
+}
@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package helper_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+	. "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1/helper"
+)
+
+var _ = Describe("TLS", func() {
+	Describe("#ApplyTLSParameters", func() {
+		var config *tls.Config
+
+		BeforeEach(func() {
+			config = &tls.Config{}
+		})
+
+		It("should default the minimum version to TLS 1.2", func() {
+			Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{})).To(Succeed())
+
+			Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+			Expect(config.MaxVersion).To(BeZero())
+			Expect(config.CipherSuites).To(BeEmpty())
+			Expect(config.CurvePreferences).To(BeEmpty())
+			Expect(config.NextProtos).To(BeEmpty())
+		})
+
+		It("should apply all parameters", func() {
+			Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{
+				MinVersion:       configv1alpha1.TLSVersion12,
+				MaxVersion:       configv1alpha1.TLSVersion13,
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				CurvePreferences: []string{"X25519MLKEM768", "X25519", "CurveP256"},
+				ALPNProtocols:    []string{"h2", "http/1.1"},
+			})).To(Succeed())
+
+			Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+			Expect(config.MaxVersion).To(Equal(uint16(tls.VersionTLS13)))
+			Expect(config.CipherSuites).To(Equal([]uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}))
+			Expect(config.CurvePreferences).To(Equal([]tls.CurveID{tls.X25519MLKEM768, tls.X25519, tls.CurveP256}))
+			Expect(config.NextProtos).To(Equal([]string{"h2", "http/1.1"}))
+		})
+
+		It("should restrict the connections to TLS 1.3", func() {
+			Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{MinVersion: configv1alpha1.TLSVersion13})).To(Succeed())
+
+			Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS13)))
+		})
+
+		It("should reject unsupported versions", func() {
+			Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{MinVersion: "VersionTLS11"})).To(MatchError(`unsupported TLS version "VersionTLS11"`))
+		})
+
+		It("should reject insecure cipher suites", func() {
+			Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}})).To(MatchError(`insecure cipher suite "TLS_RSA_WITH_RC4_128_SHA"`))
+		})
+
+		It("should reject unknown cipher suites and curves", func() {
+			Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{CipherSuites: []string{"foo"}})).To(MatchError(`unknown cipher suite "foo"`))
+			Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{CurvePreferences: []string{"P256"}})).To(MatchError(`unsupported curve "P256"`))
+		})
+	})
+
+	Describe("#HTTPProtocols", func() {
+		It("should keep the default protocols if no HTTP protocol is listed", func() {
+			Expect(HTTPProtocols(nil)).To(BeNil())
+			Expect(HTTPProtocols([]string{"acme-tls/1"})).To(BeNil())
+		})
+
+		DescribeTable("should negotiate the listed HTTP protocols",
+			func(alpnProtocols []string, expected string) {
+				config := &tls.Config{Certificates: []tls.Certificate{selfSignedCertificate()}}
+				Expect(ApplyTLSParameters(config, configv1alpha1.TLSParameters{ALPNProtocols: alpnProtocols})).To(Succeed())
+				srv := &http.Server{TLSConfig: config, Protocols: HTTPProtocols(alpnProtocols), ReadHeaderTimeout: time.Second}
+
+				listener, err := net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				go func() { _ = srv.ServeTLS(listener, "", "") }()
+				DeferCleanup(srv.Close)
+
+				conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+					InsecureSkipVerify: true, //#nosec G402 -- The test only checks the negotiated protocol.
+					NextProtos:         []string{"h2", "http/1.1"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				defer conn.Close()
+				Expect(conn.ConnectionState().NegotiatedProtocol).To(Equal(expected))
+			},
+			Entry("defaults", nil, "h2"),
+			Entry("HTTP/1.1 only", []string{"http/1.1"}, "http/1.1"),
+			Entry("HTTP/2 only", []string{"h2"}, "h2"),
+			Entry("HTTP/1.1 preferred over HTTP/2", []string{"http/1.1", "h2"}, "http/1.1"),
+		)
+	})
+})
+
+// selfSignedCertificate returns a self-signed certificate for 127.0.0.1.
+func selfSignedCertificate() tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -32,6 +33,98 @@ const (
 	// DeliveryModeBestEffort indicates that delivery is attempted but failures don't affect request success.
 	// Messages may be delivered multiple times or not at all.
 	DeliveryModeBestEffort DeliveryMode = "BestEffort"
+	// DeliveryModeQuorum indicates that the output is part of the quorum group.
+	// The request is successful as soon as the configured number of quorum outputs succeeded,
+	// delivery to the remaining outputs of the group continues in the background.
+	// There is only one quorum group, which replaces the "Guaranteed" output.
+	DeliveryModeQuorum DeliveryMode = "Quorum"
+)
+
+// Schema defines the schema into which audit events are transformed before they are sent to an output.
+type Schema string
+
+const (
+	// SchemaKubernetesAudit forwards the audit events unchanged as "audit.k8s.io/v1" EventList.
+	SchemaKubernetesAudit Schema = "KubernetesAudit"
+	// SchemaECS transforms each audit event into an Elastic Common Schema document.
+	SchemaECS Schema = "ECS"
+	// SchemaOCSF transforms each audit event into an Open Cybersecurity Schema Framework API Activity event.
+	SchemaOCSF Schema = "OCSF"
+	// SchemaCEF transforms each audit event into an ArcSight Common Event Format line.
+	SchemaCEF Schema = "CEF"
+)
+
+// Format defines how the audit events of a request are framed for an output.
+type Format string
+
+const (
+	// FormatEventList sends all events of a request as one "audit.k8s.io/v1" EventList.
+	// It is only supported with the "KubernetesAudit" schema.
+	FormatEventList Format = "EventList"
+	// FormatNDJSON sends all events of a request in one body with one event per line.
+	FormatNDJSON Format = "NDJSON"
+	// FormatJSONArray sends all events of a request as one JSON array.
+	// It is not supported with the "CEF" schema.
+	FormatJSONArray Format = "JSONArray"
+	// FormatSingleEvent sends one request per event.
+	FormatSingleEvent Format = "SingleEvent"
+)
+
+// LoadBalancingPolicy defines how an endpoint is selected for a request.
+type LoadBalancingPolicy string
+
+const (
+	// LoadBalancingPolicyRoundRobin selects the endpoints one after another.
+	LoadBalancingPolicyRoundRobin LoadBalancingPolicy = "RoundRobin"
+	// LoadBalancingPolicyLeastOutstandingRequests selects the endpoint with the fewest in-flight requests.
+	LoadBalancingPolicyLeastOutstandingRequests LoadBalancingPolicy = "LeastOutstandingRequests"
+)
+
+// DNSRecordType defines which DNS records are used to discover endpoints.
+type DNSRecordType string
+
+const (
+	// DNSRecordTypeA discovers endpoints by resolving the A and AAAA records of the host.
+	DNSRecordTypeA DNSRecordType = "A"
+	// DNSRecordTypeSRV discovers endpoints by resolving the SRV records of the host.
+	DNSRecordTypeSRV DNSRecordType = "SRV"
+)
+
+// TracingProtocol defines the protocol used to export traces.
+type TracingProtocol string
+
+const (
+	// TracingProtocolGRPC exports traces via OTLP over gRPC.
+	TracingProtocolGRPC TracingProtocol = "grpc"
+	// TracingProtocolHTTP exports traces via OTLP over HTTP with protobuf encoding.
+	TracingProtocolHTTP TracingProtocol = "http/protobuf"
+)
+
+const (
+	// TLSVersion12 is TLS version 1.2.
+	TLSVersion12 = "VersionTLS12"
+	// TLSVersion13 is TLS version 1.3.
+	TLSVersion13 = "VersionTLS13"
+)
+
+// RevocationFailurePolicy defines how certificates are handled whose revocation status cannot be determined.
+type RevocationFailurePolicy string
+
+const (
+	// RevocationFailurePolicyFailClosed rejects certificates whose revocation status cannot be determined.
+	RevocationFailurePolicyFailClosed RevocationFailurePolicy = "FailClosed"
+	// RevocationFailurePolicyFailOpen accepts certificates whose revocation status cannot be determined.
+	RevocationFailurePolicyFailOpen RevocationFailurePolicy = "FailOpen"
+)
+
+// OverflowPolicy defines which request is dropped when a queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowPolicyDropOldest drops the request which has been queued the longest to make room for the new one.
+	OverflowPolicyDropOldest OverflowPolicy = "DropOldest"
+	// OverflowPolicyDropNewest drops the new request.
+	OverflowPolicyDropNewest OverflowPolicy = "DropNewest"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -46,9 +139,63 @@ type AuditlogForwarder struct {
 	Server Server `json:"server"`
 	// Outputs contains the list of outputs to forward audit logs to.
 	Outputs []Output `json:"outputs"`
+	// Quorum contains the configuration for outputs with "Quorum" delivery mode.
+	// All "Quorum" outputs form a single group; multiple groups are not supported.
+	// +optional
+	Quorum *Quorum `json:"quorum,omitempty"`
+	// BestEffortQueue contains the configuration of the queue for deliveries to outputs with "BestEffort" delivery mode.
+	// +optional
+	BestEffortQueue *BestEffortQueue `json:"bestEffortQueue,omitempty"`
+	// Tracing contains the configuration for exporting OpenTelemetry traces.
+	// Tracing is disabled if not set.
+	// +optional
+	Tracing *Tracing `json:"tracing,omitempty"`
+	// Deduplication contains the configuration of the dropping of audit events which were already forwarded,
+	// e.g. because the kube-apiserver retried a request.
+	// Deduplication is disabled if not set.
+	// +optional
+	Deduplication *Deduplication `json:"deduplication,omitempty"`
+	// Sampling contains the rules by which audit events are sampled before they are forwarded.
+	// All events are forwarded if not set.
+	// +optional
+	Sampling *Sampling `json:"sampling,omitempty"`
+	// StageMerging contains the configuration of the merging of the stages of a request, so that only one
+	// audit event is forwarded per request.
+	// Stage merging is disabled if not set.
+	// +optional
+	StageMerging *StageMerging `json:"stageMerging,omitempty"`
+	// Filter contains the expression deciding which audit events are forwarded.
+	// All events are forwarded if not set.
+	// +optional
+	Filter *Filter `json:"filter,omitempty"`
 	// InjectAnnotations contains annotations to be injected into audit events.
+	// Annotations with the same keys which are already set, e.g. by the kube-apiserver, are kept unless
+	// overwriteInjectedAnnotations is set.
 	// +optional
 	InjectAnnotations map[string]string `json:"injectAnnotations,omitempty"`
+	// OverwriteInjectedAnnotations specifies whether annotations with the same keys as the injected annotations which
+	// are already set are overwritten.
+	// Defaults to false.
+	// +optional
+	OverwriteInjectedAnnotations bool `json:"overwriteInjectedAnnotations,omitempty"`
+	// ComputedAnnotations contains annotations whose values are sourced dynamically or computed per audit event
+	// and injected into it.
+	// The annotation is not injected if its value is empty or cannot be computed.
+	// +optional
+	ComputedAnnotations []ComputedAnnotation `json:"computedAnnotations,omitempty"`
+	// Enrichment contains the configuration of the enrichment of audit events with metadata of Kubernetes objects,
+	// e.g. the owner label of the namespace of an event.
+	// Enrichment is disabled if not set.
+	// +optional
+	Enrichment *Enrichment `json:"enrichment,omitempty"`
+	// Pseudonymization contains the configuration of the pseudonymization of user identities and source IPs
+	// for outputs with pseudonymize enabled.
+	// +optional
+	Pseudonymization *Pseudonymization `json:"pseudonymization,omitempty"`
+	// HashChain contains the configuration of the tamper-evident hash chain over the forwarded audit events.
+	// The hash chain is disabled if not set.
+	// +optional
+	HashChain *HashChain `json:"hashChain,omitempty"`
 }
 
 // Log defines the logging configuration for the audit log forwarder.
@@ -77,6 +224,55 @@ type Server struct {
 	Address string `json:"address,omitempty"`
 	// TLS contains the TLS configuration for the server.
 	TLS TLS `json:"tls"`
+	// MaxInFlightRequests is the maximum number of audit requests which are processed concurrently.
+	// Further requests are rejected with status code 429 and a Retry-After header.
+	// Defaults to 100, 0 disables the limit.
+	// +optional
+	MaxInFlightRequests *int32 `json:"maxInFlightRequests,omitempty"`
+	// RetryAfter is the duration after which clients are asked to retry requests rejected because of
+	// MaxInFlightRequests. It is rounded up to full seconds.
+	// Defaults to 1s.
+	// +optional
+	RetryAfter *metav1.Duration `json:"retryAfter,omitempty"`
+	// RequestLimits contains the limits for the bodies of incoming audit requests.
+	// +optional
+	RequestLimits RequestLimits `json:"requestLimits,omitempty"`
+	// Health contains the configuration of the readiness endpoint served on the metrics port.
+	// +optional
+	Health Health `json:"health,omitempty"`
+}
+
+// Health defines the configuration of the readiness endpoint.
+// The forwarder is ready once its configuration is loaded and its TLS certificates are valid.
+type Health struct {
+	// CheckGuaranteedOutputs enables active health checks of the outputs with "Guaranteed" or "Quorum" delivery mode.
+	// If enabled, the forwarder is only ready if all Guaranteed outputs and at least the minimum number of successful
+	// Quorum outputs were reachable in the last check.
+	// +optional
+	CheckGuaranteedOutputs bool `json:"checkGuaranteedOutputs,omitempty"`
+	// CheckInterval is the interval of the active health checks. It also bounds the duration of a single check.
+	// Defaults to 30s.
+	// +optional
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+}
+
+// RequestLimits defines the limits for the bodies of incoming audit requests.
+// Requests exceeding a limit are rejected with status code 413.
+type RequestLimits struct {
+	// MaxBodySize is the maximum size of a request body as received, i.e. before decompression.
+	// The size is not limited if not set. A limit has to be above the largest batch sent by the kube-apiserver,
+	// as rejected batches are not retried.
+	// +optional
+	MaxBodySize *resource.Quantity `json:"maxBodySize,omitempty"`
+	// MaxDecompressedBodySize is the maximum size of a gzip or zstd compressed request body after decompression.
+	// The size is not limited if not set.
+	// +optional
+	MaxDecompressedBodySize *resource.Quantity `json:"maxDecompressedBodySize,omitempty"`
+	// MaxDecompressionRatio is the maximum ratio between the decompressed and the compressed size of a request body.
+	// It protects against decompression bombs which stay below MaxDecompressedBodySize.
+	// The ratio is not limited if not set.
+	// +optional
+	MaxDecompressionRatio int32 `json:"maxDecompressionRatio,omitempty"`
 }
 
 // TLS defines the TLS configuration for the server.
@@ -89,6 +285,56 @@ type TLS struct {
 	// If specified, client certificate verification will be enabled with RequireAndVerifyClientCert policy.
 	// +optional
 	ClientCAFile string `json:"clientCAFile,omitempty"`
+	// ClientRevocation configures the revocation checking of client certificates via CRLs.
+	// It requires ClientCAFile.
+	// +optional
+	ClientRevocation *Revocation `json:"clientRevocation,omitempty"`
+	// TLSParameters contains the parameters of the TLS connections of the server.
+	TLSParameters `json:",inline"`
+}
+
+// Revocation defines the revocation checking of certificates.
+// Connections with revoked certificates are rejected.
+type Revocation struct {
+	// CRLFiles is the list of files containing PEM or DER encoded certificate revocation lists.
+	// The files are watched and reloaded when they change.
+	// +optional
+	CRLFiles []string `json:"crlFiles,omitempty"`
+	// OCSP enables querying the OCSP responders of certificates whose revocation status is not determined by a CRL.
+	// Only supported for the server certificates of outputs.
+	// +optional
+	OCSP bool `json:"ocsp,omitempty"`
+	// FailurePolicy defines how certificates are handled whose revocation status cannot be determined,
+	// e.g. because no valid CRL of their issuer is loaded or the OCSP responder is unreachable.
+	// Must be one of [FailClosed,FailOpen]. Defaults to FailClosed.
+	// +optional
+	FailurePolicy RevocationFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// TLSParameters defines the parameters of TLS connections.
+type TLSParameters struct {
+	// MinVersion is the minimum TLS version. Must be one of [VersionTLS12,VersionTLS13].
+	// If not set, VersionTLS12 is used.
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+	// MaxVersion is the maximum TLS version. Must be one of [VersionTLS12,VersionTLS13].
+	// If not set, the highest version supported is used.
+	// +optional
+	MaxVersion string `json:"maxVersion,omitempty"`
+	// CipherSuites is the list of enabled cipher suites for TLS 1.2, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256.
+	// Insecure cipher suites are rejected. The cipher suites of TLS 1.3 are not configurable.
+	// If not set, the default cipher suites of the Go standard library are used.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// CurvePreferences is the list of elliptic curves used for key exchanges, e.g. X25519MLKEM768, X25519 or CurveP256.
+	// If not set, the default curves of the Go standard library are used.
+	// +optional
+	CurvePreferences []string `json:"curvePreferences,omitempty"`
+	// ALPNProtocols is the list of supported application level protocols in order of preference, e.g. h2 or http/1.1.
+	// A server only offers the listed ones of h2 and http/1.1. If neither of them is listed, it offers both after
+	// the listed protocols.
+	// +optional
+	ALPNProtocols []string `json:"alpnProtocols,omitempty"`
 }
 
 // Output defines an output to forward audit logs to.
@@ -96,26 +342,414 @@ type Output struct {
 	// DeliveryMode specifies how messages are delivered to this output.
 	// "Guaranteed" means the request is considered successful only if this output succeeds.
 	// "BestEffort" means delivery is attempted but failures don't affect request success.
+	// "Quorum" means the request is considered successful once the required number of "Quorum" outputs succeeded.
 	// When only one output is configured, it is implicitly "Guaranteed".
-	// When multiple outputs are configured, exactly one must be "Guaranteed".
+	// When multiple outputs are configured, either exactly one must be "Guaranteed" or at least one must be "Quorum".
+	// "Guaranteed" and "Quorum" cannot be combined, i.e. no output is mandatory next to the quorum group.
+	// All "Quorum" outputs form a single group.
 	// +optional
 	DeliveryMode DeliveryMode `json:"deliveryMode,omitempty"`
+	// Schema specifies the schema into which the audit events are transformed for this output.
+	// "KubernetesAudit" keeps the "audit.k8s.io/v1" events.
+	// "ECS", "OCSF" and "CEF" transform each audit event into an Elastic Common Schema document,
+	// OCSF API Activity event or ArcSight CEF line.
+	// Defaults to "KubernetesAudit".
+	// +optional
+	Schema Schema `json:"schema,omitempty"`
+	// Format specifies how the events of a request are sent to this output.
+	// "EventList" sends one "audit.k8s.io/v1" EventList and requires the "KubernetesAudit" schema.
+	// "NDJSON" sends one event per line, "JSONArray" sends a JSON array of events
+	// and "SingleEvent" sends one request per event.
+	// Defaults to "EventList" for the "KubernetesAudit" schema and to "NDJSON" otherwise.
+	// +optional
+	Format Format `json:"format,omitempty"`
+	// Filter contains the expression deciding which audit events are sent to this output.
+	// All events are sent if not set. It cannot be combined with the hash chain.
+	// +optional
+	Filter *Filter `json:"filter,omitempty"`
+	// Pseudonymize replaces the user identities and source IPs of the events sent to this output with pseudonyms.
+	// The request and response objects and the annotations which may name the users are removed.
+	// Requires pseudonymization to be configured and cannot be combined with the hash chain.
+	// +optional
+	Pseudonymize bool `json:"pseudonymize,omitempty"`
+	// Encryption contains the envelope encryption of the request bodies of this output.
+	// The bodies are encrypted after compression, so that only the recipients can read the stored audit events.
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
 	// HTTP contains the HTTP output configuration.
 	// +optional
 	HTTP *OutputHTTP `json:"http,omitempty"`
 }
 
+// Encryption defines the envelope encryption of request bodies as JSON Web Encryption (JWE) in JSON serialization.
+// Each body is encrypted with a random AES-256-GCM key, which is encrypted for each recipient.
+type Encryption struct {
+	// RecipientPublicKeyFiles is the list of files containing the PEM encoded PKIX public keys of the recipients.
+	// RSA keys of at least 2048 bits are used with RSA-OAEP-256, ECDSA keys on the curves P-256, P-384 and P-521
+	// with ECDH-ES+A256KW. Each recipient can decrypt the bodies with its private key.
+	RecipientPublicKeyFiles []string `json:"recipientPublicKeyFiles"`
+}
+
+// Quorum defines the configuration for the group of outputs with "Quorum" delivery mode.
+// Only one group is supported, it contains all outputs with "Quorum" delivery mode.
+type Quorum struct {
+	// MinSuccessful is the number of "Quorum" outputs that must succeed for a request to be successful.
+	// Must not exceed the number of outputs with "Quorum" delivery mode.
+	// Defaults to 1.
+	// +optional
+	MinSuccessful int32 `json:"minSuccessful,omitempty"`
+}
+
+// Deduplication defines the dropping of audit events which were already forwarded.
+// Events are identified by their audit ID and stage and remembered once they were forwarded to the required outputs.
+// Each replica remembers the events it forwarded itself.
+type Deduplication struct {
+	// CacheSize is the maximum number of forwarded events which are remembered.
+	// The least recently forwarded events are forgotten first.
+	// Defaults to 100000.
+	// +optional
+	CacheSize int32 `json:"cacheSize,omitempty"`
+	// TTL is the duration for which forwarded events are remembered.
+	// Defaults to 10m.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// Sampling defines the sampling of audit events by rules.
+// The first rule matching an event decides whether it is forwarded; events matching no rule are always forwarded.
+// The decision is made by the hash of the audit ID, so that all stages of a request and replays of it are
+// sampled alike. Events of failed requests, i.e. with a response code of 400 or above, are always forwarded.
+type Sampling struct {
+	// Rules is the list of sampling rules, which are evaluated in order.
+	Rules []SamplingRule `json:"rules"`
+}
+
+// SamplingRule defines which audit events are matched and which percentage of them is forwarded.
+// An event is matched if it matches all of the specified criteria.
+type SamplingRule struct {
+	// Name is the name of the rule, which is used as label of the metrics and in the annotations of forwarded events.
+	Name string `json:"name"`
+	// Verbs is the list of verbs matched by the rule, e.g. "list" or "watch".
+	// If empty, all verbs are matched.
+	// +optional
+	Verbs []string `json:"verbs,omitempty"`
+	// Users is the list of patterns of usernames matched by the rule, e.g. "system:serviceaccount:*".
+	// A "*" matches any sequence of characters except "/".
+	// If empty, all users are matched.
+	// +optional
+	Users []string `json:"users,omitempty"`
+	// Resources is the list of resources matched by the rule, e.g. "pods" or "pods/log".
+	// If empty, all events including the ones of non-resource requests are matched.
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+	// Percentage is the percentage of matched events which are forwarded. Must be between 0 and 100.
+	Percentage int32 `json:"percentage"`
+}
+
+// StageMerging defines the merging of the stages of a request.
+// The "RequestReceived" events are held for a window and dropped if the "ResponseComplete" or "Panic" event of
+// the request arrives within the window. Otherwise, they are forwarded once the window expired or on shutdown.
+// Held events are lost if the forwarder terminates unexpectedly.
+type StageMerging struct {
+	// Window is the duration for which "RequestReceived" events are held.
+	// Defaults to 1m, the default timeout of requests to the kube-apiserver.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+	// MaxHeldEvents is the maximum number of held events. If it is exceeded, the events held the longest are
+	// forwarded with the current request.
+	// Defaults to 10000.
+	// +optional
+	MaxHeldEvents int32 `json:"maxHeldEvents,omitempty"`
+}
+
+// Filter defines which audit events are kept by a Common Expression Language (CEL) expression.
+// The expression is evaluated with the variable "event", which holds the audit event with the fields of the
+// "audit.k8s.io/v1" Event, e.g. "verb", "user", "objectRef", "responseStatus" and "annotations".
+// Events are kept if the expression cannot be evaluated, so that no events are lost by mistake.
+type Filter struct {
+	// Expression is the CEL expression, which must evaluate to a bool. Events for which it evaluates to true are kept,
+	// e.g. `event.verb != "get" || event.objectRef.resource != "secrets"`.
+	Expression string `json:"expression"`
+}
+
+// ComputedAnnotation defines an annotation whose value is sourced dynamically or computed per audit event.
+// Exactly one of value, env, file, template and expression must be set.
+type ComputedAnnotation struct {
+	// Key is the key of the annotation.
+	Key string `json:"key"`
+	// Value is the static value of the annotation.
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Env is the name of the environment variable holding the value of the annotation, which is read at startup.
+	// +optional
+	Env string `json:"env,omitempty"`
+	// File contains the file holding the value of the annotation, which is re-read when it changes.
+	// +optional
+	File *AnnotationFile `json:"file,omitempty"`
+	// Template is the Go template computing the value of the annotation, e.g. `{{ .ObjectRef.Namespace }}`.
+	// The template is executed with the audit event, whose fields are named like the Go fields of the "audit.k8s.io/v1"
+	// Event. Unset fields and missing keys of maps are empty.
+	// +optional
+	Template string `json:"template,omitempty"`
+	// Expression is the Common Expression Language (CEL) expression computing the value of the annotation, which
+	// must evaluate to a string, e.g. `event.user.username.startsWith("system:") ? "system" : "user"`.
+	// The expression is evaluated with the variable "event" like the expression of a filter.
+	// +optional
+	Expression string `json:"expression,omitempty"`
+	// Overwrite specifies whether an annotation with the same key which is already set, e.g. by the kube-apiserver,
+	// is overwritten. Otherwise, the existing annotation is kept.
+	// Defaults to false.
+	// +optional
+	Overwrite bool `json:"overwrite,omitempty"`
+}
+
+// AnnotationFile defines a file holding the value of an annotation.
+type AnnotationFile struct {
+	// Path is the path to the file.
+	Path string `json:"path"`
+	// Key is the key of the value in the file, which contains one `key="value"` line per key like the labels or
+	// annotations of a downward API volume. If not set, the content of the file without leading and trailing whitespace
+	// is the value.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// Enrichment defines the enrichment of audit events with metadata of Kubernetes objects.
+// The objects are cached by informers, so that the enrichment does not send requests per audit event.
+// Annotations of the events with the same keys are not overwritten.
+type Enrichment struct {
+	// Kubeconfig is the path to the kubeconfig of the cluster whose objects are cached.
+	// The in-cluster configuration is used if not set.
+	// +optional
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Namespaces contains the metadata of the namespace of an event which is injected into it.
+	// The namespace of an event is the namespace of its object, or the object itself for namespaces.
+	Namespaces MetadataEnrichment `json:"namespaces"`
+}
+
+// MetadataEnrichment defines which labels and annotations of an object are injected into audit events.
+type MetadataEnrichment struct {
+	// Labels maps the keys of labels of the object to the keys of the annotations of the events into which their
+	// values are injected, e.g. "example.com/owner" to "namespace.example.com/owner".
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations maps the keys of annotations of the object to the keys of the annotations of the events into which
+	// their values are injected.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Pseudonymization defines the replacement of user identities and source IPs with keyed HMAC-SHA256 pseudonyms.
+// The usernames, UIDs and extra values of the user and the impersonated user are replaced with pseudonyms,
+// unless the username is allowed. The source IPs are replaced with pseudonymous IPv6 addresses, unless the
+// username of the user is allowed. Equal values result in equal pseudonyms, so that events can still be correlated.
+type Pseudonymization struct {
+	// KeyFile is the path to the file containing the secret key of the pseudonyms, which must have at least 32 bytes.
+	// The key is reloaded when the file changes. Changing the key changes all pseudonyms.
+	KeyFile string `json:"keyFile"`
+	// AllowedUsers is the list of patterns of usernames which are kept readable, e.g. "system:serviceaccount:*".
+	// A "*" matches any sequence of characters except "/".
+	// Defaults to ["system:*"], which matches service accounts, nodes and the system components.
+	// +optional
+	AllowedUsers []string `json:"allowedUsers,omitempty"`
+}
+
+// HashChain defines the tamper-evident hash chain over the forwarded audit events.
+// Each audit event is annotated with its sequence number, the hash of the preceding event and its own hash.
+// Checkpoints signed with an Ed25519 key are added periodically.
+// It cannot be combined with outputs which filter or pseudonymize the events.
+type HashChain struct {
+	// SigningKeyFile is the path to the PEM encoded PKCS #8 Ed25519 private key used to sign checkpoints.
+	// The key is reloaded when the file changes.
+	SigningKeyFile string `json:"signingKeyFile"`
+	// CheckpointInterval is the minimum interval between two signed checkpoints.
+	// Defaults to 1m.
+	// +optional
+	CheckpointInterval *metav1.Duration `json:"checkpointInterval,omitempty"`
+}
+
+// Tracing defines the export of OpenTelemetry traces to an OTLP collector.
+type Tracing struct {
+	// Endpoint is the host and port of the OTLP collector, e.g. "otel-collector:4317".
+	Endpoint string `json:"endpoint"`
+	// Protocol is the protocol used to export traces.
+	// Must be one of [grpc,http/protobuf].
+	// Defaults to "grpc".
+	// +optional
+	Protocol TracingProtocol `json:"protocol,omitempty"`
+	// Insecure disables TLS for the connection to the collector.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+	// CAFile is the path to the CA bundle used to verify the certificate of the collector.
+	// Defaults to the system root CAs.
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+	// SamplingPercentage is the percentage of traces which are sampled if the incoming request is not part of a trace.
+	// Requests which are part of a trace follow the sampling decision of their parent.
+	// Must be between 0 and 100.
+	// Defaults to 100.
+	// +optional
+	SamplingPercentage *int32 `json:"samplingPercentage,omitempty"`
+}
+
+// BestEffortQueue defines the queue for deliveries to the outputs with "BestEffort" delivery mode.
+// Requests are queued after they were forwarded to the required outputs and delivered by a fixed number of workers.
+type BestEffortQueue struct {
+	// Size is the maximum number of requests waiting for delivery.
+	// Defaults to 1000.
+	// +optional
+	Size int32 `json:"size,omitempty"`
+	// Workers is the number of requests which are delivered concurrently.
+	// Defaults to 10.
+	// +optional
+	Workers int32 `json:"workers,omitempty"`
+	// OverflowPolicy defines which request is dropped when the queue is full.
+	// Must be one of [DropOldest,DropNewest].
+	// Defaults to "DropOldest".
+	// +optional
+	OverflowPolicy OverflowPolicy `json:"overflowPolicy,omitempty"`
+}
+
 // OutputHTTP defines the configuration for an HTTP output.
 type OutputHTTP struct {
 	// URL is the endpoint URL to send audit logs to.
 	URL string `json:"url"`
+	// LoadBalancing contains the configuration for client-side load balancing across multiple endpoints.
+	// If not specified, all requests are sent to URL.
+	// +optional
+	LoadBalancing *LoadBalancing `json:"loadBalancing,omitempty"`
 	// TLS contains the TLS configuration for client.
 	// +optional
 	TLS *ClientTLS `json:"tls,omitempty"`
+	// Headers contains additional headers which are set on every request.
+	// Headers managed by the output, e.g. "Content-Type", cannot be set.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Auth contains the authentication configuration for requests to the output.
+	// +optional
+	Auth *OutputHTTPAuth `json:"auth,omitempty"`
+	// Proxy contains the configuration of the proxy through which requests are sent.
+	// If not specified, the proxy environment variables are honoured.
+	// +optional
+	Proxy *OutputHTTPProxy `json:"proxy,omitempty"`
 	// Compression defines the compression algorithm to use for the HTTP request body.
-	// Currently only "gzip" is supported. If empty, no compression is applied.
+	// Must be one of [gzip,zstd,snappy,deflate]. If empty, no compression is applied.
+	// Outputs using the same compression and level share a single compressed copy of each request.
 	// +optional
 	Compression string `json:"compression,omitempty"`
+	// CompressionLevel is the level of the compression algorithm. Supported ranges are 1-9 for "gzip"
+	// and "deflate" and 1-22 for "zstd"; "snappy" has no levels.
+	// If not specified, the default level of the algorithm is used.
+	// +optional
+	CompressionLevel *int32 `json:"compressionLevel,omitempty"`
+}
+
+// LoadBalancing defines the configuration for client-side load balancing across multiple endpoints.
+type LoadBalancing struct {
+	// Policy defines how an endpoint is selected for each request attempt.
+	// Must be one of [RoundRobin,LeastOutstandingRequests].
+	// Defaults to "RoundRobin".
+	// +optional
+	Policy LoadBalancingPolicy `json:"policy,omitempty"`
+	// Endpoints contains additional endpoint URLs. Requests are balanced across URL and Endpoints.
+	// Cannot be combined with DNS.
+	// +optional
+	Endpoints []string `json:"endpoints,omitempty"`
+	// DNS contains the configuration for discovering endpoints by resolving the host of URL.
+	// Connections are made to the discovered addresses while the TLS server name and the
+	// Host header remain those of URL.
+	// Cannot be combined with Endpoints.
+	// +optional
+	DNS *DNSDiscovery `json:"dns,omitempty"`
+	// FailureThreshold is the number of consecutive failed attempts after which an endpoint is ejected.
+	// Defaults to 3.
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+	// EjectionCooldown is the duration for which an ejected endpoint is not selected.
+	// If all endpoints are ejected, requests are still sent to them.
+	// Defaults to 30s.
+	// +optional
+	EjectionCooldown *metav1.Duration `json:"ejectionCooldown,omitempty"`
+}
+
+// DNSDiscovery defines the configuration for discovering endpoints via DNS.
+type DNSDiscovery struct {
+	// RecordType is the type of DNS records to resolve. Must be one of [A,SRV].
+	// "A" resolves the A and AAAA records of the host of URL and keeps the port of URL.
+	// "SRV" resolves the "_<service>._tcp.<host>" SRV records of the host of URL.
+	// Defaults to "A".
+	// +optional
+	RecordType DNSRecordType `json:"recordType,omitempty"`
+	// Service is the service name used for the SRV lookup.
+	// Required if RecordType is "SRV".
+	// +optional
+	Service string `json:"service,omitempty"`
+	// RefreshInterval is the interval in which the DNS records are resolved again.
+	// Defaults to 30s.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// OutputHTTPAuth defines the authentication configuration for an HTTP output.
+// Exactly one authentication method must be specified.
+// Credential files are re-read when they change.
+type OutputHTTPAuth struct {
+	// BearerTokenFile is the file containing a bearer token, e.g. a projected service account token.
+	// +optional
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+	// Basic contains the configuration for basic authentication.
+	// +optional
+	Basic *BasicAuth `json:"basic,omitempty"`
+	// OAuth2 contains the configuration for the OAuth2 client credentials flow.
+	// Tokens are cached and refreshed before they expire.
+	// +optional
+	OAuth2 *OAuth2ClientCredentials `json:"oauth2,omitempty"`
+}
+
+// BasicAuth defines the configuration for basic authentication.
+type BasicAuth struct {
+	// UsernameFile is the file containing the username.
+	UsernameFile string `json:"usernameFile"`
+	// PasswordFile is the file containing the password.
+	PasswordFile string `json:"passwordFile"`
+}
+
+// OutputHTTPProxy defines the proxy configuration of an HTTP output.
+// Exactly one of URL or FromEnvironment must be specified.
+// Requests to localhost and loopback addresses are never sent through the proxy.
+type OutputHTTPProxy struct {
+	// URL is the URL of the proxy, e.g. "http://proxy.example.com:3128".
+	// The schemes "http", "https" and "socks5" are supported.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// FromEnvironment configures the proxy from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	// +optional
+	FromEnvironment bool `json:"fromEnvironment,omitempty"`
+	// NoProxy contains hosts, domains, IP addresses and CIDRs for which the proxy is bypassed,
+	// following the format of the NO_PROXY environment variable. If FromEnvironment is set,
+	// the entries are added to the ones of the NO_PROXY environment variable.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+	// BasicAuth contains the credentials for authenticating to the proxy.
+	// +optional
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+}
+
+// OAuth2ClientCredentials defines the configuration for the OAuth2 client credentials flow.
+type OAuth2ClientCredentials struct {
+	// TokenURL is the URL of the token endpoint. Requests to it use the TLS and proxy configuration of the output,
+	// except for the TLS server name.
+	TokenURL string `json:"tokenURL"`
+	// ClientID is the client ID.
+	ClientID string `json:"clientID"`
+	// ClientSecretFile is the file containing the client secret.
+	ClientSecretFile string `json:"clientSecretFile"`
+	// Scopes contains the scopes to request.
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+	// EndpointParams contains additional parameters for requests to the token endpoint, e.g. "audience".
+	// +optional
+	EndpointParams map[string]string `json:"endpointParams,omitempty"`
 }
 
 // ClientTLS defines the TLS configuration for client.
@@ -129,4 +763,13 @@ type ClientTLS struct {
 	// KeyFile is the file containing the client private key for mutual TLS.
 	// +optional
 	KeyFile string `json:"keyFile,omitempty"`
+	// ServerName overrides the name used to verify the server certificate and sent via SNI.
+	// If not set, the host of the URL is used.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// Revocation configures the revocation checking of the server certificate via CRLs or OCSP.
+	// +optional
+	Revocation *Revocation `json:"revocation,omitempty"`
+	// TLSParameters contains the parameters of the TLS connections to the output.
+	TLSParameters `json:",inline"`
 }
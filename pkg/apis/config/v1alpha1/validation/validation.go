@@ -5,14 +5,27 @@
 package validation
 
 import (
+	"crypto/tls"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
 	"net/url"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 
+	"golang.org/x/net/http/httpguts"
+
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/util/sets"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	configv1alpha1 "github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1"
+	"github.com/gardener/auditlog-forwarder/pkg/apis/config/v1alpha1/helper"
+	"github.com/gardener/auditlog-forwarder/pkg/expression"
 )
 
 var (
@@ -28,7 +41,56 @@ var (
 	validDeliveryModes = sets.NewString(
 		string(configv1alpha1.DeliveryModeGuaranteed),
 		string(configv1alpha1.DeliveryModeBestEffort),
+		string(configv1alpha1.DeliveryModeQuorum),
+	)
+	validSchemas = sets.NewString(
+		string(configv1alpha1.SchemaKubernetesAudit),
+		string(configv1alpha1.SchemaECS),
+		string(configv1alpha1.SchemaOCSF),
+		string(configv1alpha1.SchemaCEF),
+	)
+	validFormats = sets.NewString(
+		string(configv1alpha1.FormatEventList),
+		string(configv1alpha1.FormatNDJSON),
+		string(configv1alpha1.FormatJSONArray),
+		string(configv1alpha1.FormatSingleEvent),
+	)
+	validLoadBalancingPolicies = sets.NewString(
+		string(configv1alpha1.LoadBalancingPolicyRoundRobin),
+		string(configv1alpha1.LoadBalancingPolicyLeastOutstandingRequests),
+	)
+	// reservedHeaders are set by the HTTP output itself and cannot be configured.
+	reservedHeaders = sets.NewString(
+		"Content-Type",
+		"Content-Encoding",
+		"Content-Length",
+		"Host",
+		"Transfer-Encoding",
+	)
+	validDNSRecordTypes = sets.NewString(
+		string(configv1alpha1.DNSRecordTypeA),
+		string(configv1alpha1.DNSRecordTypeSRV),
+	)
+	validOverflowPolicies = sets.NewString(
+		string(configv1alpha1.OverflowPolicyDropOldest),
+		string(configv1alpha1.OverflowPolicyDropNewest),
 	)
+	validRevocationFailurePolicies = sets.NewString(
+		string(configv1alpha1.RevocationFailurePolicyFailClosed),
+		string(configv1alpha1.RevocationFailurePolicyFailOpen),
+	)
+	validTracingProtocols = sets.NewString(
+		string(configv1alpha1.TracingProtocolGRPC),
+		string(configv1alpha1.TracingProtocolHTTP),
+	)
+	validProxySchemes = sets.NewString("http", "https", "socks5")
+	validCompressions = sets.NewString("gzip", "zstd", "snappy", "deflate")
+	// compressionLevels are the inclusive ranges of the supported levels per compression algorithm.
+	compressionLevels = map[string][2]int32{
+		"gzip":    {1, 9},
+		"deflate": {1, 9},
+		"zstd":    {1, 22},
+	}
 )
 
 // ValidateAuditlogForwarder validates the given [*configv1alpha1.AuditlogForwarder].
@@ -38,7 +100,18 @@ func ValidateAuditlogForwarder(cfg *configv1alpha1.AuditlogForwarder) field.Erro
 	allErrs = append(allErrs, validateLogConfiguration(&cfg.Log, field.NewPath("log"))...)
 	allErrs = append(allErrs, validateServer(&cfg.Server, field.NewPath("server"))...)
 	allErrs = append(allErrs, validateOutputs(cfg.Outputs, field.NewPath("outputs"))...)
+	allErrs = append(allErrs, validateQuorum(cfg.Quorum, cfg.Outputs, field.NewPath("quorum"))...)
+	allErrs = append(allErrs, validateBestEffortQueue(cfg.BestEffortQueue, cfg.Outputs, field.NewPath("bestEffortQueue"))...)
+	allErrs = append(allErrs, validateTracing(cfg.Tracing, field.NewPath("tracing"))...)
+	allErrs = append(allErrs, validateDeduplication(cfg.Deduplication, field.NewPath("deduplication"))...)
+	allErrs = append(allErrs, validateSampling(cfg.Sampling, field.NewPath("sampling"))...)
+	allErrs = append(allErrs, validateStageMerging(cfg.StageMerging, field.NewPath("stageMerging"))...)
+	allErrs = append(allErrs, validateFilter(cfg.Filter, field.NewPath("filter"))...)
 	allErrs = append(allErrs, validateInjectAnnotations(cfg.InjectAnnotations, field.NewPath("injectAnnotations"))...)
+	allErrs = append(allErrs, validateComputedAnnotations(cfg.ComputedAnnotations, cfg.InjectAnnotations, field.NewPath("computedAnnotations"))...)
+	allErrs = append(allErrs, validateEnrichment(cfg.Enrichment, field.NewPath("enrichment"))...)
+	allErrs = append(allErrs, validatePseudonymization(cfg.Pseudonymization, cfg.Outputs, field.NewPath("pseudonymization"))...)
+	allErrs = append(allErrs, validateHashChain(cfg.HashChain, cfg.Outputs, field.NewPath("hashChain"))...)
 
 	return allErrs
 }
@@ -80,7 +153,50 @@ func validateServer(serverConfig *configv1alpha1.Server, fldPath *field.Path) fi
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("metricsPort"), serverConfig.MetricsPort, "metrics port must be between 0 and 65535"))
 	}
 
+	if serverConfig.MaxInFlightRequests != nil && *serverConfig.MaxInFlightRequests < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxInFlightRequests"), *serverConfig.MaxInFlightRequests, "must not be negative"))
+	}
+	if serverConfig.RetryAfter != nil && serverConfig.RetryAfter.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("retryAfter"), serverConfig.RetryAfter.Duration.String(), "must be positive"))
+	}
+
 	allErrs = append(allErrs, validateTLS(&serverConfig.TLS, fldPath.Child("tls"))...)
+	allErrs = append(allErrs, validateRequestLimits(&serverConfig.RequestLimits, fldPath.Child("requestLimits"))...)
+	allErrs = append(allErrs, validateHealth(&serverConfig.Health, fldPath.Child("health"))...)
+
+	return allErrs
+}
+
+// validateHealth validates the configuration of the readiness endpoint.
+func validateHealth(health *configv1alpha1.Health, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if health.CheckInterval != nil && health.CheckInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("checkInterval"), health.CheckInterval.Duration.String(), "must be positive"))
+	}
+
+	return allErrs
+}
+
+// validateRequestLimits validates the limits of incoming audit requests.
+func validateRequestLimits(limits *configv1alpha1.RequestLimits, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if limits.MaxBodySize != nil && limits.MaxBodySize.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxBodySize"), limits.MaxBodySize.String(), "must be greater than 0"))
+	}
+
+	if limits.MaxDecompressedBodySize != nil {
+		if limits.MaxDecompressedBodySize.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxDecompressedBodySize"), limits.MaxDecompressedBodySize.String(), "must be greater than 0"))
+		} else if limits.MaxBodySize != nil && limits.MaxDecompressedBodySize.Cmp(*limits.MaxBodySize) < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxDecompressedBodySize"), limits.MaxDecompressedBodySize.String(), "must not be less than maxBodySize"))
+		}
+	}
+
+	if limits.MaxDecompressionRatio < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxDecompressionRatio"), limits.MaxDecompressionRatio, "must be greater than 0"))
+	}
 
 	return allErrs
 }
@@ -102,6 +218,111 @@ func validateTLS(tlsConfig *configv1alpha1.TLS, fldPath *field.Path) field.Error
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("clientCAFile"), tlsConfig.ClientCAFile, "client CA file path cannot be empty when specified"))
 	}
 
+	if tlsConfig.ClientRevocation != nil {
+		revocationPath := fldPath.Child("clientRevocation")
+		if strings.TrimSpace(tlsConfig.ClientCAFile) == "" {
+			allErrs = append(allErrs, field.Forbidden(revocationPath, "revocation checking of client certificates requires clientCAFile"))
+		}
+		if tlsConfig.ClientRevocation.OCSP {
+			allErrs = append(allErrs, field.Forbidden(revocationPath.Child("ocsp"), "OCSP is not supported for client certificates"))
+		}
+		allErrs = append(allErrs, validateRevocation(tlsConfig.ClientRevocation, revocationPath)...)
+	}
+
+	allErrs = append(allErrs, validateTLSParameters(&tlsConfig.TLSParameters, fldPath)...)
+
+	return allErrs
+}
+
+// validateRevocation validates the revocation checking of certificates.
+func validateRevocation(revocation *configv1alpha1.Revocation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(revocation.CRLFiles) == 0 && !revocation.OCSP {
+		allErrs = append(allErrs, field.Required(fldPath, "at least one of crlFiles or ocsp must be configured"))
+	}
+
+	for i, file := range revocation.CRLFiles {
+		if strings.TrimSpace(file) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("crlFiles").Index(i), file, "CRL file path cannot be empty"))
+		}
+	}
+
+	if !validRevocationFailurePolicies.Has(string(revocation.FailurePolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("failurePolicy"), revocation.FailurePolicy, validRevocationFailurePolicies.List()))
+	}
+
+	return allErrs
+}
+
+// validateTLSParameters validates the parameters of TLS connections and rejects insecure combinations.
+func validateTLSParameters(params *configv1alpha1.TLSParameters, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	var minVersion, maxVersion uint16
+	if params.MinVersion != "" {
+		version, err := helper.TLSVersion(params.MinVersion)
+		if err != nil {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("minVersion"), params.MinVersion, helper.SupportedTLSVersions()))
+		}
+		minVersion = version
+	}
+	if params.MaxVersion != "" {
+		version, err := helper.TLSVersion(params.MaxVersion)
+		if err != nil {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("maxVersion"), params.MaxVersion, helper.SupportedTLSVersions()))
+		}
+		maxVersion = version
+	}
+	if minVersion != 0 && maxVersion != 0 && maxVersion < minVersion {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxVersion"), params.MaxVersion, "must not be lower than minVersion"))
+	}
+
+	cipherSuitesPath := fldPath.Child("cipherSuites")
+	if len(params.CipherSuites) > 0 && minVersion == tls.VersionTLS13 {
+		allErrs = append(allErrs, field.Forbidden(cipherSuitesPath, "cipher suites are not configurable for TLS 1.3"))
+	}
+	for i, name := range params.CipherSuites {
+		suite, insecure, err := helper.CipherSuite(name)
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(cipherSuitesPath.Index(i), name, "unknown cipher suite"))
+		case insecure:
+			allErrs = append(allErrs, field.Invalid(cipherSuitesPath.Index(i), name, "insecure cipher suite"))
+		case !slices.Contains(suite.SupportedVersions, tls.VersionTLS12):
+			allErrs = append(allErrs, field.Invalid(cipherSuitesPath.Index(i), name, "cipher suites of TLS 1.3 are not configurable"))
+		}
+	}
+	curvesPath := fldPath.Child("curvePreferences")
+	onlyHybridCurves := len(params.CurvePreferences) > 0
+	for i, name := range params.CurvePreferences {
+		curve, err := helper.Curve(name)
+		if err != nil {
+			allErrs = append(allErrs, field.NotSupported(curvesPath.Index(i), name, helper.SupportedCurves()))
+			continue
+		}
+		if curve != tls.X25519MLKEM768 {
+			onlyHybridCurves = false
+		}
+	}
+	// Hybrid post-quantum key exchanges are only available in TLS 1.3, so no key exchange would be possible with TLS 1.2.
+	if onlyHybridCurves && maxVersion == tls.VersionTLS12 {
+		allErrs = append(allErrs, field.Invalid(curvesPath, params.CurvePreferences, "must contain a curve supported by TLS 1.2 if maxVersion is VersionTLS12"))
+	}
+
+	seenProtocols := sets.New[string]()
+	for i, protocol := range params.ALPNProtocols {
+		protocolPath := fldPath.Child("alpnProtocols").Index(i)
+		if strings.TrimSpace(protocol) == "" {
+			allErrs = append(allErrs, field.Invalid(protocolPath, protocol, "protocol must not be empty"))
+			continue
+		}
+		if seenProtocols.Has(protocol) {
+			allErrs = append(allErrs, field.Duplicate(protocolPath, protocol))
+		}
+		seenProtocols.Insert(protocol)
+	}
+
 	return allErrs
 }
 
@@ -114,14 +335,18 @@ func validateOutputs(outputs []configv1alpha1.Output, fldPath *field.Path) field
 		return allErrs
 	}
 
-	// Validate each output and count Guaranteed outputs
+	// Validate each output and count Guaranteed and Quorum outputs
 	guaranteedCount := 0
+	quorumCount := 0
 	for i, output := range outputs {
 		outputPath := fldPath.Index(i)
 		allErrs = append(allErrs, validateOutput(&output, outputPath)...)
 
-		if output.DeliveryMode == configv1alpha1.DeliveryModeGuaranteed {
+		switch output.DeliveryMode {
+		case configv1alpha1.DeliveryModeGuaranteed:
 			guaranteedCount++
+		case configv1alpha1.DeliveryModeQuorum:
+			quorumCount++
 		}
 	}
 
@@ -133,11 +358,17 @@ func validateOutputs(outputs []configv1alpha1.Output, fldPath *field.Path) field
 				outputs[0].DeliveryMode,
 				"single output must have 'Guaranteed' delivery mode"))
 		}
+	} else if quorumCount > 0 {
+		// Multiple outputs with a quorum group: the quorum replaces the Guaranteed output
+		if guaranteedCount > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, guaranteedCount,
+				"'Guaranteed' and 'Quorum' delivery modes cannot be combined, the single group of 'Quorum' outputs replaces the 'Guaranteed' output"))
+		}
 	} else {
 		// Multiple outputs: exactly one must be Guaranteed
 		if guaranteedCount == 0 {
 			allErrs = append(allErrs, field.Invalid(fldPath, guaranteedCount,
-				"exactly one output must have 'Guaranteed' delivery mode when multiple outputs are configured"))
+				"exactly one output must have 'Guaranteed' delivery mode when multiple outputs are configured, or at least one must have 'Quorum' delivery mode"))
 		} else if guaranteedCount > 1 {
 			allErrs = append(allErrs, field.Invalid(fldPath, guaranteedCount,
 				"only one output can have 'Guaranteed' delivery mode"))
@@ -147,6 +378,98 @@ func validateOutputs(outputs []configv1alpha1.Output, fldPath *field.Path) field
 	return allErrs
 }
 
+// validateQuorum validates the quorum configuration against the configured outputs.
+func validateQuorum(quorum *configv1alpha1.Quorum, outputs []configv1alpha1.Output, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if quorum == nil {
+		return allErrs
+	}
+
+	quorumCount := 0
+	for _, output := range outputs {
+		if output.DeliveryMode == configv1alpha1.DeliveryModeQuorum {
+			quorumCount++
+		}
+	}
+
+	if quorumCount == 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "quorum can only be configured when at least one output has 'Quorum' delivery mode"))
+		return allErrs
+	}
+
+	if quorum.MinSuccessful < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minSuccessful"), quorum.MinSuccessful, "minimum number of successful outputs must be at least 1"))
+	} else if int(quorum.MinSuccessful) > quorumCount {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minSuccessful"), quorum.MinSuccessful,
+			fmt.Sprintf("minimum number of successful outputs must not exceed the number of 'Quorum' outputs (%d)", quorumCount)))
+	}
+
+	return allErrs
+}
+
+// validateBestEffortQueue validates the queue of deliveries to BestEffort outputs.
+func validateBestEffortQueue(queue *configv1alpha1.BestEffortQueue, outputs []configv1alpha1.Output, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if queue == nil {
+		return allErrs
+	}
+
+	isBestEffort := func(o configv1alpha1.Output) bool { return o.DeliveryMode == configv1alpha1.DeliveryModeBestEffort }
+	if !slices.ContainsFunc(outputs, isBestEffort) {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "best effort queue can only be configured when at least one output has 'BestEffort' delivery mode"))
+		return allErrs
+	}
+
+	if queue.Size < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("size"), queue.Size, "size must be at least 1"))
+	}
+	if queue.Workers < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("workers"), queue.Workers, "number of workers must be at least 1"))
+	}
+	if !validOverflowPolicies.Has(string(queue.OverflowPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("overflowPolicy"), queue.OverflowPolicy, validOverflowPolicies.List()))
+	}
+
+	return allErrs
+}
+
+// validateTracing validates the export of traces.
+func validateTracing(tracing *configv1alpha1.Tracing, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if tracing == nil {
+		return allErrs
+	}
+
+	if tracing.Endpoint == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("endpoint"), "endpoint is required"))
+	} else if host, port, err := net.SplitHostPort(tracing.Endpoint); err != nil || host == "" || !isValidPort(port) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("endpoint"), tracing.Endpoint, "endpoint must be of the form host:port"))
+	}
+
+	if tracing.Protocol != "" && !validTracingProtocols.Has(string(tracing.Protocol)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), tracing.Protocol, validTracingProtocols.List()))
+	}
+
+	if tracing.Insecure && strings.TrimSpace(tracing.CAFile) != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("caFile"), "CA file cannot be configured when TLS is disabled"))
+	}
+
+	if p := tracing.SamplingPercentage; p != nil && (*p < 0 || *p > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("samplingPercentage"), *p, "sampling percentage must be between 0 and 100"))
+	}
+
+	return allErrs
+}
+
+// isValidPort returns whether the given string is a port number between 1 and 65535.
+func isValidPort(port string) bool {
+	p, err := strconv.ParseUint(port, 10, 16)
+	return err == nil && p > 0
+}
+
 // validateOutput validates a single output configuration.
 func validateOutput(output *configv1alpha1.Output, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -159,6 +482,13 @@ func validateOutput(output *configv1alpha1.Output, fldPath *field.Path) field.Er
 		}
 	}
 
+	if output.Schema != "" && !validSchemas.Has(string(output.Schema)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("schema"), output.Schema, validSchemas.List()))
+	}
+	allErrs = append(allErrs, validateFormat(output.Format, output.Schema, fldPath.Child("format"))...)
+	allErrs = append(allErrs, validateFilter(output.Filter, fldPath.Child("filter"))...)
+	allErrs = append(allErrs, validateEncryption(output.Encryption, fldPath.Child("encryption"))...)
+
 	// Count the number of output types configured
 	outputTypes := 0
 	if output.HTTP != nil {
@@ -182,6 +512,48 @@ func validateOutput(output *configv1alpha1.Output, fldPath *field.Path) field.Er
 	return allErrs
 }
 
+// validateFormat validates the format of an output against its schema.
+func validateFormat(format configv1alpha1.Format, schema configv1alpha1.Schema, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if format == "" {
+		return allErrs
+	}
+	if !validFormats.Has(string(format)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath, format, validFormats.List()))
+		return allErrs
+	}
+
+	switch {
+	case format == configv1alpha1.FormatEventList && schema != "" && schema != configv1alpha1.SchemaKubernetesAudit:
+		allErrs = append(allErrs, field.Invalid(fldPath, format, fmt.Sprintf("format is only supported with schema %q", configv1alpha1.SchemaKubernetesAudit)))
+	case format == configv1alpha1.FormatJSONArray && schema == configv1alpha1.SchemaCEF:
+		allErrs = append(allErrs, field.Invalid(fldPath, format, fmt.Sprintf("format is not supported with schema %q", configv1alpha1.SchemaCEF)))
+	}
+
+	return allErrs
+}
+
+// validateEncryption validates the encryption of an output.
+func validateEncryption(encryption *configv1alpha1.Encryption, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if encryption == nil {
+		return allErrs
+	}
+
+	if len(encryption.RecipientPublicKeyFiles) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("recipientPublicKeyFiles"), "at least one recipient public key file is required"))
+	}
+	for i, file := range encryption.RecipientPublicKeyFiles {
+		if strings.TrimSpace(file) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("recipientPublicKeyFiles").Index(i), file, "recipient public key file path cannot be empty"))
+		}
+	}
+
+	return allErrs
+}
+
 // validateOutputHTTP validates the HTTP output configuration.
 func validateOutputHTTP(httpOutput *configv1alpha1.OutputHTTP, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -190,38 +562,274 @@ func validateOutputHTTP(httpOutput *configv1alpha1.OutputHTTP, fldPath *field.Pa
 	if urlValue == "" {
 		allErrs = append(allErrs, field.Required(fldPath.Child("url"), "URL is required for HTTP output"))
 	} else {
-		// Validate URL format
-		if outputURL, err := url.Parse(urlValue); err != nil {
-			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), urlValue, "invalid URL format"))
-		} else {
-			if outputURL.Scheme != "https" {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), urlValue, "URL scheme must be 'https'"))
-			}
+		allErrs = append(allErrs, validateHTTPSURL(urlValue, fldPath.Child("url"))...)
+	}
 
-			if outputURL.RawQuery != "" {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), urlValue, "URL must not contain query parameters"))
-			}
+	if httpOutput.LoadBalancing != nil {
+		allErrs = append(allErrs, validateLoadBalancing(httpOutput.LoadBalancing, urlValue, fldPath.Child("loadBalancing"))...)
+	}
 
-			if outputURL.Fragment != "" {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), urlValue, "URL must not contain fragments"))
-			}
+	if httpOutput.TLS != nil {
+		allErrs = append(allErrs, validateClientTLS(httpOutput.TLS, fldPath.Child("tls"))...)
+	}
 
-			if outputURL.User != nil {
-				allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), urlValue, "URL must not contain user information"))
-			}
+	allErrs = append(allErrs, validateHeaders(httpOutput.Headers, httpOutput.Auth != nil, fldPath.Child("headers"))...)
+
+	if httpOutput.Auth != nil {
+		allErrs = append(allErrs, validateOutputHTTPAuth(httpOutput.Auth, fldPath.Child("auth"))...)
+	}
+
+	if httpOutput.Proxy != nil {
+		allErrs = append(allErrs, validateOutputHTTPProxy(httpOutput.Proxy, fldPath.Child("proxy"))...)
+	}
+
+	allErrs = append(allErrs, validateCompression(httpOutput.Compression, httpOutput.CompressionLevel, fldPath)...)
+
+	return allErrs
+}
+
+// validateCompression validates the compression algorithm and level of an HTTP output.
+func validateCompression(compression string, level *int32, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	compression = strings.TrimSpace(compression)
+	if compression != "" && !validCompressions.Has(compression) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("compression"), compression, validCompressions.List()))
+		return allErrs
+	}
+
+	if level == nil {
+		return allErrs
+	}
+
+	levelPath := fldPath.Child("compressionLevel")
+	levelRange, ok := compressionLevels[compression]
+	if !ok {
+		allErrs = append(allErrs, field.Forbidden(levelPath, fmt.Sprintf("compression level is not supported for compression %q", compression)))
+		return allErrs
+	}
+	if *level < levelRange[0] || *level > levelRange[1] {
+		allErrs = append(allErrs, field.Invalid(levelPath, *level, fmt.Sprintf("compression level for %q must be between %d and %d", compression, levelRange[0], levelRange[1])))
+	}
+
+	return allErrs
+}
+
+// validateHTTPSURL validates that the given value is an HTTPS URL suitable for an output endpoint.
+func validateHTTPSURL(urlValue string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	outputURL, err := url.Parse(urlValue)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, urlValue, "invalid URL format"))
+		return allErrs
+	}
+
+	if outputURL.Scheme != "https" {
+		allErrs = append(allErrs, field.Invalid(fldPath, urlValue, "URL scheme must be 'https'"))
+	}
+
+	if outputURL.RawQuery != "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, urlValue, "URL must not contain query parameters"))
+	}
+
+	if outputURL.Fragment != "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, urlValue, "URL must not contain fragments"))
+	}
+
+	if outputURL.User != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, urlValue, "URL must not contain user information"))
+	}
+
+	return allErrs
+}
+
+// validateLoadBalancing validates the load balancing configuration of an HTTP output.
+func validateLoadBalancing(lb *configv1alpha1.LoadBalancing, urlValue string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !validLoadBalancingPolicies.Has(string(lb.Policy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("policy"), lb.Policy, validLoadBalancingPolicies.List()))
+	}
+
+	for i, endpoint := range lb.Endpoints {
+		endpointPath := fldPath.Child("endpoints").Index(i)
+		if endpoint = strings.TrimSpace(endpoint); endpoint == "" {
+			allErrs = append(allErrs, field.Required(endpointPath, "endpoint URL cannot be empty"))
+			continue
 		}
+		allErrs = append(allErrs, validateHTTPSURL(endpoint, endpointPath)...)
 	}
 
-	if httpOutput.TLS != nil {
-		allErrs = append(allErrs, validateClientTLS(httpOutput.TLS, fldPath.Child("tls"))...)
+	if lb.DNS != nil {
+		if len(lb.Endpoints) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("dns"), "DNS discovery cannot be combined with endpoints"))
+		}
+		allErrs = append(allErrs, validateDNSDiscovery(lb.DNS, urlValue, fldPath.Child("dns"))...)
+	}
+
+	if lb.FailureThreshold < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("failureThreshold"), lb.FailureThreshold, "failure threshold must be at least 1"))
+	}
+
+	if lb.EjectionCooldown == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("ejectionCooldown"), "ejection cooldown is required"))
+	} else if lb.EjectionCooldown.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("ejectionCooldown"), lb.EjectionCooldown.Duration.String(), "ejection cooldown must be positive"))
+	}
+
+	return allErrs
+}
+
+// validateDNSDiscovery validates the DNS discovery configuration of an HTTP output.
+func validateDNSDiscovery(dns *configv1alpha1.DNSDiscovery, urlValue string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !validDNSRecordTypes.Has(string(dns.RecordType)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("recordType"), dns.RecordType, validDNSRecordTypes.List()))
+	}
+
+	if dns.RecordType == configv1alpha1.DNSRecordTypeSRV && strings.TrimSpace(dns.Service) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("service"), "service is required for SRV records"))
+	}
+
+	if outputURL, err := url.Parse(urlValue); err == nil && net.ParseIP(outputURL.Hostname()) != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, urlValue, "DNS discovery requires the host of the URL to be a DNS name"))
+	}
+
+	if dns.RefreshInterval == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("refreshInterval"), "refresh interval is required"))
+	} else if dns.RefreshInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("refreshInterval"), dns.RefreshInterval.Duration.String(), "refresh interval must be positive"))
+	}
+
+	return allErrs
+}
+
+// validateHeaders validates the additional headers of an HTTP output.
+func validateHeaders(headers map[string]string, authConfigured bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for name, value := range headers {
+		namePath := fldPath.Key(name)
+		if !httpguts.ValidHeaderFieldName(name) {
+			allErrs = append(allErrs, field.Invalid(namePath, name, "invalid header name"))
+			continue
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			allErrs = append(allErrs, field.Invalid(namePath, value, "invalid header value"))
+		}
+
+		canonicalName := http.CanonicalHeaderKey(name)
+		if reservedHeaders.Has(canonicalName) {
+			allErrs = append(allErrs, field.Forbidden(namePath, "header is managed by the output"))
+		}
+		if authConfigured && canonicalName == "Authorization" {
+			allErrs = append(allErrs, field.Forbidden(namePath, "header cannot be set when auth is configured"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateOutputHTTPAuth validates the authentication configuration of an HTTP output.
+func validateOutputHTTPAuth(auth *configv1alpha1.OutputHTTPAuth, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	methods := 0
+	if auth.BearerTokenFile != "" {
+		methods++
+		if strings.TrimSpace(auth.BearerTokenFile) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("bearerTokenFile"), auth.BearerTokenFile, "bearer token file path cannot be empty when specified"))
+		}
+	}
+
+	if auth.Basic != nil {
+		methods++
+		allErrs = append(allErrs, validateBasicAuth(auth.Basic, fldPath.Child("basic"))...)
+	}
+
+	if auth.OAuth2 != nil {
+		methods++
+		allErrs = append(allErrs, validateOAuth2ClientCredentials(auth.OAuth2, fldPath.Child("oauth2"))...)
+	}
+
+	if methods != 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath, methods, "exactly one of 'bearerTokenFile', 'basic' or 'oauth2' must be specified"))
+	}
+
+	return allErrs
+}
+
+// validateBasicAuth validates the basic authentication configuration.
+func validateBasicAuth(basic *configv1alpha1.BasicAuth, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if strings.TrimSpace(basic.UsernameFile) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("usernameFile"), "username file is required"))
+	}
+	if strings.TrimSpace(basic.PasswordFile) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("passwordFile"), "password file is required"))
+	}
+
+	return allErrs
+}
+
+// validateOAuth2ClientCredentials validates the OAuth2 client credentials configuration.
+func validateOAuth2ClientCredentials(oauth2 *configv1alpha1.OAuth2ClientCredentials, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if tokenURL := strings.TrimSpace(oauth2.TokenURL); tokenURL == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("tokenURL"), "token URL is required"))
+	} else {
+		allErrs = append(allErrs, validateHTTPSURL(tokenURL, fldPath.Child("tokenURL"))...)
+	}
+
+	if strings.TrimSpace(oauth2.ClientID) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clientID"), "client ID is required"))
+	}
+
+	if strings.TrimSpace(oauth2.ClientSecretFile) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("clientSecretFile"), "client secret file is required"))
 	}
 
-	if compression := strings.TrimSpace(httpOutput.Compression); compression != "" {
-		if compression != "gzip" {
-			allErrs = append(allErrs, field.NotSupported(fldPath.Child("compression"), compression, []string{"gzip"}))
+	return allErrs
+}
+
+// validateOutputHTTPProxy validates the proxy configuration of an HTTP output.
+func validateOutputHTTPProxy(proxy *configv1alpha1.OutputHTTPProxy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	proxyURL := strings.TrimSpace(proxy.URL)
+	switch {
+	case proxyURL != "" && proxy.FromEnvironment:
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("url"), "url cannot be specified when 'fromEnvironment' is enabled"))
+	case proxyURL == "" && !proxy.FromEnvironment:
+		allErrs = append(allErrs, field.Required(fldPath.Child("url"), "url is required unless 'fromEnvironment' is enabled"))
+	case proxyURL != "":
+		u, err := url.Parse(proxyURL)
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), proxy.URL, "invalid URL format"))
+		case !validProxySchemes.Has(u.Scheme):
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("url"), u.Scheme, validProxySchemes.List()))
+		case u.Host == "":
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), proxy.URL, "URL must contain a host"))
+		case u.User != nil:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("url"), proxy.URL, "URL must not contain user information, use 'basicAuth' instead"))
+		}
+	}
+
+	for i, noProxy := range proxy.NoProxy {
+		if strings.TrimSpace(noProxy) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("noProxy").Index(i), "entry cannot be empty"))
 		}
 	}
 
+	if proxy.BasicAuth != nil {
+		allErrs = append(allErrs, validateBasicAuth(proxy.BasicAuth, fldPath.Child("basicAuth"))...)
+	}
+
 	return allErrs
 }
 
@@ -241,6 +849,108 @@ func validateClientTLS(tlsConfig *configv1alpha1.ClientTLS, fldPath *field.Path)
 		allErrs = append(allErrs, field.Required(fldPath.Child("certFile"), "certFile is required when keyFile is specified"))
 	}
 
+	if tlsConfig.ServerName != "" {
+		for _, msg := range utilvalidation.IsDNS1123Subdomain(strings.ToLower(tlsConfig.ServerName)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("serverName"), tlsConfig.ServerName, msg))
+		}
+	}
+
+	if tlsConfig.Revocation != nil {
+		allErrs = append(allErrs, validateRevocation(tlsConfig.Revocation, fldPath.Child("revocation"))...)
+	}
+
+	allErrs = append(allErrs, validateTLSParameters(&tlsConfig.TLSParameters, fldPath)...)
+
+	return allErrs
+}
+
+// validateDeduplication validates the deduplication of audit events.
+func validateDeduplication(deduplication *configv1alpha1.Deduplication, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if deduplication == nil {
+		return allErrs
+	}
+
+	if deduplication.CacheSize <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cacheSize"), deduplication.CacheSize, "must be positive"))
+	}
+
+	if deduplication.TTL != nil && deduplication.TTL.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("ttl"), deduplication.TTL.Duration.String(), "must be positive"))
+	}
+
+	return allErrs
+}
+
+// validateSampling validates the sampling rules.
+func validateSampling(sampling *configv1alpha1.Sampling, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if sampling == nil {
+		return allErrs
+	}
+
+	rulesPath := fldPath.Child("rules")
+	if len(sampling.Rules) == 0 {
+		allErrs = append(allErrs, field.Required(rulesPath, "at least one rule is required"))
+	}
+
+	names := sets.New[string]()
+	for i, rule := range sampling.Rules {
+		rulePath := rulesPath.Index(i)
+
+		if strings.TrimSpace(rule.Name) == "" {
+			allErrs = append(allErrs, field.Required(rulePath.Child("name"), "name is required"))
+		} else if names.Has(rule.Name) {
+			allErrs = append(allErrs, field.Duplicate(rulePath.Child("name"), rule.Name))
+		}
+		names.Insert(rule.Name)
+
+		for j, verb := range rule.Verbs {
+			if strings.TrimSpace(verb) == "" {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("verbs").Index(j), verb, "verb cannot be empty"))
+			}
+		}
+		for j, pattern := range rule.Users {
+			if strings.TrimSpace(pattern) == "" {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("users").Index(j), pattern, "pattern cannot be empty"))
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("users").Index(j), pattern, err.Error()))
+			}
+		}
+		for j, resource := range rule.Resources {
+			if strings.TrimSpace(resource) == "" {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("resources").Index(j), resource, "resource cannot be empty"))
+			}
+		}
+
+		if rule.Percentage < 0 || rule.Percentage > 100 {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("percentage"), rule.Percentage, "percentage must be between 0 and 100"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateStageMerging validates the merging of the stages of a request.
+func validateStageMerging(stageMerging *configv1alpha1.StageMerging, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if stageMerging == nil {
+		return allErrs
+	}
+
+	if stageMerging.Window != nil && stageMerging.Window.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("window"), stageMerging.Window.Duration.String(), "must be positive"))
+	}
+
+	if stageMerging.MaxHeldEvents <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxHeldEvents"), stageMerging.MaxHeldEvents, "must be positive"))
+	}
+
 	return allErrs
 }
 
@@ -259,3 +969,204 @@ func validateInjectAnnotations(annotations map[string]string, fldPath *field.Pat
 
 	return allErrs
 }
+
+// validateFilter validates that the expression of the filter compiles to a bool.
+func validateFilter(filter *configv1alpha1.Filter, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if filter == nil {
+		return allErrs
+	}
+
+	expressionPath := fldPath.Child("expression")
+	if strings.TrimSpace(filter.Expression) == "" {
+		allErrs = append(allErrs, field.Required(expressionPath, "expression is required"))
+	} else if _, err := expression.CompileBool(filter.Expression); err != nil {
+		allErrs = append(allErrs, field.Invalid(expressionPath, filter.Expression, err.Error()))
+	}
+
+	return allErrs
+}
+
+// validateComputedAnnotations validates the keys and the value sources of the computed annotations.
+func validateComputedAnnotations(annotations []configv1alpha1.ComputedAnnotation, injectAnnotations map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	keys := sets.New[string]()
+	for i, annotation := range annotations {
+		annotationPath := fldPath.Index(i)
+
+		keyPath := annotationPath.Child("key")
+		if annotation.Key == "" {
+			allErrs = append(allErrs, field.Required(keyPath, "key is required"))
+		} else {
+			for _, msg := range utilvalidation.IsQualifiedName(strings.ToLower(annotation.Key)) {
+				allErrs = append(allErrs, field.Invalid(keyPath, annotation.Key, msg))
+			}
+			if _, ok := injectAnnotations[annotation.Key]; ok || keys.Has(annotation.Key) {
+				allErrs = append(allErrs, field.Duplicate(keyPath, annotation.Key))
+			}
+			keys.Insert(annotation.Key)
+		}
+
+		allErrs = append(allErrs, validateComputedAnnotationSource(&annotation, annotationPath)...)
+	}
+
+	return allErrs
+}
+
+// validateComputedAnnotationSource validates that exactly one value source is set, that templates parse and
+// that expressions compile to strings.
+func validateComputedAnnotationSource(annotation *configv1alpha1.ComputedAnnotation, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	sources := 0
+	if annotation.Value != "" {
+		sources++
+	}
+	if annotation.Env != "" {
+		sources++
+		if strings.TrimSpace(annotation.Env) == "" || strings.ContainsAny(annotation.Env, "= ") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("env"), annotation.Env, "must be the name of an environment variable"))
+		}
+	}
+	if annotation.File != nil {
+		sources++
+		if strings.TrimSpace(annotation.File.Path) == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("file", "path"), "path is required"))
+		}
+	}
+	if annotation.Template != "" {
+		sources++
+		if _, err := expression.ParseTemplate(annotation.Template); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("template"), annotation.Template, err.Error()))
+		}
+	}
+	if annotation.Expression != "" {
+		sources++
+		if _, err := expression.CompileString(annotation.Expression); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("expression"), annotation.Expression, err.Error()))
+		}
+	}
+
+	switch {
+	case sources == 0:
+		allErrs = append(allErrs, field.Required(fldPath, "one of value, env, file, template or expression is required"))
+	case sources > 1:
+		allErrs = append(allErrs, field.Invalid(fldPath, sources, "exactly one of value, env, file, template or expression must be specified"))
+	}
+
+	return allErrs
+}
+
+// validateEnrichment validates the enrichment with metadata of Kubernetes objects.
+func validateEnrichment(enrichment *configv1alpha1.Enrichment, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if enrichment == nil {
+		return allErrs
+	}
+
+	if enrichment.Kubeconfig != "" && strings.TrimSpace(enrichment.Kubeconfig) == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("kubeconfig"), enrichment.Kubeconfig, "kubeconfig cannot be blank"))
+	}
+
+	namespacesPath := fldPath.Child("namespaces")
+	if len(enrichment.Namespaces.Labels) == 0 && len(enrichment.Namespaces.Annotations) == 0 {
+		allErrs = append(allErrs, field.Required(namespacesPath, "at least one label or annotation is required"))
+	}
+	allErrs = append(allErrs, validateMetadataEnrichment(&enrichment.Namespaces, namespacesPath)...)
+
+	return allErrs
+}
+
+// validateMetadataEnrichment validates the keys of the labels and annotations of objects and that the keys
+// of the injected annotations are valid and unique.
+func validateMetadataEnrichment(metadata *configv1alpha1.MetadataEnrichment, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	targets := sets.New[string]()
+	validateMapping := func(mapping map[string]string, mappingPath *field.Path) {
+		for _, key := range slices.Sorted(maps.Keys(mapping)) {
+			keyPath := mappingPath.Key(key)
+			for _, msg := range utilvalidation.IsQualifiedName(key) {
+				allErrs = append(allErrs, field.Invalid(keyPath, key, msg))
+			}
+
+			target := mapping[key]
+			if target == "" {
+				allErrs = append(allErrs, field.Required(keyPath, "annotation key is required"))
+				continue
+			}
+			for _, msg := range utilvalidation.IsQualifiedName(strings.ToLower(target)) {
+				allErrs = append(allErrs, field.Invalid(keyPath, target, msg))
+			}
+			if targets.Has(target) {
+				allErrs = append(allErrs, field.Duplicate(keyPath, target))
+			}
+			targets.Insert(target)
+		}
+	}
+	validateMapping(metadata.Labels, fldPath.Child("labels"))
+	validateMapping(metadata.Annotations, fldPath.Child("annotations"))
+
+	return allErrs
+}
+
+// validatePseudonymization validates the pseudonymization and that it is configured if an output requires it.
+func validatePseudonymization(pseudonymization *configv1alpha1.Pseudonymization, outputs []configv1alpha1.Output, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if pseudonymization == nil {
+		if slices.ContainsFunc(outputs, func(o configv1alpha1.Output) bool { return o.Pseudonymize }) {
+			allErrs = append(allErrs, field.Required(fldPath, "pseudonymization must be configured for outputs with pseudonymize enabled"))
+		}
+		return allErrs
+	}
+
+	if strings.TrimSpace(pseudonymization.KeyFile) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("keyFile"), "key file is required"))
+	}
+
+	for i, pattern := range pseudonymization.AllowedUsers {
+		if strings.TrimSpace(pattern) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("allowedUsers").Index(i), pattern, "pattern cannot be empty"))
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("allowedUsers").Index(i), pattern, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateHashChain validates the configuration of the hash chain and that no output modifies the chained events.
+func validateHashChain(hashChain *configv1alpha1.HashChain, outputs []configv1alpha1.Output, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if hashChain == nil {
+		return allErrs
+	}
+
+	if strings.TrimSpace(hashChain.SigningKeyFile) == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("signingKeyFile"), "signing key file is required"))
+	}
+
+	if hashChain.CheckpointInterval != nil && hashChain.CheckpointInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("checkpointInterval"), hashChain.CheckpointInterval.Duration.String(), "must be positive"))
+	}
+
+	// The processors of the outputs run after the hash chain, hence the stored events would not match their hashes
+	// and the filtered events would be reported as missing.
+	for i, output := range outputs {
+		if output.Filter != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("outputs").Index(i).Child("filter"), "output filters cannot be combined with the hash chain"))
+		}
+		if output.Pseudonymize {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("outputs").Index(i).Child("pseudonymize"), "pseudonymization cannot be combined with the hash chain"))
+		}
+	}
+
+	return allErrs
+}
@@ -25,4 +25,45 @@ func SetObjectDefaults_AuditlogForwarder(in *AuditlogForwarder) {
 	SetDefaults_AuditlogForwarder(in)
 	SetDefaults_Log(&in.Log)
 	SetDefaults_Server(&in.Server)
+	if in.Server.TLS.ClientRevocation != nil {
+		SetDefaults_Revocation(in.Server.TLS.ClientRevocation)
+	}
+	SetDefaults_Health(&in.Server.Health)
+	for i := range in.Outputs {
+		a := &in.Outputs[i]
+		if a.HTTP != nil {
+			if a.HTTP.LoadBalancing != nil {
+				SetDefaults_LoadBalancing(a.HTTP.LoadBalancing)
+				if a.HTTP.LoadBalancing.DNS != nil {
+					SetDefaults_DNSDiscovery(a.HTTP.LoadBalancing.DNS)
+				}
+			}
+			if a.HTTP.TLS != nil {
+				if a.HTTP.TLS.Revocation != nil {
+					SetDefaults_Revocation(a.HTTP.TLS.Revocation)
+				}
+			}
+		}
+	}
+	if in.Quorum != nil {
+		SetDefaults_Quorum(in.Quorum)
+	}
+	if in.BestEffortQueue != nil {
+		SetDefaults_BestEffortQueue(in.BestEffortQueue)
+	}
+	if in.Tracing != nil {
+		SetDefaults_Tracing(in.Tracing)
+	}
+	if in.Deduplication != nil {
+		SetDefaults_Deduplication(in.Deduplication)
+	}
+	if in.StageMerging != nil {
+		SetDefaults_StageMerging(in.StageMerging)
+	}
+	if in.Pseudonymization != nil {
+		SetDefaults_Pseudonymization(in.Pseudonymization)
+	}
+	if in.HashChain != nil {
+		SetDefaults_HashChain(in.HashChain)
+	}
 }
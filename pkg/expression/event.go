@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package expression
+
+import (
+	"time"
+
+	authnv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+// Event is the audit event as exposed to CEL expressions by the variable "event" and to templates as dot.
+// The fields are named like the fields of the "audit.k8s.io/v1" Event. Unset fields have their zero value,
+// so that e.g. `has(event.objectRef)` is false and `{{ .ObjectRef.Namespace }}` is empty for non-resource requests.
+type Event struct {
+	Level                    string            `cel:"level"`
+	AuditID                  string            `cel:"auditID"`
+	Stage                    string            `cel:"stage"`
+	RequestURI               string            `cel:"requestURI"`
+	Verb                     string            `cel:"verb"`
+	User                     User              `cel:"user"`
+	ImpersonatedUser         User              `cel:"impersonatedUser"`
+	SourceIPs                []string          `cel:"sourceIPs"`
+	UserAgent                string            `cel:"userAgent"`
+	ObjectRef                ObjectReference   `cel:"objectRef"`
+	ResponseStatus           ResponseStatus    `cel:"responseStatus"`
+	RequestReceivedTimestamp time.Time         `cel:"requestReceivedTimestamp"`
+	StageTimestamp           time.Time         `cel:"stageTimestamp"`
+	Annotations              map[string]string `cel:"annotations"`
+}
+
+// User is the user of an audit event.
+type User struct {
+	Username string              `cel:"username"`
+	UID      string              `cel:"uid"`
+	Groups   []string            `cel:"groups"`
+	Extra    map[string][]string `cel:"extra"`
+}
+
+// ObjectReference is the object reference of an audit event.
+type ObjectReference struct {
+	Resource        string `cel:"resource"`
+	Namespace       string `cel:"namespace"`
+	Name            string `cel:"name"`
+	UID             string `cel:"uid"`
+	APIGroup        string `cel:"apiGroup"`
+	APIVersion      string `cel:"apiVersion"`
+	ResourceVersion string `cel:"resourceVersion"`
+	Subresource     string `cel:"subresource"`
+}
+
+// ResponseStatus is the response status of an audit event.
+type ResponseStatus struct {
+	Code    int64  `cel:"code"`
+	Status  string `cel:"status"`
+	Reason  string `cel:"reason"`
+	Message string `cel:"message"`
+}
+
+// NewEvent returns the audit event as exposed to CEL expressions and templates.
+func NewEvent(event *audit.Event) *Event {
+	e := &Event{
+		Level:                    string(event.Level),
+		AuditID:                  string(event.AuditID),
+		Stage:                    string(event.Stage),
+		RequestURI:               event.RequestURI,
+		Verb:                     event.Verb,
+		User:                     newUser(&event.User),
+		SourceIPs:                event.SourceIPs,
+		UserAgent:                event.UserAgent,
+		RequestReceivedTimestamp: event.RequestReceivedTimestamp.Time,
+		StageTimestamp:           event.StageTimestamp.Time,
+		Annotations:              event.Annotations,
+	}
+	if event.ImpersonatedUser != nil {
+		e.ImpersonatedUser = newUser(event.ImpersonatedUser)
+	}
+	if ref := event.ObjectRef; ref != nil {
+		e.ObjectRef = ObjectReference{
+			Resource:        ref.Resource,
+			Namespace:       ref.Namespace,
+			Name:            ref.Name,
+			UID:             string(ref.UID),
+			APIGroup:        ref.APIGroup,
+			APIVersion:      ref.APIVersion,
+			ResourceVersion: ref.ResourceVersion,
+			Subresource:     ref.Subresource,
+		}
+	}
+	if status := event.ResponseStatus; status != nil {
+		e.ResponseStatus = ResponseStatus{
+			Code:    int64(status.Code),
+			Status:  status.Status,
+			Reason:  string(status.Reason),
+			Message: status.Message,
+		}
+	}
+	return e
+}
+
+// newUser returns the user as exposed to CEL expressions and templates.
+func newUser(user *authnv1.UserInfo) User {
+	u := User{
+		Username: user.Username,
+		UID:      user.UID,
+		Groups:   user.Groups,
+	}
+	if len(user.Extra) > 0 {
+		u.Extra = make(map[string][]string, len(user.Extra))
+		for key, values := range user.Extra {
+			u.Extra[key] = values
+		}
+	}
+	return u
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package expression implements CEL expressions and Go templates evaluated against audit events.
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+const (
+	// VariableEvent is the name of the variable holding the audit event.
+	VariableEvent = "event"
+
+	// costLimit limits the cost of an evaluation, so that an expression cannot stall the processing of requests.
+	costLimit = 1000000
+)
+
+// environment returns the CEL environment of the expressions, which is created once.
+var environment = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		ext.NativeTypes(reflect.TypeFor[Event](), ext.ParseStructTags(true)),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+		cel.Variable(VariableEvent, cel.ObjectType("expression.Event")),
+	)
+})
+
+// Program is a compiled CEL expression.
+type Program struct {
+	expression string
+	program    cel.Program
+}
+
+// Compile compiles and type-checks the CEL expression, which must evaluate to the given type.
+func Compile(expression string, outputType *cel.Type) (*Program, error) {
+	env, err := environment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	checked, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !checked.OutputType().IsExactType(outputType) {
+		return nil, fmt.Errorf("expression must evaluate to %s, but evaluates to %s", outputType, checked.OutputType())
+	}
+
+	program, err := env.Program(checked, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, err
+	}
+	return &Program{expression: expression, program: program}, nil
+}
+
+// CompileBool compiles the CEL expression, which must evaluate to a bool, e.g. to filter audit events.
+func CompileBool(expression string) (*Program, error) {
+	return Compile(expression, cel.BoolType)
+}
+
+// CompileString compiles the CEL expression, which must evaluate to a string, e.g. to compute annotation values.
+func CompileString(expression string) (*Program, error) {
+	return Compile(expression, cel.StringType)
+}
+
+// String returns the expression of the program.
+func (p *Program) String() string {
+	return p.expression
+}
+
+// EvalBool evaluates the program, which must have been compiled with CompileBool, against the audit event.
+func (p *Program) EvalBool(event *audit.Event) (bool, error) {
+	value, err := p.eval(event)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, errors.New("expression did not evaluate to a bool")
+	}
+	return result, nil
+}
+
+// EvalString evaluates the program, which must have been compiled with CompileString, against the audit event.
+func (p *Program) EvalString(event *audit.Event) (string, error) {
+	value, err := p.eval(event)
+	if err != nil {
+		return "", err
+	}
+	result, ok := value.(string)
+	if !ok {
+		return "", errors.New("expression did not evaluate to a string")
+	}
+	return result, nil
+}
+
+// eval evaluates the program against the audit event.
+func (p *Program) eval(event *audit.Event) (any, error) {
+	value, _, err := p.program.Eval(map[string]any{VariableEvent: NewEvent(event)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression %q: %w", p.expression, err)
+	}
+	return value.Value(), nil
+}
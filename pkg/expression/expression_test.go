@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package expression_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authnv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/pkg/expression"
+)
+
+var _ = Describe("Expression", func() {
+	var event *auditinternal.Event
+
+	BeforeEach(func() {
+		event = &auditinternal.Event{
+			Level:      auditinternal.LevelMetadata,
+			AuditID:    "1",
+			Stage:      auditinternal.StageResponseComplete,
+			RequestURI: "/api/v1/namespaces/default/secrets/token",
+			Verb:       "get",
+			User: authnv1.UserInfo{
+				Username: "system:serviceaccount:kube-system:controller",
+				Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+				Extra:    map[string]authnv1.ExtraValue{"scopes": {"read"}},
+			},
+			SourceIPs: []string{"10.0.0.1"},
+			ObjectRef: &auditinternal.ObjectReference{
+				Resource:  "secrets",
+				Namespace: "default",
+				Name:      "token",
+			},
+			ResponseStatus: &metav1.Status{Code: 403, Reason: metav1.StatusReasonForbidden},
+			StageTimestamp: metav1.NewMicroTime(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)),
+			Annotations:    map[string]string{"authorization.k8s.io/decision": "forbid"},
+		}
+	})
+
+	DescribeTable("#CompileBool",
+		func(expr string, expected bool) {
+			program, err := expression.CompileBool(expr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(program.EvalBool(event)).To(Equal(expected))
+		},
+		Entry("verb", `event.verb == "get"`, true),
+		Entry("stage", `event.stage == "ResponseComplete"`, true),
+		Entry("object reference", `event.objectRef.resource == "secrets" && event.objectRef.namespace == "default"`, true),
+		Entry("user", `event.user.username.startsWith("system:serviceaccount:")`, true),
+		Entry("groups", `"system:authenticated" in event.user.groups`, true),
+		Entry("extra", `event.user.extra["scopes"] == ["read"]`, true),
+		Entry("response status", `event.responseStatus.code >= 400`, true),
+		Entry("annotations", `event.annotations["authorization.k8s.io/decision"] == "forbid"`, true),
+		Entry("missing annotation", `"missing" in event.annotations`, false),
+		Entry("source IPs", `event.sourceIPs.exists(ip, ip.startsWith("10."))`, true),
+		Entry("timestamps", `event.stageTimestamp > timestamp("2026-01-01T00:00:00Z")`, true),
+		Entry("unset impersonated user", `has(event.impersonatedUser)`, false),
+	)
+
+	It("should expose non-resource requests with an empty object reference", func() {
+		event.ObjectRef = nil
+		event.ResponseStatus = nil
+
+		program, err := expression.CompileBool(`event.objectRef.resource == "" && event.responseStatus.code == 0`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(program.EvalBool(event)).To(BeTrue())
+	})
+
+	It("should compute string values", func() {
+		program, err := expression.CompileString(`event.verb + " " + event.objectRef.resource`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(program.String()).To(Equal(`event.verb + " " + event.objectRef.resource`))
+		Expect(program.EvalString(event)).To(Equal("get secrets"))
+	})
+
+	It("should fail to compile expressions with syntax errors", func() {
+		_, err := expression.CompileBool(`event.verb ==`)
+		Expect(err).To(MatchError(ContainSubstring("Syntax error")))
+	})
+
+	It("should fail to compile expressions with unknown fields", func() {
+		_, err := expression.CompileBool(`event.unknown == "get"`)
+		Expect(err).To(MatchError(ContainSubstring("undefined field 'unknown'")))
+	})
+
+	It("should fail to compile expressions of the wrong type", func() {
+		_, err := expression.CompileBool(`event.verb`)
+		Expect(err).To(MatchError("expression must evaluate to bool, but evaluates to string"))
+
+		_, err = expression.CompileString(`event.responseStatus.code`)
+		Expect(err).To(MatchError("expression must evaluate to string, but evaluates to int"))
+	})
+
+	It("should fail to evaluate expressions accessing missing keys", func() {
+		program, err := expression.CompileBool(`event.annotations["missing"] == ""`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = program.EvalBool(event)
+		Expect(err).To(MatchError(ContainSubstring("no such key: missing")))
+	})
+})
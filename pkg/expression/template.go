@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package expression
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+// Template is a parsed Go template which is executed with the audit event as data.
+type Template struct {
+	text     string
+	template *template.Template
+}
+
+// ParseTemplate parses the Go template and checks that the fields it accesses on the audit event exist,
+// e.g. `{{ .ObjectRef.Namespace }}`. The fields are the ones of Event, missing keys of maps evaluate to their zero value.
+func ParseTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("template").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFields(tmpl.Root, reflect.TypeFor[Event]()); err != nil {
+		return nil, err
+	}
+	return &Template{text: text, template: tmpl}, nil
+}
+
+// String returns the text of the template.
+func (t *Template) String() string {
+	return t.text
+}
+
+// Execute executes the template with the audit event.
+func (t *Template) Execute(event *audit.Event) (string, error) {
+	var b strings.Builder
+	if err := t.template.Execute(&b, NewEvent(event)); err != nil {
+		return "", fmt.Errorf("failed to execute template %q: %w", t.text, err)
+	}
+	return b.String(), nil
+}
+
+// checkFields checks that the fields accessed on dot and on $ in the nodes exist in the given types.
+// The type of dot is nil in the bodies of range and with actions, where it is not known.
+func checkFields(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkFields(child, dot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkFields(n.Pipe, dot)
+	case *parse.IfNode:
+		return checkBranch(&n.BranchNode, dot, dot)
+	case *parse.RangeNode:
+		return checkBranch(&n.BranchNode, dot, nil)
+	case *parse.WithNode:
+		return checkBranch(&n.BranchNode, dot, nil)
+	case *parse.TemplateNode:
+		return checkFields(n.Pipe, dot)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkFields(arg, dot); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.FieldNode:
+		if dot != nil {
+			return checkFieldChain(dot, n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 0 && n.Ident[0] == "$" {
+			return checkFieldChain(reflect.TypeFor[Event](), n.Ident[1:])
+		}
+	}
+	return nil
+}
+
+// checkBranch checks the pipeline and the else list of the branch with dot and the list with the type of its dot.
+func checkBranch(n *parse.BranchNode, dot, listDot reflect.Type) error {
+	if err := checkFields(n.Pipe, dot); err != nil {
+		return err
+	}
+	if err := checkFields(n.List, listDot); err != nil {
+		return err
+	}
+	return checkFields(n.ElseList, dot)
+}
+
+// checkFieldChain checks that the chain of fields exists in the given type. Keys of maps are not checked.
+func checkFieldChain(t reflect.Type, fields []string) error {
+	for _, name := range fields {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			field, ok := t.FieldByName(name)
+			if !ok || !field.IsExported() {
+				return fmt.Errorf("can't evaluate field %s in type %s", name, t)
+			}
+			t = field.Type
+		default:
+			return fmt.Errorf("can't evaluate field %s in type %s", name, t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package expression_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authnv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/gardener/auditlog-forwarder/pkg/expression"
+)
+
+var _ = Describe("Template", func() {
+	event := &auditinternal.Event{
+		Verb: "get",
+		User: authnv1.UserInfo{
+			Username: "alice",
+			Groups:   []string{"admins", "system:authenticated"},
+		},
+		ObjectRef:      &auditinternal.ObjectReference{Resource: "secrets", Namespace: "default", Name: "token"},
+		ResponseStatus: &metav1.Status{Code: 200},
+		Annotations:    map[string]string{"team": "a"},
+	}
+
+	DescribeTable("#Execute",
+		func(text, expected string) {
+			tmpl, err := expression.ParseTemplate(text)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tmpl.String()).To(Equal(text))
+			Expect(tmpl.Execute(event)).To(Equal(expected))
+		},
+		Entry("fields", `{{ .Verb }} {{ .ObjectRef.Namespace }}/{{ .ObjectRef.Name }}`, "get default/token"),
+		Entry("numbers", `{{ .ResponseStatus.Code }}`, "200"),
+		Entry("map keys", `{{ .Annotations.team }}{{ .Annotations.missing }}`, "a"),
+		Entry("unset fields", `{{ .ImpersonatedUser.Username }}`, ""),
+		Entry("conditions", `{{ if eq .Verb "get" }}read{{ else }}write{{ end }}`, "read"),
+		Entry("ranges", `{{ range $i, $g := .User.Groups }}{{ if $i }},{{ end }}{{ $g }}{{ end }}`, "admins,system:authenticated"),
+		Entry("with", `{{ with .ObjectRef }}{{ .Resource }} of {{ $.User.Username }}{{ end }}`, "secrets of alice"),
+	)
+
+	DescribeTable("#ParseTemplate errors",
+		func(text, expected string) {
+			_, err := expression.ParseTemplate(text)
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("syntax", `{{ .Verb `, "unclosed action"),
+		Entry("unknown field", `{{ .Unknown }}`, "can't evaluate field Unknown in type expression.Event"),
+		Entry("unknown nested field", `{{ .User.Name }}`, "can't evaluate field Name in type expression.User"),
+		Entry("field of non-struct", `{{ .Verb.Length }}`, "can't evaluate field Length in type string"),
+		Entry("unknown field in condition", `{{ if .Unknown }}x{{ end }}`, "can't evaluate field Unknown"),
+		Entry("unknown field of root", `{{ range .User.Groups }}{{ $.Unknown }}{{ end }}`, "can't evaluate field Unknown"),
+		Entry("unknown function", `{{ upper .Verb }}`, `function "upper" not defined`),
+	)
+
+	It("should fail to execute templates with invalid operations", func() {
+		tmpl, err := expression.ParseTemplate(`{{ index .User.Groups 5 }}`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tmpl.Execute(event)
+		Expect(err).To(MatchError(ContainSubstring("failed to execute template")))
+	})
+})